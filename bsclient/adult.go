@@ -0,0 +1,59 @@
+package bsclient
+
+import "strings"
+
+// adultRatings are the content ratings of the shows restricted to adults
+var adultRatings = map[string]bool{
+	"TV-MA": true,
+	"NC-17": true,
+	"X":     true,
+	"18":    true,
+	"18+":   true,
+	"-18":   true,
+}
+
+// IsAdult reports whether the content rating of the show restricts it to
+// adults (TV-MA, NC-17, X or 18). The API has no other adult flag: shows
+// without rating are not adult.
+func (s *Show) IsAdult() bool {
+	return adultRatings[strings.ToUpper(strings.TrimSpace(s.Rating))]
+}
+
+// WithExcludeAdult removes the adult shows from the listings, see
+// SetExcludeAdult.
+func WithExcludeAdult(exclude bool) Option {
+	return func(o *clientOptions) {
+		o.excludeAdult = exclude
+	}
+}
+
+// SetExcludeAdult removes the adult shows (see Show.IsAdult) from the
+// results of the show listings, searches and random picks, as well as from
+// the catalog scans and dumps, whatever the options of the member account.
+// Similar shows are only removed when requested with their details, and
+// the lists of the member are left as is. The results may then hold fewer
+// shows than requested: a page whose shows are all removed is returned
+// empty rather than with ErrNoShowsFound, which still marks the end of the
+// listings. Disabled by default.
+func (bs *BetaSeries) SetExcludeAdult(exclude bool) {
+	bs.excludeAdult = exclude
+}
+
+// excluded reports whether the show is removed from the listings.
+func (bs *BetaSeries) excluded(show *Show) bool {
+	return bs.excludeAdult && show.IsAdult()
+}
+
+// filterShows removes the excluded shows from the result of a listing.
+func (bs *BetaSeries) filterShows(shows []Show, err error) ([]Show, error) {
+	if err != nil || !bs.excludeAdult {
+		return shows, err
+	}
+	kept := make([]Show, 0, len(shows))
+	for i := range shows {
+		if !bs.excluded(&shows[i]) {
+			kept = append(kept, shows[i])
+		}
+	}
+	return kept, nil
+}
@@ -0,0 +1,129 @@
+package bsclient
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// adultCatalog serves a catalog of 250 shows of the network "HBO" whose
+// shows with an id multiple of 3, and all the shows of the second page, are
+// rated TV-MA.
+func adultCatalog(f *fakeServer) {
+	f.handle("GET", "/shows/list", func(w http.ResponseWriter, r *http.Request) {
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		shows := []string{}
+		for id := start + 1; id <= start+limit && id <= 250; id++ {
+			rating := "TV-14"
+			if id%3 == 0 || id > 100 && id <= 200 {
+				rating = "TV-MA"
+			}
+			shows = append(shows, fmt.Sprintf(`{"id":%d,"network":"HBO","rating":%q,"followers":"%d"}`,
+				id, rating, 1000-id))
+		}
+		writeFakeJSON(w, 200, `{"shows":[`+strings.Join(shows, ",")+`],"errors":[]}`)
+	})
+}
+
+func showIDs(shows []Show) []int {
+	ids := []int{}
+	for _, show := range shows {
+		ids = append(ids, show.ID)
+	}
+	return ids
+}
+
+func (s *MySuite) TestShowIsAdult(c *C) {
+	for rating, adult := range map[string]bool{
+		"TV-MA": true, "tv-ma": true, " NC-17": true, "18": true, "-18": true,
+		"TV-14": false, "PG-13": false, "": false,
+	} {
+		show := Show{Rating: rating}
+		c.Assert(show.IsAdult(), Equals, adult, Commentf("rating %q", rating))
+	}
+}
+
+func (s *MySuite) TestExcludeAdult(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	body := `{"shows":[{"id":1,"rating":"TV-MA"},{"id":2,"rating":"TV-PG"},{"id":3}],"errors":[]}`
+	f.handleJSON("GET", "/shows/search", 200, body)
+	f.handleJSON("GET", "/shows/random", 200, body)
+	f.handleJSON("GET", "/shows/similars", 200, `{"similars":[`+
+		`{"id":10,"show_id":1,"show":{"id":1,"rating":"TV-MA"}},`+
+		`{"id":11,"show_id":2,"show":{"id":2,"rating":"TV-PG"}}],"errors":[]}`)
+	adultCatalog(f)
+	bs := f.client(c)
+
+	// disabled by default
+	shows, err := bs.ShowsSearch(ctx, "show", "", false)
+	c.Assert(err, IsNil)
+	c.Assert(showIDs(shows), DeepEquals, []int{1, 2, 3})
+	shows, err = bs.ShowsList(ctx, "", "", "", 0, 10)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 10)
+
+	bs.SetExcludeAdult(true)
+	shows, err = bs.ShowsSearch(ctx, "show", "", false)
+	c.Assert(err, IsNil)
+	c.Assert(showIDs(shows), DeepEquals, []int{2, 3})
+	shows, err = bs.ShowsRandom(ctx, 3, false)
+	c.Assert(err, IsNil)
+	c.Assert(showIDs(shows), DeepEquals, []int{2, 3})
+	similars, err := bs.ShowsSimilars(ctx, 5, 0, true)
+	c.Assert(err, IsNil)
+	c.Assert(similars, HasLen, 1)
+	c.Assert(similars[0].ShowID, Equals, 2)
+	shows, err = bs.ShowsList(ctx, "", "", "", 0, 10)
+	c.Assert(err, IsNil)
+	c.Assert(showIDs(shows), DeepEquals, []int{1, 2, 4, 5, 7, 8, 10})
+	// a page of adult shows is empty, not the end of the listing
+	shows, err = bs.ShowsList(ctx, "", "", "", 100, 100)
+	c.Assert(err, IsNil)
+	c.Assert(shows, NotNil)
+	c.Assert(shows, HasLen, 0)
+}
+
+func (s *MySuite) TestExcludeAdultCatalog(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	adultCatalog(f)
+	bs, err := NewClientFromToken("key", "token", WithBaseURL(f.URL), WithExcludeAdult(true))
+	c.Assert(err, IsNil)
+
+	// the scans go past the page of adult shows
+	expected := []int{}
+	for id := 1; id <= 250; id++ {
+		if id%3 != 0 && (id <= 100 || id > 200) {
+			expected = append(expected, id)
+		}
+	}
+	shows, err := bs.ShowsByNetwork(ctx, "HBO", 0)
+	c.Assert(err, IsNil)
+	c.Assert(showIDs(shows), DeepEquals, expected)
+
+	var out bytes.Buffer
+	c.Assert(bs.DumpJSONL(ctx, &out, DumpCatalog), IsNil)
+	lines := dumpLines(c, out.String())[1:]
+	c.Assert(lines, HasLen, len(expected))
+	for i, line := range lines {
+		c.Assert(line["id"], Equals, float64(expected[i]))
+	}
+
+	shows, err = bs.ShowsRandomWeighted(ctx, 50, 0)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 50)
+	for _, show := range shows {
+		c.Assert(show.IsAdult(), Equals, false)
+	}
+
+	bs.SetExcludeAdult(false)
+	shows, err = bs.ShowsByNetwork(ctx, "HBO", 0)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 250)
+}
@@ -0,0 +1,112 @@
+package bsclient
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// airTime is the air date of an episode in a given time zone.
+type airTime struct {
+	// day is midnight of the air day
+	day time.Time
+	// at is the air time, if the API gives the time of day
+	at    time.Time
+	timed bool
+}
+
+// parseAirTime converts the API date of an episode into the zone 'loc'.
+// Dates without time of day are kept as the same calendar day in 'loc'.
+func parseAirTime(date Date, loc *time.Location) (airTime, bool) {
+	if date.IsZero() {
+		return airTime{}, false
+	}
+	if date.HasTime() {
+		at := date.Time().In(loc)
+		return airTime{day: midnight(at), at: at, timed: true}, true
+	}
+	year, month, day := date.Time().Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	return airTime{day: midnight, at: midnight}, true
+}
+
+// midnight returns the start of the day of 't', in its time zone.
+func midnight(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// AiringTonight returns the episodes of the shows of the authenticated member
+// airing today in the time zone 'loc' (the local time zone if nil) which are
+// not seen yet. See AiringWithin for the order of the episodes.
+func (bs *BetaSeries) AiringTonight(ctx context.Context, loc *time.Location) ([]EpisodeWithShow, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	today := midnight(timeNow().In(loc))
+	return bs.airing(ctx, today, today.AddDate(0, 0, 1), loc)
+}
+
+// AiringWithin returns the episodes of the shows of the authenticated member
+// airing from the start of today up to 'd' from now, in the time zone 'loc'
+// (the local time zone if nil), which are not seen yet.
+// Episodes are sorted by air time. Within a day, the episodes whose time of
+// day is unknown come after the others: they are considered airing at the
+// start of their day to select them.
+func (bs *BetaSeries) AiringWithin(ctx context.Context, d time.Duration, loc *time.Location) ([]EpisodeWithShow, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	now := timeNow().In(loc)
+	return bs.airing(ctx, midnight(now), now.Add(d), loc)
+}
+
+// airing returns the unseen episodes of the member's planning airing in
+// [from, to[, sorted by air time.
+func (bs *BetaSeries) airing(ctx context.Context, from, to time.Time, loc *time.Location) ([]EpisodeWithShow, error) {
+	type airing struct {
+		EpisodeWithShow
+		airTime
+	}
+	var found []airing
+	// the planning is requested by month of the API dates: a day of margin
+	// covers the episodes moved to another month by the time zone
+	listed := map[int]bool{}
+	first := from.AddDate(0, 0, -1)
+	last := to.AddDate(0, 0, 1)
+	month := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, loc)
+	for ; !month.After(last); month = month.AddDate(0, 1, 0) {
+		episodes, err := bs.PlanningMember(ctx, 0, true, month.Format("2006-01"))
+		if err != nil && !errors.Is(err, ErrNoEpisodesFound) {
+			return nil, err
+		}
+		for _, episode := range episodes {
+			if episode.User.Seen || listed[episode.ID] {
+				continue
+			}
+			at, ok := parseAirTime(episode.Date, loc)
+			if !ok || at.at.Before(from) || !at.at.Before(to) {
+				continue
+			}
+			listed[episode.ID] = true
+			found = append(found, airing{EpisodeWithShow{episode, episodeShow(&episode)}, at})
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		a, b := found[i].airTime, found[j].airTime
+		if !a.day.Equal(b.day) {
+			return a.day.Before(b.day)
+		}
+		if a.timed != b.timed {
+			return a.timed
+		}
+		return a.at.Before(b.at)
+	})
+	out := make([]EpisodeWithShow, len(found))
+	for i := range found {
+		out[i] = found[i].EpisodeWithShow
+	}
+	return out, nil
+}
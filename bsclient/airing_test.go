@@ -0,0 +1,105 @@
+package bsclient
+
+import (
+	"net/http"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// airingServer answers the member planning by month.
+func airingServer(months map[string]string) *fakeServer {
+	f := newFakeServer()
+	f.handle("GET", "/planning/member", func(w http.ResponseWriter, r *http.Request) {
+		if episodes, ok := months[r.URL.Query().Get("month")]; ok {
+			writeFakeJSON(w, 200, `{"episodes":[`+episodes+`],"errors":[]}`)
+			return
+		}
+		writeFakeJSON(w, 200, `{"episodes":[],"errors":[]}`)
+	})
+	return f
+}
+
+func airingIDs(episodes []EpisodeWithShow) []int {
+	ids := make([]int, len(episodes))
+	for i, e := range episodes {
+		ids[i] = e.Episode.ID
+	}
+	return ids
+}
+
+func (s *MySuite) TestAiringTonight(c *C) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		c.Skip("no time zone database")
+	}
+	// 21:00 in Paris, where the times of the API are
+	now := time.Date(2017, 6, 1, 19, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+	f := airingServer(map[string]string{"2017-06": `
+		{"id":1,"date":"2017-06-01","show":{"id":10,"title":"Dated only"}},
+		{"id":2,"date":"2017-06-01 22:00:00","show":{"id":20,"title":"Late"}},
+		{"id":3,"date":"2017-06-01 20:00:00","show":{"id":30,"title":"Early"}},
+		{"id":4,"date":"2017-06-02 00:30:00","show":{"id":40,"title":"After midnight in Paris"}},
+		{"id":5,"date":"2017-06-01 00:30:00","show":{"id":50,"title":"Just after midnight in Paris"}},
+		{"id":6,"date":"2017-06-01 21:00:00","user":{"seen":true}},
+		{"id":7,"date":"2017-06-02"}`})
+	defer f.Close()
+	bs := f.client(c)
+
+	episodes, err := bs.AiringTonight(ctx, paris)
+	c.Assert(err, IsNil)
+	c.Assert(airingIDs(episodes), DeepEquals, []int{5, 3, 2, 1})
+	c.Assert(episodes[0].Show.Title, Equals, "Just after midnight in Paris")
+	calls := f.callsTo("/planning/member")
+	c.Assert(calls, HasLen, 2)
+	c.Assert(calls[0].Query.Get("month"), Equals, "2017-05")
+	c.Assert(calls[0].Query.Get("unseen"), Equals, "true")
+	c.Assert(calls[1].Query.Get("month"), Equals, "2017-06")
+
+	// in UTC, 00:30 in Paris is 22:30 the day before
+	episodes, err = bs.AiringTonight(ctx, time.UTC)
+	c.Assert(err, IsNil)
+	c.Assert(airingIDs(episodes), DeepEquals, []int{3, 2, 4, 1})
+}
+
+func (s *MySuite) TestAiringWithin(c *C) {
+	now := time.Date(2017, 6, 28, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+	f := airingServer(map[string]string{
+		"2017-06": `
+			{"id":1,"date":"2017-06-27"},
+			{"id":2,"date":"2017-06-28 11:00:00"},
+			{"id":3,"date":"2017-06-30"},
+			{"id":4,"date":"2017-06-29 23:00:00"}`,
+		"2017-07": `
+			{"id":5,"date":"2017-07-03 22:00:00"},
+			{"id":6,"date":"2017-07-05 14:00:00"},
+			{"id":7,"date":"2017-07-05 13:00:00"},
+			{"id":8,"date":"0000-00-00"}`,
+	})
+	defer f.Close()
+	bs := f.client(c)
+
+	episodes, err := bs.AiringWithin(ctx, 7*24*time.Hour, time.UTC)
+	c.Assert(err, IsNil)
+	c.Assert(airingIDs(episodes), DeepEquals, []int{2, 4, 3, 5, 7})
+	c.Assert(f.callsTo("/planning/member"), HasLen, 2)
+}
+
+func (s *MySuite) TestParseAirTime(c *C) {
+	tokyo := time.FixedZone("JST", 9*3600)
+	at, ok := parseAirTime(apiDate("2017-06-01 17:30:00"), tokyo)
+	c.Assert(ok, Equals, true)
+	c.Assert(at.timed, Equals, true)
+	c.Assert(at.at.Format("2006-01-02 15:04"), Equals, "2017-06-02 00:30")
+	c.Assert(at.day.Format("2006-01-02 15:04"), Equals, "2017-06-02 00:00")
+	at, ok = parseAirTime(apiDate("2017-06-01"), tokyo)
+	c.Assert(ok, Equals, true)
+	c.Assert(at.timed, Equals, false)
+	c.Assert(at.day.Format(time.RFC3339), Equals, "2017-06-01T00:00:00+09:00")
+	_, ok = parseAirTime(apiDate("0000-00-00"), tokyo)
+	c.Assert(ok, Equals, false)
+}
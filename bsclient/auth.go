@@ -0,0 +1,165 @@
+package bsclient
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrNotAuthenticated is returned without calling the API by the calls
+// needing a member token when the client has none and no credentials to
+// log in with, see Login. It is an ErrAuthRequired.
+var ErrNotAuthenticated = newError(ErrAuthRequired, "not authenticated")
+
+// ErrNoCredentials is returned when logging in with an empty login or
+// password (or password hash), rather than leaving the client anonymous.
+// It is an ErrInvalidInput.
+var ErrNoCredentials = newError(ErrInvalidInput, "missing login or password")
+
+// ErrInvalidPasswordHash is returned by LoginWithHash when the hash is not
+// an MD5 hash of 32 hexadecimal characters. It is an ErrInvalidInput.
+var ErrInvalidPasswordHash = newError(ErrInvalidInput, "invalid password hash: must be 32 hexadecimal characters")
+
+// memberEndpoints holds the paths of the endpoints acting on the account of
+// the authenticated member.
+var memberEndpoints = map[string]bool{
+	"/episodes/list":         true,
+	"/episodes/downloaded":   true,
+	"/episodes/watched":      true,
+	"/episodes/note":         true,
+	"/shows/show":            true,
+	"/shows/archive":         true,
+	"/shows/favorite":        true,
+	"/shows/note":            true,
+	"/members/is_active":     true,
+	"/members/notifications": true,
+	"/members/destroy":       true,
+	"/friends/requests":      true,
+	"/friends/friend":        true,
+	"/friends/block":         true,
+}
+
+// ownDataEndpoints holds the paths of the endpoints reading the data of the
+// member given by the id parameter, the authenticated member without it.
+var ownDataEndpoints = map[string]bool{
+	"/members/infos":   true,
+	"/planning/member": true,
+	"/shows/favorites": true,
+	"/friends/list":    true,
+}
+
+// needsToken reports whether the request to 'u' needs a member token.
+func (bs *BetaSeries) needsToken(u *url.URL) bool {
+	path := strings.TrimPrefix(u.Path, bs.baseURL.Path)
+	if ownDataEndpoints[path] {
+		return u.Query().Get("id") == ""
+	}
+	return memberEndpoints[path]
+}
+
+// checkAuth makes sure the client has a member token if the request to 'u'
+// needs one, logging in with the credentials kept by NewBetaseriesClient
+// or Login if it has none. It returns ErrNotAuthenticated if the client
+// has neither.
+func (bs *BetaSeries) checkAuth(ctx context.Context, u *url.URL) error {
+	if !bs.needsToken(u) {
+		return nil
+	}
+	bs.authMu.Lock()
+	defer bs.authMu.Unlock()
+	bs.tokenMu.Lock()
+	creds, authenticated := bs.credentials, bs.token != nil
+	bs.tokenMu.Unlock()
+	if authenticated {
+		return nil
+	}
+	if creds == nil {
+		return ErrNotAuthenticated
+	}
+	return bs.login(ctx, creds)
+}
+
+// hashPassword returns the MD5 hash of 'password' sent by the API logins.
+func hashPassword(password string) string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(password)))
+}
+
+// newCredentials returns the credentials of the member 'login', nil if
+// both 'login' and 'password' are empty. It returns ErrNoCredentials if
+// only one of them is.
+func newCredentials(login, password string) (*credentials, error) {
+	if login == "" && password == "" {
+		return nil, nil
+	}
+	if login == "" || password == "" {
+		return nil, ErrNoCredentials
+	}
+	return &credentials{login, hashPassword(password)}, nil
+}
+
+// Login authenticates the client as the member 'login', replacing the
+// current token if any. The credentials are kept to log in again when the
+// token expires. It returns ErrNoCredentials if 'login' or 'password' is
+// empty.
+func (bs *BetaSeries) Login(ctx context.Context, login, password string) error {
+	if login == "" || password == "" {
+		return ErrNoCredentials
+	}
+	return bs.login(ctx, &credentials{login, hashPassword(password)})
+}
+
+// LoginWithHash is Login for the applications keeping the MD5 hash of the
+// password rather than the password itself: 'md5hash' is sent as is, in
+// lower case. It returns ErrInvalidPasswordHash if it is not 32
+// hexadecimal characters.
+func (bs *BetaSeries) LoginWithHash(ctx context.Context, login, md5hash string) error {
+	if login == "" || md5hash == "" {
+		return ErrNoCredentials
+	}
+	if !isMD5Hash(md5hash) {
+		return ErrInvalidPasswordHash
+	}
+	return bs.login(ctx, &credentials{login, strings.ToLower(md5hash)})
+}
+
+// isMD5Hash reports whether 's' is made of 32 hexadecimal characters.
+func isMD5Hash(s string) bool {
+	if len(s) != 2*md5.Size {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Logout destroys the member token on the API side, if the client has
+// one, and forgets the token and the credentials of the client, whose
+// calls needing a member then fail with ErrNotAuthenticated. The client is
+// logged out even if the API fails to destroy the token, the error being
+// returned. It returns ErrNotAuthenticated if the client had neither a
+// token nor credentials.
+func (bs *BetaSeries) Logout(ctx context.Context) error {
+	bs.tokenMu.Lock()
+	creds, authenticated := bs.credentials, bs.token != nil
+	bs.credentials = nil
+	bs.tokenMu.Unlock()
+	if !authenticated {
+		if creds == nil {
+			return ErrNotAuthenticated
+		}
+		return nil
+	}
+	u := bs.endpoint("/members/destroy")
+	_, err := bs.send(ctx, "POST", u)
+	bs.tokenMu.Lock()
+	defer bs.tokenMu.Unlock()
+	bs.token = nil
+	bs.credentials = nil
+	bs.currentUser = nil
+	return err
+}
@@ -0,0 +1,146 @@
+package bsclient
+
+import (
+	"errors"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestLazyAuthentication(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("POST", "/members/auth", 200, `{"user":{"id":1,"login":"me"},"token":"t1","errors":[]}`)
+	f.handleJSON("POST", "/members/destroy", 200, `{"errors":[]}`)
+	f.handleJSON("GET", "/shows/search", 200, `{"shows":[{"id":1}],"errors":[]}`)
+	f.handleJSON("GET", "/members/infos", 200, `{"member":{"id":2,"login":"other"},"errors":[]}`)
+	f.handleJSON("POST", "/episodes/watched", 200, `{"episode":{"id":10},"errors":[]}`)
+
+	// the constructor does not call the API
+	bs, err := NewBetaseriesClient("key", "me", "password")
+	c.Assert(err, IsNil)
+	bs.baseURL, _ = parseBaseURL(f.URL)
+	c.Assert(f.calls, HasLen, 0)
+
+	// public endpoints are requested without logging in
+	_, err = bs.ShowsSearch(ctx, "show", "", false)
+	c.Assert(err, IsNil)
+	_, err = bs.MembersInfos(ctx, 2, true, "")
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/members/auth"), HasLen, 0)
+	c.Assert(f.callsTo("/shows/search")[0].Header.Get("X-BetaSeries-Token"), Equals, "")
+
+	// the first member call logs in
+	_, err = bs.EpisodeWatched(ctx, 10, 0, 0, false, false)
+	c.Assert(err, IsNil)
+	_, err = bs.EpisodeWatched(ctx, 10, 0, 0, false, false)
+	c.Assert(err, IsNil)
+	auth := f.callsTo("/members/auth")
+	c.Assert(auth, HasLen, 1)
+	c.Assert(auth[0].Form.Get("login"), Equals, "me")
+	c.Assert(auth[0].Form.Get("password"), Equals, hashPassword("password"))
+	c.Assert(f.callsTo("/episodes/watched")[0].Header.Get("X-BetaSeries-Token"), Equals, "t1")
+}
+
+func (s *MySuite) TestLoginLogout(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("POST", "/members/auth", 200, `{"user":{"id":1,"login":"me"},"token":"t1","errors":[]}`)
+	f.handleJSON("POST", "/members/destroy", 200, `{"errors":[]}`)
+	f.handleJSON("GET", "/members/infos", 200, `{"member":{"id":1,"login":"me"},"errors":[]}`)
+	bs, err := NewClient("key", WithBaseURL(f.URL))
+	c.Assert(err, IsNil)
+
+	// member calls fail without calling the API until the client logs in
+	_, err = bs.MembersInfos(ctx, 0, true, "")
+	c.Assert(err, Equals, ErrNotAuthenticated)
+	c.Assert(bs.Logout(ctx), Equals, ErrNotAuthenticated)
+	c.Assert(f.calls, HasLen, 0)
+
+	c.Assert(bs.Login(ctx, "me", "password"), IsNil)
+	c.Assert(bs.Token(), Equals, "t1")
+	member, err := bs.MembersInfos(ctx, 0, true, "")
+	c.Assert(err, IsNil)
+	c.Assert(member.Login, Equals, "me")
+
+	c.Assert(bs.Logout(ctx), IsNil)
+	destroy := f.callsTo("/members/destroy")
+	c.Assert(destroy, HasLen, 1)
+	c.Assert(destroy[0].Header.Get("X-BetaSeries-Token"), Equals, "t1")
+	c.Assert(bs.Token(), Equals, "")
+	calls := len(f.calls)
+	_, err = bs.MembersInfos(ctx, 0, true, "")
+	c.Assert(err, Equals, ErrNotAuthenticated)
+	c.Assert(f.calls, HasLen, calls)
+}
+
+func (s *MySuite) TestLogoutBeforeFirstCall(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("POST", "/members/auth", 200, `{"user":{"id":1,"login":"me"},"token":"t1","errors":[]}`)
+	f.handleJSON("POST", "/episodes/watched", 200, `{"episode":{"id":10},"errors":[]}`)
+	bs, err := NewBetaseriesClient("key", "me", "password")
+	c.Assert(err, IsNil)
+	bs.baseURL, _ = parseBaseURL(f.URL)
+
+	// the credentials are forgotten without calling the API
+	c.Assert(bs.Logout(ctx), IsNil)
+	c.Assert(f.calls, HasLen, 0)
+	_, err = bs.EpisodeWatched(ctx, 10, 0, 0, false, false)
+	c.Assert(err, Equals, ErrNotAuthenticated)
+	c.Assert(f.calls, HasLen, 0)
+	c.Assert(bs.Logout(ctx), Equals, ErrNotAuthenticated)
+}
+
+func (s *MySuite) TestLoginWithHash(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("POST", "/members/auth", 200, `{"user":{"id":1,"login":"me"},"token":"t1","errors":[]}`)
+	bs, err := NewClient("key", WithBaseURL(f.URL))
+	c.Assert(err, IsNil)
+
+	hash := hashPassword("password")
+	c.Assert(bs.LoginWithHash(ctx, "me", strings.ToUpper(hash)), IsNil)
+	c.Assert(bs.Token(), Equals, "t1")
+	auth := f.callsTo("/members/auth")
+	c.Assert(auth, HasLen, 1)
+	c.Assert(auth[0].Form.Get("login"), Equals, "me")
+	c.Assert(auth[0].Form.Get("password"), Equals, hash)
+
+	for _, invalid := range []string{"password", hash[:31], hash + "0", "z" + hash[1:]} {
+		c.Assert(bs.LoginWithHash(ctx, "me", invalid), Equals, ErrInvalidPasswordHash, Commentf(invalid))
+	}
+	c.Assert(f.callsTo("/members/auth"), HasLen, 1)
+
+	// the hash stays out of the errors of the failed logins
+	f.handleJSON("POST", "/members/auth", 400, `{"errors":[{"code":4003,"text":"Mot de passe invalide."}]}`)
+	err = bs.LoginWithHash(ctx, "me", hash)
+	c.Assert(err, NotNil)
+	c.Assert(strings.Contains(err.Error(), hash), Equals, false)
+	f.Close()
+	err = bs.Login(ctx, "me", "password")
+	c.Assert(err, NotNil)
+	c.Assert(strings.Contains(err.Error(), hash), Equals, false)
+}
+
+func (s *MySuite) TestNoCredentials(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	bs, err := NewClient("key", WithBaseURL(f.URL))
+	c.Assert(err, IsNil)
+	c.Assert(bs.Login(ctx, "me", ""), Equals, ErrNoCredentials)
+	c.Assert(bs.Login(ctx, "", "password"), Equals, ErrNoCredentials)
+	c.Assert(bs.LoginWithHash(ctx, "me", ""), Equals, ErrNoCredentials)
+	c.Assert(errors.Is(ErrNoCredentials, ErrInvalidInput), Equals, true)
+
+	_, err = NewClient("key", WithBaseURL(f.URL), WithCredentials("me", ""))
+	c.Assert(err, Equals, ErrNoCredentials)
+	_, err = NewBetaseriesClient("key", "", "password")
+	c.Assert(err, Equals, ErrNoCredentials)
+	c.Assert(f.calls, HasLen, 0)
+
+	// without any credentials, the client is anonymous
+	bs, err = NewBetaseriesClient("key", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(bs.Token(), Equals, "")
+}
@@ -0,0 +1,85 @@
+package bsclient
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// displayChunkSize is the number of ids requested at once from the multiple
+// display endpoints.
+const displayChunkSize = 50
+
+// BatchError holds the errors of the items which failed in a batch, keyed by
+// id. The batch functions return the results of the other items along with
+// it. The errors match ErrNotFound for missing items.
+type BatchError map[int]error
+
+func (e BatchError) Error() string {
+	ids := make([]int, 0, len(e))
+	for id := range e {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = fmt.Sprintf("%d: %s", id, strings.TrimSpace(e[id].Error()))
+	}
+	return fmt.Sprintf("%d failed: %s", len(e), strings.Join(parts, "; "))
+}
+
+// chunkIDs splits 'ids' into chunks of at most 'size' ids.
+func chunkIDs(ids []int, size int) [][]int {
+	var chunks [][]int
+	for start := 0; start < len(ids); start += size {
+		end := start + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunks = append(chunks, ids[start:end])
+	}
+	return chunks
+}
+
+// joinIDs formats 'ids' as the comma separated list of the multiple display
+// endpoints.
+func joinIDs(ids []int) string {
+	list := make([]string, len(ids))
+	for i, id := range ids {
+		list[i] = strconv.Itoa(id)
+	}
+	return strings.Join(list, ",")
+}
+
+// missingIDs adds to 'failed' the 'requested' ids which are not 'found'. They
+// fail with the API errors of the response if any, with 'notFound'
+// otherwise.
+func missingIDs(failed BatchError, requested []int, found map[int]bool, apiErr *errAPI, notFound error) {
+	for _, id := range requested {
+		if found[id] {
+			continue
+		}
+		if apiErr != nil {
+			failed[id] = apiErr
+		} else {
+			failed[id] = notFound
+		}
+	}
+}
+
+// decodePartial decodes the response of a multiple display, which holds the
+// entities found along with the API errors of the others, returned apart.
+func (bs *BetaSeries) decodePartial(data interface{}, body []byte) (*errAPI, error) {
+	apiErr := &errAPI{}
+	if err := decodeJSON(body, apiErr); err != nil {
+		return nil, err
+	}
+	if err := bs.unmarshal(data, body); err != nil {
+		return nil, err
+	}
+	if len(apiErr.Errors) == 0 {
+		return nil, nil
+	}
+	return apiErr, nil
+}
@@ -0,0 +1,126 @@
+package bsclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// mixedShows is a multiple display of shows/display with an unknown id.
+const mixedShows = `{"shows":[
+	{"id":1,"title":"Breaking Bad"},
+	{"id":2,"title":"Lost"}
+],"errors":[{"code":4001,"text":"Aucune série trouvée."}]}`
+
+func (s *MySuite) TestBatchPartialErrors(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handle("GET", "/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("id") {
+		case "1,2,999999":
+			writeFakeJSON(w, 200, mixedShows)
+		case "1":
+			writeFakeJSON(w, 200, `{"show":{"id":1,"title":"Breaking Bad"},"errors":[]}`)
+		case "3":
+			writeFakeJSON(w, 200, `{"errors":[{"code":2001,"text":"Token invalide."}]}`)
+		default:
+			writeFakeJSON(w, 400, `{"errors":[{"code":4001,"text":"Aucune série trouvée."}]}`)
+		}
+	})
+	f.handleJSON("GET", "/episodes/display", 200, `{"episodes":[{"id":10}],
+		"errors":[{"code":4002,"text":"Episode introuvable."}]}`)
+	bs := f.client(c)
+
+	shows, err := bs.ShowsDisplay(ctx, []int{1, 2, 999999})
+	c.Assert(shows, HasLen, 2)
+	c.Assert(shows[1].Title, Equals, "Lost")
+	var batchErr BatchError
+	c.Assert(errors.As(err, &batchErr), Equals, true)
+	c.Assert(batchErr, HasLen, 1)
+	c.Assert(errors.Is(batchErr[999999], ErrNotFound), Equals, true)
+	c.Assert(err, ErrorMatches, "1 failed: 999999: Aucune série trouvée.")
+
+	shows, err = bs.ShowsDisplay(ctx, []int{1})
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 1)
+	shows, err = bs.ShowsDisplay(ctx, []int{4, 5})
+	c.Assert(shows, HasLen, 0)
+	c.Assert(err, FitsTypeOf, BatchError{})
+	c.Assert(err.(BatchError), HasLen, 2)
+	c.Assert(errors.Is(err.(BatchError)[5], ErrNotFound), Equals, true)
+	_, err = bs.ShowsDisplay(ctx, []int{3})
+	c.Assert(errors.Is(err, ErrTokenInvalid), Equals, true)
+
+	exist, err := bs.ShowsExist(ctx, []int{1, 2, 999999})
+	c.Assert(err, IsNil)
+	c.Assert(exist, DeepEquals, map[int]bool{1: true, 2: true, 999999: false})
+
+	for _, id := range []int{10, 11} {
+		c.Assert(bs.PinEpisode(id), IsNil)
+	}
+	pinned, stale, err := bs.PinnedEpisodes(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(pinned, HasLen, 1)
+	c.Assert(stale, DeepEquals, []int{11})
+}
+
+func (s *MySuite) TestChunkIDs(c *C) {
+	c.Assert(chunkIDs(nil, 2), HasLen, 0)
+	c.Assert(chunkIDs([]int{1, 2, 3, 4, 5}, 2), DeepEquals, [][]int{{1, 2}, {3, 4}, {5}})
+	c.Assert(joinIDs([]int{1, 22, 333}), Equals, "1,22,333")
+}
+
+func (s *MySuite) TestShowsDisplayBatch(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	inFlight := &concurrencyGauge{}
+	f.handle("GET", "/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		inFlight.enter()
+		defer inFlight.leave()
+		id, _ := strconv.Atoi(r.URL.Query().Get("id"))
+		// the first shows answer last
+		time.Sleep(time.Duration(10-id) * time.Millisecond)
+		switch {
+		case id == 4:
+			writeFakeJSON(w, 404, `{"errors":[{"code":4001,"text":"Aucune série trouvée."}]}`)
+		case id == 6:
+			writeFakeJSON(w, 503, ``)
+		default:
+			writeFakeJSON(w, 200, `{"show":{"id":`+strconv.Itoa(id)+`},"errors":[]}`)
+		}
+	})
+	bs := f.client(c)
+
+	shows, err := bs.ShowsDisplayBatch(ctx, []int{1, 2, 3, 4, 5, 6, 7, 8}, 3)
+	ids := []int{}
+	for _, show := range shows {
+		ids = append(ids, show.ID)
+	}
+	c.Assert(ids, DeepEquals, []int{1, 2, 3, 5, 7, 8})
+	c.Assert(inFlight.max, Equals, 3)
+	c.Assert(f.callsTo("/shows/display"), HasLen, 8)
+	var batchErr BatchError
+	c.Assert(errors.As(err, &batchErr), Equals, true)
+	c.Assert(batchErr, HasLen, 2)
+	c.Assert(errors.Is(batchErr[4], ErrNotFound), Equals, true)
+	c.Assert(batchErr[6], NotNil)
+
+	shows, err = bs.ShowsDisplayBatch(ctx, []int{2, 1}, 0)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 2)
+	c.Assert(shows[0].ID, Equals, 2)
+	c.Assert(inFlight.max, Equals, 3)
+
+	// the shows are no longer requested once the context is done
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	calls := len(f.callsTo("/shows/display"))
+	shows, err = bs.ShowsDisplayBatch(canceled, []int{1, 2}, 1)
+	c.Assert(err, Equals, context.Canceled)
+	c.Assert(shows, HasLen, 0)
+	c.Assert(f.callsTo("/shows/display"), HasLen, calls)
+}
@@ -2,45 +2,48 @@
 package bsclient
 
 import (
-	"crypto/md5"
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
-	"log"
+	"io/ioutil"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
+// Version is the version of the bsclient package, sent in the User-Agent
+// header. It is unrelated to the API version.
+const Version = "0.4.0"
+
 const (
 	bsBaseURL = "https://api.betaseries.com"
 	bsVersion = "2.4"
+	userAgent = "bsclient/" + Version
 )
 
 var (
-	errNoToken    = errors.New("no token")
-	errURLParsing = errors.New("url parsing error")
+	// ErrAPIKeyMissing is returned without calling the API when the client
+	// has no API key, unless the endpoint is served without key or keyless
+	// requests are allowed (see SetAllowKeyless). It is an ErrInvalidAPIKey.
+	ErrAPIKeyMissing = newError(ErrInvalidAPIKey, "no api key")
 )
 
-type errorsAPI struct {
-	Code int    `json:"code"`
-	Text string `json:"text"`
-}
-
-// errAPI represents an error returned by the API
-type errAPI struct {
-	Errors []errorsAPI `json:"errors"`
-}
-
-func (e *errAPI) Error() string {
-	out := ""
-	for _, e := range e.Errors {
-		out += fmt.Sprintf("%s\n", e.Text)
-	}
-	return out
-}
+var (
+	errNoToken        = newError(ErrAuthRequired, "no token")
+	errEmptyToken     = newError(ErrInvalidInput, "empty token")
+	errInvalidLocale  = newError(ErrInvalidInput, "unsupported locale")
+	errInvalidBaseURL = newError(ErrInvalidInput, "invalid base url: must be an absolute http or https url")
+	errBodyTooLarge   = newError(ErrServiceUnavailable, "response body too large")
+	errTrailingData   = newError(ErrServiceUnavailable, "invalid character after top-level value")
+)
 
 // token is a struct return by the betaseries API when requesting a token
 type token struct {
@@ -56,110 +59,757 @@ type token struct {
 
 // BetaSeries represents the web client to the BetaSeries API
 type BetaSeries struct {
-	baseURL    string
+	baseURL    *url.URL
 	version    string
 	key        string
-	token      *token
 	httpClient *http.Client
+	// endpointVersions are the minimal API versions of the endpoints, see
+	// defaultEndpointVersions
+	endpointVersions map[string]string
+	// tokenMu guards the token, the credentials and the current user
+	tokenMu sync.Mutex
+	token   *token
+	// currentUser caches the member of the token, see CurrentUser
+	currentUser *Member
+	// credentials are kept to log in again when the token expires
+	credentials *credentials
+	// authMu serializes the logins renewing the token
+	authMu sync.Mutex
+	// validateWrites enables extra checks before risky mutations
+	validateWrites bool
+	// strictRefs rejects conflicting identifiers
+	strictRefs bool
+	// random is the source used by the random helpers, seeded per call if nil
+	random *rand.Rand
+	// rawCapture keeps the JSON of the decoded entities
+	rawCapture bool
+	// resolveFallback enables the title search of ResolveShow
+	resolveFallback bool
+	// keepSearchCase disables the lowercasing of the search queries
+	keepSearchCase bool
+	// locale is the language requested for the API texts
+	locale string
+	// application is the name put before the default User-Agent, if set
+	application string
+	// pins keeps the episodes pinned to watch later
+	pins PinStore
+	// allowKeyless disables the local check of the API key
+	allowKeyless bool
+	// maxClockSkew is the tolerated clock skew, the default one if zero
+	maxClockSkew time.Duration
+	// journal records the mutations, if set
+	journal *mutationJournal
+	// rateMu guards the rate limit
+	rateMu    sync.Mutex
+	rateLimit rateLimit
+	// rateLimitRetry enables the retry of the rate limited requests
+	rateLimitRetry bool
+	// onRequest and onResponse are the hooks called around the requests
+	onRequest  func(*http.Request)
+	onResponse func(*http.Request, *http.Response, time.Duration, error)
+	// onDeprecated is the hook called when the API version is deprecated
+	onDeprecated func(endpoint, version string)
+	// onJournalError is the hook called with the errors of the journal
+	onJournalError func(err error)
+	// excludeAdult removes the adult shows from the listings
+	excludeAdult bool
+	// noCompression asks for uncompressed responses
+	noCompression bool
+	// cache keeps the GET responses, if enabled
+	cache *responseCache
+	// warningsMu guards the API errors sent along with the last listing
+	warningsMu sync.Mutex
+	warnings   []APIError
+	// statsMu guards the counters of the requests
+	statsMu sync.Mutex
+	stats   map[string]EndpointStats
+}
+
+// SetWriteValidation enables or disables the extra requests performed
+// before mutations which could otherwise corrupt the user's account
+// (for instance checking that the last episode given to ShowAdd belongs
+// to the added show).
+func (bs *BetaSeries) SetWriteValidation(enabled bool) {
+	bs.validateWrites = enabled
+}
+
+// credentials are the login and password hash of a member.
+type credentials struct {
+	login string
+	hash  string
 }
 
 func (bs *BetaSeries) getToken() (string, error) {
+	bs.tokenMu.Lock()
+	defer bs.tokenMu.Unlock()
 	if bs.token != nil {
 		return bs.token.Token, nil
 	}
 	return "", errNoToken
 }
 
-// NewBetaseriesClient creates a betaseries web client
+// setToken replaces the token of the client.
+func (bs *BetaSeries) setToken(t *token) {
+	bs.tokenMu.Lock()
+	defer bs.tokenMu.Unlock()
+	bs.token = t
+	bs.currentUser = nil
+}
+
+// Token returns the member token of the client, empty if it is not
+// authenticated. It can be saved to restore the session later with
+// SetToken rather than logging in again.
+func (bs *BetaSeries) Token() string {
+	token, _ := bs.getToken()
+	return token
+}
+
+// TokenMember returns the id and login of the member the token was obtained
+// for, zero values if the client is not authenticated or the token was
+// restored with SetToken.
+func (bs *BetaSeries) TokenMember() (int, string) {
+	bs.tokenMu.Lock()
+	defer bs.tokenMu.Unlock()
+	if bs.token == nil {
+		return 0, ""
+	}
+	return bs.token.User.ID, bs.token.User.Login
+}
+
+// SetToken authenticates the client with a member token obtained earlier,
+// without calling the API: use MembersIsActive to check the token is still
+// valid. An empty token logs the client out.
+func (bs *BetaSeries) SetToken(value string) {
+	if value == "" {
+		bs.setToken(nil)
+		return
+	}
+	bs.setToken(&token{Token: value})
+}
+
+// NewBetaseriesClient creates a betaseries web client without calling the
+// API. If the credentials of a member are given, the client logs in on the
+// first call needing a member token; without them, the calls of the public
+// endpoints work with the API key alone and the others fail with
+// ErrNotAuthenticated until Login is called. Giving only one of 'login'
+// and 'password' is an error, ErrNoCredentials. See NewClient with
+// WithCredentials to log in when the client is created.
+// The client can be created without API key, its requests then fail with
+// ErrAPIKeyMissing (see SetAllowKeyless); logging in requires the key.
 func NewBetaseriesClient(key, login, password string) (*BetaSeries, error) {
+	creds, err := newCredentials(login, password)
+	if err != nil {
+		return nil, err
+	}
+	bs, _, err := newClient(key, nil)
+	if err != nil {
+		return nil, err
+	}
+	bs.credentials = creds
+	return bs, nil
+}
+
+// clientOptions holds the settings of the client being created.
+type clientOptions struct {
+	baseURL    string
+	version    string
+	login      string
+	password   string
+	httpClient *http.Client
+	journal    io.Writer
+	locale     string
+	// userAgent is set by WithUserAgent
+	userAgent string
+	cacheSize int
+	// cacheMaxBytes is set by WithCacheMaxBytes
+	cacheMaxBytes int
+	// excludeAdult is set by WithExcludeAdult
+	excludeAdult bool
+	// rateLimitRetry is set by WithRateLimitRetry
+	rateLimitRetry bool
+	// noCompression is set by WithoutCompression
+	noCompression bool
+}
+
+// Option is a setting of the client given to NewClient.
+type Option func(*clientOptions)
+
+// WithBaseURL sets the url of the API, https://api.betaseries.com by
+// default, for instance to use a mock server or a staging endpoint.
+// It must be an absolute http or https url without query.
+func WithBaseURL(baseURL string) Option {
+	return func(o *clientOptions) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithAPIVersion sets the API version sent with every request, see
+// SetAPIVersion.
+func WithAPIVersion(version string) Option {
+	return func(o *clientOptions) {
+		o.version = version
+	}
+}
+
+// WithCredentials authenticates the client as the member 'login' when it is
+// created. NewClient returns ErrNoCredentials if only one of 'login' and
+// 'password' is empty; both empty leave the client anonymous.
+func WithCredentials(login, password string) Option {
+	return func(o *clientOptions) {
+		o.login = login
+		o.password = password
+	}
+}
+
+// WithHTTPClient sets the http client sending the requests, the
+// authentication included, see SetHTTPClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = client
+	}
+}
+
+// NewClient creates a betaseries web client using the API key 'key' and
+// the given options. It returns an ErrInvalidInput error if the base url or
+// the locale is invalid. If the client is created with credentials and the
+// authentication fails, it is returned with the error.
+func NewClient(key string, opts ...Option) (*BetaSeries, error) {
+	bs, o, err := newClient(key, opts)
+	if err != nil {
+		return nil, err
+	}
+	// basic authentication, see ExchangeCode for OAuth 2.0.
+	err = bs.retrieveToken(context.Background(), o.login, o.password)
+	return bs, err
+}
+
+// NewClientFromToken creates a betaseries web client authenticated with the
+// member token 'token' obtained elsewhere, for instance from a secret
+// store, without calling the API. The credentials of the options are
+// ignored: an invalid or expired token is not renewed, the requests then
+// fail with an ErrTokenInvalid error. See CurrentUser for the identity of
+// the member.
+func NewClientFromToken(key, token string, opts ...Option) (*BetaSeries, error) {
+	if token == "" {
+		return nil, errEmptyToken
+	}
+	bs, _, err := newClient(key, opts)
+	if err != nil {
+		return nil, err
+	}
+	bs.SetToken(token)
+	return bs, nil
+}
+
+// newClient creates a client with the given options, without logging in.
+func newClient(key string, opts []Option) (*BetaSeries, *clientOptions, error) {
+	o := &clientOptions{
+		baseURL: bsBaseURL,
+		version: bsVersion,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	baseURL, err := parseBaseURL(o.baseURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	if o.version == "" {
+		o.version = bsVersion
+	}
+	if o.httpClient == nil {
+		o.httpClient = newHTTPClient()
+	}
+	bs := &BetaSeries{
+		version:          o.version,
+		endpointVersions: defaultEndpointVersions,
+		baseURL:          baseURL,
+		key:              key,
+		httpClient:       o.httpClient,
+		pins:             &memoryPinStore{},
+		rateLimitRetry:   o.rateLimitRetry,
+		excludeAdult:     o.excludeAdult,
+		noCompression:    o.noCompression,
+		application:      o.userAgent,
+	}
+	if o.journal != nil {
+		bs.SetMutationJournal(o.journal)
+	}
+	if o.cacheSize > 0 {
+		bs.cache = newResponseCache(o.cacheSize, o.cacheMaxBytes)
+	}
+	if err := bs.SetLocale(o.locale); err != nil {
+		return nil, nil, err
+	}
+	return bs, o, nil
+}
+
+// newHTTPClient returns the default http client of the betaseries clients.
+func newHTTPClient() *http.Client {
 	var netTransport = &http.Transport{
 		Dial: (&net.Dialer{
 			Timeout: 5 * time.Second,
 		}).Dial,
 		TLSHandshakeTimeout: 5 * time.Second,
 	}
-	bs := &BetaSeries{
-		version: bsVersion,
-		baseURL: bsBaseURL,
-		key:     key,
-		httpClient: &http.Client{
-			Timeout:   time.Second * 45,
-			Transport: netTransport,
-		},
-	}
-	// basic authentication.
-	// TODO: OAUTH 2.0
-	err := bs.retrieveToken(login, password)
-	return bs, err
+	return &http.Client{
+		Timeout:   time.Second * 45,
+		Transport: netTransport,
+	}
+}
+
+// SetHTTPClient sets the http client sending the requests, for instance to
+// use a proxy or other timeouts. A nil client restores the default one,
+// which times out after 45 seconds.
+// The authentication of NewBetaseriesClient is done with the default client,
+// use WithHTTPClient to authenticate with another one.
+func (bs *BetaSeries) SetHTTPClient(client *http.Client) {
+	if client == nil {
+		client = newHTTPClient()
+	}
+	bs.httpClient = client
+}
+
+// parseBaseURL parses the base url of the API, which must be an absolute
+// http or https url without query.
+func parseBaseURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.RawQuery != "" || u.Fragment != "" {
+		return nil, errInvalidBaseURL
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+	return u, nil
+}
+
+// endpoint returns the url of the API endpoint at 'path', a copy of the base
+// url the query can be set on.
+func (bs *BetaSeries) endpoint(path string) *url.URL {
+	u := *bs.baseURL
+	u.Path += path
+	return &u
+}
+
+// locales are the languages supported by the API
+var locales = map[string]bool{
+	"fr": true, "en": true, "es": true, "it": true,
+	"nl": true, "pl": true, "pt": true, "de": true,
+}
+
+// SetLocale sets the language requested for the texts of the API, error
+// messages included, sent in the X-BetaSeries-Locale and Accept-Language
+// headers. It must be one of fr, en, es, it, nl, pl, pt and de, other
+// values are rejected with an ErrInvalidInput error. An empty locale
+// restores the default one: the API answers in French. See CodeName for
+// identifiers of the errors which do not depend on the locale.
+func (bs *BetaSeries) SetLocale(locale string) error {
+	if locale != "" && !locales[locale] {
+		return errInvalidLocale
+	}
+	bs.locale = locale
+	return nil
+}
+
+// WithLocale sets the language requested for the texts of the API, the
+// authentication included, see SetLocale. An unsupported locale fails
+// NewClient.
+func WithLocale(locale string) Option {
+	return func(o *clientOptions) {
+		o.locale = locale
+	}
+}
+
+// WithUserAgent names the application in the User-Agent header, see
+// SetUserAgent.
+func WithUserAgent(application string) Option {
+	return func(o *clientOptions) {
+		o.userAgent = application
+	}
+}
+
+// SetUserAgent puts 'application', for instance "myapp/1.2", before the
+// default User-Agent header "bsclient/<Version>" of the requests, the
+// authentication included, as BetaSeries asks the applications to identify
+// themselves. An empty name restores the default header.
+func (bs *BetaSeries) SetUserAgent(application string) {
+	bs.application = strings.TrimSpace(application)
+}
+
+// userAgentHeader returns the User-Agent header of the requests.
+func (bs *BetaSeries) userAgentHeader() string {
+	if bs.application == "" {
+		return userAgent
+	}
+	return bs.application + " " + userAgent
+}
+
+// keylessEndpoints holds the paths of the endpoints the API serves without
+// API key.
+var keylessEndpoints = map[string]bool{
+	"/pictures/shows": true,
+}
+
+// SetAllowKeyless disables the local check failing the requests with
+// ErrAPIKeyMissing when the client has no API key, for instance when a proxy
+// between the client and the API adds the key.
+func (bs *BetaSeries) SetAllowKeyless(enabled bool) {
+	bs.allowKeyless = enabled
+}
+
+// checkKey returns ErrAPIKeyMissing if the request to 'u' needs an API key
+// the client does not have.
+func (bs *BetaSeries) checkKey(u *url.URL) error {
+	if bs.key != "" || bs.allowKeyless ||
+		keylessEndpoints[strings.TrimPrefix(u.Path, bs.baseURL.Path)] {
+		return nil
+	}
+	return ErrAPIKeyMissing
+}
+
+// defaultEndpointVersions holds the minimal API version required by the
+// endpoints which do not exist under the default version, keyed by endpoint
+// path (for instance "/shows/display"). None of the endpoints of the client
+// needs one yet.
+var defaultEndpointVersions = map[string]string{}
+
+// SetAPIVersion sets the API version sent with every request (2.4 by default).
+// Endpoints requiring a newer version are still requested with the version
+// they need.
+func (bs *BetaSeries) SetAPIVersion(version string) {
+	bs.version = version
+}
+
+// versionFor returns the API version to use for the request to 'u'.
+func (bs *BetaSeries) versionFor(u *url.URL) string {
+	endpoint := strings.TrimPrefix(u.Path, bs.baseURL.Path)
+	if version, ok := bs.endpointVersions[endpoint]; ok && versionLess(bs.version, version) {
+		return version
+	}
+	return bs.version
+}
+
+// versionLess reports whether the version 'a' (major.minor) is older than 'b'.
+func versionLess(a, b string) bool {
+	pa := strings.SplitN(a, ".", 2)
+	pb := strings.SplitN(b, ".", 2)
+	for i := 0; i < 2; i++ {
+		var na, nb int
+		if i < len(pa) {
+			na, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			nb, _ = strconv.Atoi(pb[i])
+		}
+		if na != nb {
+			return na < nb
+		}
+	}
+	return false
 }
 
 func (bs *BetaSeries) doRequest(req *http.Request) (*http.Response, error) {
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("X-BetaSeries-Version", bs.version)
-	req.Header.Set("X-BetaSeries-Key", bs.key)
-	if bs.token != nil {
-		req.Header.Set("X-BetaSeries-Token", bs.token.Token)
+	req.Header.Set("User-Agent", bs.userAgentHeader())
+	bs.setAcceptEncoding(req)
+	req.Header.Set("X-BetaSeries-Version", bs.versionFor(req.URL))
+	if bs.key != "" {
+		req.Header.Set("X-BetaSeries-Key", bs.key)
+	}
+	if token, err := bs.getToken(); err == nil {
+		req.Header.Set("X-BetaSeries-Token", token)
+	}
+	if bs.locale != "" {
+		req.Header.Set("X-BetaSeries-Locale", bs.locale)
+		req.Header.Set("Accept-Language", bs.locale)
 	}
 
-	return bs.httpClient.Do(req)
+	return bs.roundTrip(req)
 }
 
-func (bs *BetaSeries) do(method string, u *url.URL) (*http.Response, error) {
-	req, err := http.NewRequest(method, u.String(), nil)
+// formContentType is the content type of the parameters sent in the body
+const formContentType = "application/x-www-form-urlencoded"
+
+// newRequest returns the request of 'method' to 'u'. The parameters of 'u'
+// are sent in the query of the GET requests and form encoded in the body of
+// the others (POST and DELETE), as the API documentation recommends.
+func newRequest(ctx context.Context, method string, u *url.URL) (*http.Request, error) {
+	if method == "GET" || u.RawQuery == "" {
+		return http.NewRequestWithContext(ctx, method, u.String(), nil)
+	}
+	target := *u
+	target.RawQuery = ""
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), strings.NewReader(u.RawQuery))
 	if err != nil {
 		return nil, err
 	}
+	req.Header.Set("Content-Type", formContentType)
+	return req, nil
+}
+
+// maxBodySize caps the size of the response bodies read by the client,
+// once decompressed.
+const maxBodySize = 64 << 20
+
+// do sends the request and returns the response body. The body is read once,
+// up to maxBodySize bytes, and is always closed.
+// If 'ctx' is done before the body is read, its error is returned.
+// If the API rejects the token as invalid and the client logged in with
+// credentials, it logs in again and sends the request once more.
+func (bs *BetaSeries) do(ctx context.Context, method string, u *url.URL) ([]byte, error) {
+	sent, _ := bs.getToken()
+	body, err := bs.send(ctx, method, u)
+	if !tokenRejected(body, err) || !bs.reauthenticate(ctx, sent) {
+		return body, err
+	}
+	return bs.send(ctx, method, u)
+}
+
+// send sends the request and returns the response body, see do. A rate
+// limited request is sent once more if enabled, see SetRateLimitRetry.
+func (bs *BetaSeries) send(ctx context.Context, method string, u *url.URL) ([]byte, error) {
+	body, delay, err := bs.sendOnce(ctx, method, u)
+	if delay < 0 || !bs.rateLimitRetry {
+		return body, err
+	}
+	if err := sleep(ctx, delay); err != nil {
+		return nil, err
+	}
+	body, _, err = bs.sendOnce(ctx, method, u)
+	return body, err
+}
+
+// sendOnce sends the request once and returns the response body, along with
+// the delay before retrying it if it was rate limited, -1 otherwise. The
+// body of a 304 Not Modified response is the cached one, see WithCache.
+func (bs *BetaSeries) sendOnce(ctx context.Context, method string, u *url.URL) (body []byte, delay time.Duration, err error) {
+	if err := bs.checkKey(u); err != nil {
+		return nil, -1, err
+	}
+	if err := bs.checkAuth(ctx, u); err != nil {
+		return nil, -1, err
+	}
+	if run := warmUpFrom(ctx); run != nil {
+		release, err := run.acquire(ctx)
+		if err != nil {
+			return nil, -1, err
+		}
+		defer release()
+	}
+	req, err := newRequest(ctx, method, u)
+	if err != nil {
+		return nil, -1, err
+	}
+	var cached *cacheEntry
+	cacheKey := ""
+	if bs.cache != nil && method == "GET" {
+		cacheKey = bs.cacheKey(req.URL.String())
+		if !forceRefresh(ctx) {
+			if cached = bs.cache.get(cacheKey); cached != nil {
+				cached.setConditions(req)
+			}
+		}
+	}
+	start := time.Now()
+	status := 0
+	defer func() {
+		bs.recordStats(u.Path, status, time.Since(start), err)
+	}()
 	resp, err := bs.doRequest(req)
 	if err != nil {
-		return nil, err
+		return nil, -1, contextError(ctx, err)
+	}
+	defer resp.Body.Close()
+	status = resp.StatusCode
+	bs.updateRateLimit(resp)
+	if onDeprecated := bs.onDeprecated; onDeprecated != nil && deprecated(resp.Header) {
+		onDeprecated(strings.TrimPrefix(u.Path, bs.baseURL.Path), req.Header.Get("X-BetaSeries-Version"))
+	}
+	body, err = readResponse(resp)
+	if err != nil {
+		return nil, -1, contextError(ctx, err)
+	}
+	if sink, ok := ctx.Value(rawKey{}).(*rawSink); ok {
+		sink.body = body
+	}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, -1, err
+		}
+		bs.cache.hit()
+		return cached.body, -1, nil
 	}
 	if resp.StatusCode != http.StatusOK {
-		apiErr := decodeErr(resp.Body)
-		return nil, apiErr
+		delay = -1
+		if wait, retry := retryDelay(resp); resp.StatusCode == http.StatusTooManyRequests && retry {
+			delay = wait
+		}
+		return nil, delay, bs.withSkewHint(resp, decodeErr(resp.StatusCode, body))
+	}
+	if cacheKey != "" {
+		bs.cache.put(cacheKey, resp, body)
+	} else if bs.cache != nil {
+		bs.markStale(strings.TrimPrefix(u.Path, bs.baseURL.Path))
 	}
-	return resp, nil
+	return body, -1, nil
 }
 
-func (bs *BetaSeries) decode(data interface{}, resp *http.Response, usedAPI, query string) error {
-	if err := json.NewDecoder(resp.Body).Decode(data); err != nil {
-		return err
+// tokenRejected reports whether the API answered a request with the invalid
+// token error, as the error of the request or in the errors of a 200 OK
+// body.
+func tokenRejected(body []byte, err error) bool {
+	if err != nil {
+		return errors.Is(err, ErrTokenInvalid)
 	}
-	return nil
+	if !bytes.Contains(body, []byte(strconv.Itoa(CodeInvalidToken))) {
+		return false
+	}
+	apiErr := &errAPI{}
+	return decodeJSON(body, apiErr) == nil && apiErr.Is(ErrTokenInvalid)
 }
 
-func decodeErr(r io.Reader) *errAPI {
-	err := &errAPI{}
-	// note that 404 error not found on 'picture, err = bs.PicturesShows(0, 100, 100)' is not handled by errAPI
-	json.NewDecoder(r).Decode(&err)
+// reauthenticate logs in again with the credentials of the client after the
+// token 'stale' was rejected, unless another request already renewed it.
+// It reports whether the request can be sent again.
+func (bs *BetaSeries) reauthenticate(ctx context.Context, stale string) bool {
+	bs.authMu.Lock()
+	defer bs.authMu.Unlock()
+	bs.tokenMu.Lock()
+	creds := bs.credentials
+	current := ""
+	if bs.token != nil {
+		current = bs.token.Token
+	}
+	bs.tokenMu.Unlock()
+	if creds == nil {
+		return false
+	}
+	if current != stale {
+		return true
+	}
+	return bs.login(ctx, creds) == nil
+}
+
+// contextError returns the error of 'ctx' if it is done, which caused 'err',
+// and 'err' otherwise.
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
 	return err
 }
 
-func (bs *BetaSeries) retrieveToken(login, password string) error {
-	usedAPI := "/members/auth"
-	if len(login) == 0 || len(password) == 0 {
+func readBody(r io.Reader) ([]byte, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(r, maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxBodySize {
+		return nil, errBodyTooLarge
+	}
+	return trimBody(body), nil
+}
+
+// utf8BOM is the byte order mark some proxies prefix the bodies with.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// trimBody strips the byte order mark and the leading whitespace of 'body',
+// which the JSON decoder rejects or the raw capture would keep.
+func trimBody(body []byte) []byte {
+	if len(body) == 0 || (body[0] != utf8BOM[0] && !isSpace(body[0])) {
+		return body
+	}
+	return bytes.TrimLeft(bytes.TrimPrefix(body, utf8BOM), " \t\r\n")
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n'
+}
+
+func (bs *BetaSeries) decode(data interface{}, body []byte, usedAPI, query string) error {
+	// the API may answer 200 OK with an errors-only body (e.g. for an
+	// invalid token): report those errors rather than an empty result.
+	if bs.fromCache(data, body) {
 		return nil
 	}
+	apiErr := &errAPI{}
+	if decodeJSON(body, apiErr) == nil && len(apiErr.Errors) > 0 {
+		return apiErr
+	}
+	return bs.unmarshal(data, body)
+}
 
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		log.Fatalln(err)
+// unmarshal decodes the body into 'data', keeping the JSON of the entities
+// if raw capture is enabled. A body of the cache is only decoded once, see
+// WithCache.
+func (bs *BetaSeries) unmarshal(data interface{}, body []byte) error {
+	if bs.fromCache(data, body) {
+		return nil
+	}
+	err := decodeJSON(body, data)
+	if err == nil && bs.rawCapture {
+		captureRaw(reflect.ValueOf(data), body)
+	}
+	if err == nil && bs.cache != nil && !bs.rawCapture {
+		bs.cache.keepDecoded(body, data)
 	}
+	return err
+}
+
+// fromCache sets 'data' to a copy of the value decoded earlier from 'body',
+// if it is a body of the cache, and reports whether it did. The raw capture
+// needs the body to be decoded.
+func (bs *BetaSeries) fromCache(data interface{}, body []byte) bool {
+	return bs.cache != nil && !bs.rawCapture && bs.cache.decoded(body, data)
+}
+
+// decodeJSON decodes 'body' into 'v' keeping the numbers decoded into
+// interface{} values as json.Number rather than float64, which cannot
+// represent every large id exactly.
+func decodeJSON(body []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errTrailingData
+	}
+	return nil
+}
+
+func (bs *BetaSeries) retrieveToken(ctx context.Context, login, password string) error {
+	creds, err := newCredentials(login, password)
+	if err != nil || creds == nil {
+		return err
+	}
+	return bs.login(ctx, creds)
+}
+
+// login authenticates the client with the credentials 'creds', kept to
+// log in again when the token expires.
+func (bs *BetaSeries) login(ctx context.Context, creds *credentials) error {
+	usedAPI := "/members/auth"
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
-	q.Set("login", login)
-	q.Set("password", fmt.Sprintf("%x", md5.Sum([]byte(password))))
+	q.Set("login", creds.login)
+	q.Set("password", creds.hash)
 	u.RawQuery = q.Encode()
 
-	resp, err := bs.do("POST", u)
+	body, err := bs.send(ctx, "POST", u)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-
 	tokenData := &token{}
-	err = bs.decode(tokenData, resp, usedAPI, u.RawQuery)
+	// the query holds the password hash: keep it out of the errors
+	err = bs.decode(tokenData, body, usedAPI, "")
 	if err != nil {
 		return err
 	}
+	bs.tokenMu.Lock()
+	defer bs.tokenMu.Unlock()
 	bs.token = tokenData
+	bs.credentials = creds
+	bs.currentUser = nil
 	return nil
 }
@@ -1,8 +1,19 @@
 package bsclient
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
 	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	. "gopkg.in/check.v1"
 )
@@ -13,35 +24,255 @@ type MySuite struct{}
 
 var _ = Suite(&MySuite{})
 
+// ctx is the context of the requests of the tests.
+var ctx = context.Background()
+
 // export BS_API_KEY=YOUR_API_KEY && go test ...bsclient -gocheck.vv -test.v -gocheck.f TestNAME
 func (s *MySuite) TestNewBS(c *C) {
 	bs, err := NewBetaseriesClient("", "", "")
 	c.Assert(err, IsNil)
 	expected := &BetaSeries{
-		version:    bsVersion,
-		baseURL:    bsBaseURL,
-		httpClient: bs.httpClient,
+		version:          bsVersion,
+		endpointVersions: defaultEndpointVersions,
+		baseURL:          bs.baseURL,
+		httpClient:       bs.httpClient,
+		pins:             &memoryPinStore{},
 	}
 	c.Assert(bs, DeepEquals, expected)
+	c.Assert(bs.baseURL.String(), Equals, bsBaseURL)
+}
+
+func (s *MySuite) TestBaseURL(c *C) {
+	for _, raw := range []string{"", "api.betaseries.com", "ftp://api.betaseries.com",
+		"https://", "https://api.betaseries.com/?key=1", "%zz"} {
+		_, err := parseBaseURL(raw)
+		c.Assert(err, Equals, errInvalidBaseURL, Commentf("%q", raw))
+	}
+	u, err := parseBaseURL("http://localhost:8080/proxy/")
+	c.Assert(err, IsNil)
+	bs := &BetaSeries{baseURL: u}
+	c.Assert(bs.endpoint("/shows/display").String(), Equals, "http://localhost:8080/proxy/shows/display")
+	// endpoints are copies
+	bs.endpoint("/shows/display").RawQuery = "id=1"
+	c.Assert(bs.endpoint("/news/last").String(), Equals, "http://localhost:8080/proxy/news/last")
+	c.Assert(u.String(), Equals, "http://localhost:8080/proxy")
+}
+
+func (s *MySuite) TestNewClient(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("POST", "/api/members/auth", 200, `{"user":{"id":1,"login":"Dev050"},"token":"abc","errors":[]}`)
+	client := &http.Client{Timeout: time.Second}
+	bs, err := NewClient("key", WithBaseURL(f.URL+"/api/"), WithAPIVersion("3.0"),
+		WithCredentials("Dev050", "developer"), WithHTTPClient(client))
+	c.Assert(err, IsNil)
+	c.Assert(bs.baseURL.String(), Equals, f.URL+"/api")
+	c.Assert(bs.version, Equals, "3.0")
+	c.Assert(bs.httpClient, Equals, client)
+	c.Assert(bs.token.Token, Equals, "abc")
+	calls := f.callsTo("/api/members/auth")
+	c.Assert(calls, HasLen, 1)
+	c.Assert(calls[0].Header.Get("X-BetaSeries-Version"), Equals, "3.0")
+
+	// defaults
+	bs, err = NewClient("key", WithAPIVersion(""), WithHTTPClient(nil))
+	c.Assert(err, IsNil)
+	c.Assert(bs.baseURL.String(), Equals, bsBaseURL)
+	c.Assert(bs.version, Equals, bsVersion)
+	c.Assert(bs.httpClient.Timeout, Equals, 45*time.Second)
+	c.Assert(bs.token, IsNil)
+
+	bs, err = NewClient("key", WithBaseURL("api.betaseries.com"))
+	c.Assert(err, Equals, errInvalidBaseURL)
+	c.Assert(bs, IsNil)
+}
+
+func (s *MySuite) TestRestoreToken(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("POST", "/members/auth", 200, `{"user":{"id":7,"login":"Dev050"},"token":"abc","errors":[]}`)
+	f.handleJSON("GET", "/members/is_active", 200, `{"errors":[]}`)
+	f.handleJSON("POST", "/shows/show", 200, `{"show":{"id":1},"errors":[]}`)
+	bs, err := NewClient("key", WithBaseURL(f.URL), WithCredentials("Dev050", "developer"))
+	c.Assert(err, IsNil)
+	c.Assert(bs.Token(), Equals, "abc")
+	id, login := bs.TokenMember()
+	c.Assert(id, Equals, 7)
+	c.Assert(login, Equals, "Dev050")
+
+	restored, err := NewClient("key", WithBaseURL(f.URL))
+	c.Assert(err, IsNil)
+	c.Assert(restored.Token(), Equals, "")
+	c.Assert(restored.MembersIsActive(ctx), Equals, errNoToken)
+	restored.SetToken(bs.Token())
+	c.Assert(restored.Token(), Equals, "abc")
+	id, login = restored.TokenMember()
+	c.Assert(id, Equals, 0)
+	c.Assert(login, Equals, "")
+	c.Assert(restored.MembersIsActive(ctx), IsNil)
+	_, err = restored.ShowAdd(ctx, 1, 0, "", 0)
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/members/auth"), HasLen, 1)
+	for _, path := range []string{"/members/is_active", "/shows/show"} {
+		c.Assert(f.callsTo(path)[0].Header.Get("X-BetaSeries-Token"), Equals, "abc")
+	}
+
+	f.handleJSON("GET", "/members/is_active", 400, `{"errors":[{"code":2001,"text":"Token invalide."}]}`)
+	err = restored.MembersIsActive(ctx)
+	c.Assert(errors.Is(err, ErrTokenInvalid), Equals, true)
+	f.handleJSON("GET", "/members/is_active", 200, `{"errors":[{"code":2001,"text":"Token invalide."}]}`)
+	err = restored.MembersIsActive(ctx)
+	c.Assert(errors.Is(err, ErrTokenInvalid), Equals, true)
+
+	restored.SetToken("")
+	c.Assert(restored.Token(), Equals, "")
+	c.Assert(restored.token, IsNil)
+}
+
+func (s *MySuite) TestClientFromToken(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/members/infos", 200, `{"member":{"id":7,"login":"Dev050"},"errors":[]}`)
+	f.handleJSON("GET", "/shows/display", 200, `{"show":{"id":1},"errors":[]}`)
+
+	_, err := NewClientFromToken("key", "", WithBaseURL(f.URL))
+	c.Assert(err, Equals, errEmptyToken)
+	_, err = NewClientFromToken("key", "abc", WithBaseURL(":"))
+	c.Assert(err, Equals, errInvalidBaseURL)
+
+	// the credentials are ignored and nothing is sent until the first call
+	bs, err := NewClientFromToken("key", "abc", WithBaseURL(f.URL), WithCredentials("Dev050", "developer"))
+	c.Assert(err, IsNil)
+	c.Assert(bs.Token(), Equals, "abc")
+	c.Assert(f.calls, HasLen, 0)
+
+	for i := 0; i < 2; i++ {
+		user, err := bs.CurrentUser(ctx)
+		c.Assert(err, IsNil)
+		c.Assert(user.ID, Equals, 7)
+		c.Assert(user.Login, Equals, "Dev050")
+		user.Login = "changed"
+	}
+	c.Assert(f.callsTo("/members/infos"), HasLen, 1)
+	c.Assert(f.callsTo("/members/infos")[0].RawQuery, Equals, "summary=true")
+	c.Assert(f.callsTo("/members/infos")[0].Header.Get("X-BetaSeries-Token"), Equals, "abc")
+
+	// a new token fetches the member again
+	bs.SetToken("def")
+	_, err = bs.CurrentUser(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/members/infos"), HasLen, 2)
+
+	// an invalid token fails on use, without logging in again
+	f.handleJSON("GET", "/shows/display", 400, `{"errors":[{"code":2001,"text":"Token invalide."}]}`)
+	f.handleJSON("GET", "/members/infos", 400, `{"errors":[{"code":2001,"text":"Token invalide."}]}`)
+	invalid, err := NewClientFromToken("key", "expired", WithBaseURL(f.URL), WithCredentials("Dev050", "developer"))
+	c.Assert(err, IsNil)
+	_, err = invalid.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(errors.Is(err, ErrTokenInvalid), Equals, true)
+	_, err = invalid.CurrentUser(ctx)
+	c.Assert(errors.Is(err, ErrTokenInvalid), Equals, true)
+	c.Assert(f.callsTo("/members/auth"), HasLen, 0)
+	c.Assert(f.callsTo("/shows/display"), HasLen, 1)
+
+	invalid.SetToken("")
+	_, err = invalid.CurrentUser(ctx)
+	c.Assert(err, Equals, errNoToken)
+}
+
+func (s *MySuite) TestReauthenticate(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	var mu sync.Mutex
+	logins := 0
+	f.handle("POST", "/members/auth", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		logins++
+		n := logins
+		mu.Unlock()
+		writeFakeJSON(w, 200, fmt.Sprintf(`{"user":{"id":7},"token":"token%d","errors":[]}`, n))
+	})
+	// the API rejects the first token, with an error status or in a 200 OK body
+	status := http.StatusBadRequest
+	f.handle("GET", "/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-BetaSeries-Token") == "token1" {
+			writeFakeJSON(w, status, `{"errors":[{"code":2001,"text":"Token invalide."}]}`)
+			return
+		}
+		writeFakeJSON(w, 200, `{"show":{"id":1},"errors":[]}`)
+	})
+	for _, status = range []int{http.StatusBadRequest, http.StatusOK} {
+		logins = 0
+		f.calls = nil
+		bs, err := NewClient("key", WithBaseURL(f.URL), WithCredentials("Dev050", "developer"))
+		c.Assert(err, IsNil)
+		show, err := bs.ShowDisplay(ctx, 1, 0, "")
+		c.Assert(err, IsNil)
+		c.Assert(show.ID, Equals, 1)
+		c.Assert(bs.Token(), Equals, "token2")
+		paths := []string{}
+		for _, call := range f.calls {
+			paths = append(paths, call.Method+" "+call.Path+" "+call.Header.Get("X-BetaSeries-Token"))
+		}
+		c.Assert(paths, DeepEquals, []string{"POST /members/auth ", "GET /shows/display token1",
+			"POST /members/auth token1", "GET /shows/display token2"})
+	}
+
+	// concurrent requests log in again once
+	logins = 0
+	bs, err := NewClient("key", WithBaseURL(f.URL), WithCredentials("Dev050", "developer"))
+	c.Assert(err, IsNil)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := bs.ShowDisplay(ctx, 1, 0, "")
+			c.Check(err, IsNil)
+		}()
+	}
+	wg.Wait()
+	c.Assert(logins, Equals, 2)
+
+	// the request is sent again once only
+	f.handleJSON("GET", "/shows/display", 400, `{"errors":[{"code":2001,"text":"Token invalide."}]}`)
+	f.calls = nil
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(errors.Is(err, ErrTokenInvalid), Equals, true)
+	c.Assert(f.callsTo("/shows/display"), HasLen, 2)
+	c.Assert(f.callsTo("/members/auth"), HasLen, 1)
+
+	// without credentials the error is returned
+	f.calls = nil
+	restored := f.client(c)
+	_, err = restored.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(errors.Is(err, ErrTokenInvalid), Equals, true)
+	c.Assert(f.calls, HasLen, 1)
 }
 
 func (s *MySuite) TestNewBSGetTokenWithoutAPIKey(c *C) {
 	bs, err := NewBetaseriesClient("", "Dev050", "developer")
-	c.Assert(err.Error(), Equals, "Veuillez spécifier une clé API.\n")
-	c.Assert(bs, NotNil)
+	c.Assert(err, IsNil)
 	expected := &BetaSeries{
-		version:    bsVersion,
-		baseURL:    bsBaseURL,
-		httpClient: bs.httpClient,
+		version:          bsVersion,
+		endpointVersions: defaultEndpointVersions,
+		baseURL:          bs.baseURL,
+		httpClient:       bs.httpClient,
+		pins:             &memoryPinStore{},
+		credentials:      &credentials{"Dev050", hashPassword("developer")},
 	}
 	c.Assert(bs, DeepEquals, expected)
+	// logging in requires the key
+	_, err = bs.EpisodesList(ctx, 0, 0, "", 0, 0, -1, false, false)
+	c.Assert(err, Equals, ErrAPIKeyMissing)
+	c.Assert(bs.Token(), Equals, "")
 }
 
 func (s *MySuite) TestNewBSGetTokenWithAPIKey(c *C) {
 	key := os.Getenv("BS_API_KEY")
-	bs, err := NewBetaseriesClient(key, "Dev050", "developer")
+	bs, err := NewBetaseriesClient(key, "", "")
 	c.Assert(err, IsNil)
-	c.Assert(bs, NotNil)
+	c.Assert(bs.Login(ctx, "Dev050", "developer"), IsNil)
 	token, err := bs.getToken()
 	c.Assert(err, IsNil)
 	c.Assert(len(token), Equals, 12)
@@ -57,24 +288,574 @@ func (s *MySuite) TestNewBSGetToken(c *C) {
 	c.Assert(err, Equals, errNoToken)
 }
 
+// pathsTransport records the paths of the requests it sends.
+type pathsTransport struct {
+	paths []string
+}
+
+func (t *pathsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.paths = append(t.paths, req.Method+" "+req.URL.Path)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (s *MySuite) TestHTTPClient(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	release := make(chan struct{})
+	defer close(release)
+	f.handleJSON("POST", "/members/auth", 200, `{"user":{"id":1,"login":"me"},"token":"t","errors":[]}`)
+	f.handleJSON("GET", "/shows/display", 200, `{"show":{"id":1},"errors":[]}`)
+	f.handleJSON("GET", "/news/last", 200, `{"news":[],"errors":[]}`)
+	f.handleJSON("GET", "/members/infos", 200, `{"member":{"id":1},"errors":[]}`)
+	f.handle("GET", "/shows/episodes", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	bs := f.client(c)
+
+	transport := &pathsTransport{}
+	bs.SetHTTPClient(&http.Client{Transport: transport, Timeout: 50 * time.Millisecond})
+	c.Assert(bs.retrieveToken(ctx, "me", "password"), IsNil)
+	_, err := bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(bs.SelfCheck(ctx), HasLen, 0)
+	c.Assert(transport.paths, DeepEquals, []string{"POST /members/auth", "GET /shows/display",
+		"GET /news/last", "GET /members/infos"})
+
+	// the client timeout applies to every call
+	start := time.Now()
+	_, err = bs.ShowsEpisodes(ctx, 1, 0, 0, 0, false)
+	var netErr net.Error
+	c.Assert(errors.As(err, &netErr), Equals, true)
+	c.Assert(netErr.Timeout(), Equals, true)
+	c.Assert(time.Since(start) < time.Second, Equals, true)
+
+	bs.SetHTTPClient(nil)
+	c.Assert(bs.httpClient, NotNil)
+	c.Assert(bs.httpClient.Timeout, Equals, 45*time.Second)
+	c.Assert(transport.paths, HasLen, 5)
+}
+
+// agentsTransport records the User-Agent header of the requests it sends.
+type agentsTransport struct {
+	agents []string
+}
+
+func (t *agentsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.agents = append(t.agents, req.Method+" "+req.URL.Path+": "+req.Header.Get("User-Agent"))
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (s *MySuite) TestUserAgent(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("POST", "/members/auth", 200, `{"user":{"id":1,"login":"me"},"token":"t","errors":[]}`)
+	f.handleJSON("GET", "/shows/list", 200, `{"shows":[{"id":1}],"errors":[]}`)
+	f.handleJSON("POST", "/shows/show", 200, `{"show":{"id":1},"errors":[]}`)
+	transport := &agentsTransport{}
+	bs, err := NewClient("key", WithBaseURL(f.URL), WithCredentials("me", "password"),
+		WithHTTPClient(&http.Client{Transport: transport}), WithUserAgent("myapp/1.2"))
+	c.Assert(err, IsNil)
+	_, err = bs.ShowsList(ctx, "", "", "", 0, 10)
+	c.Assert(err, IsNil)
+	_, err = bs.ShowAdd(ctx, 1, 0, "", 0)
+	c.Assert(err, IsNil)
+	bs.SetUserAgent("")
+	_, err = bs.ShowsList(ctx, "", "", "", 0, 10)
+	c.Assert(err, IsNil)
+	c.Assert(transport.agents, DeepEquals, []string{
+		"POST /members/auth: myapp/1.2 bsclient/" + Version,
+		"GET /shows/list: myapp/1.2 bsclient/" + Version,
+		"POST /shows/show: myapp/1.2 bsclient/" + Version,
+		"GET /shows/list: bsclient/" + Version,
+	})
+}
+
+func (s *MySuite) TestContext(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{}, 1)
+	f.handle("GET", "/shows/search", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		fmt.Fprint(w, `{"shows":[{"id":1},`)
+		w.(http.Flusher).Flush()
+		started <- struct{}{}
+		<-release
+	})
+	f.handle("GET", "/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	bs := f.client(c)
+
+	// canceled before the request
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := bs.ShowDisplay(canceled, 1, 0, "")
+	c.Assert(err, Equals, context.Canceled)
+	c.Assert(f.calls, HasLen, 0)
+
+	// no response in time
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = bs.ShowDisplay(timeout, 1, 0, "")
+	c.Assert(err, Equals, context.DeadlineExceeded)
+
+	// canceled while reading the body
+	reading, cancel := context.WithCancel(ctx)
+	go func() {
+		<-started
+		cancel()
+	}()
+	_, err = bs.ShowsSearch(reading, "breaking bad", "", false)
+	c.Assert(err, Equals, context.Canceled)
+	c.Assert(f.callsTo("/shows/search"), HasLen, 1)
+}
+
+// keyTransport stands for a proxy adding the API key to the requests.
+type keyTransport struct{}
+
+func (keyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("X-BetaSeries-Key", "proxy key")
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (s *MySuite) TestKeyless(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/display", 200, `{"show":{"id":1},"errors":[]}`)
+	f.handleJSON("GET", "/pictures/shows", 200, "picture")
+	f.handleJSON("GET", "/news/last", 200, `{"news":[],"errors":[]}`)
+	bs := f.client(c)
+	bs.key = ""
+	bs.token = nil
+
+	// blocked locally
+	_, err := bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, Equals, ErrAPIKeyMissing)
+	c.Assert(errors.Is(err, ErrInvalidAPIKey), Equals, true)
+	problems := bs.SelfCheck(ctx)
+	c.Assert(problems, DeepEquals, []Problem{{CheckAPIKey, true, ErrAPIKeyMissing}})
+	c.Assert(f.calls, HasLen, 0)
+
+	// served without key
+	picture, err := bs.PicturesShows(ctx, 1, 0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(picture, Equals, "picture")
+	calls := f.callsTo("/pictures/shows")
+	c.Assert(calls, HasLen, 1)
+	_, sent := calls[0].Header["X-Betaseries-Key"]
+	c.Assert(sent, Equals, false)
+
+	// allowed, the key being added by a proxy
+	bs.SetAllowKeyless(true)
+	bs.httpClient.Transport = keyTransport{}
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	calls = f.callsTo("/shows/display")
+	c.Assert(calls, HasLen, 1)
+	c.Assert(calls[0].Header.Get("X-BetaSeries-Key"), Equals, "proxy key")
+	c.Assert(bs.SelfCheck(ctx), HasLen, 0)
+}
+
 func makeClientAndAddShow(c *C) (*BetaSeries, string, int) {
 	key := os.Getenv("BS_API_KEY")
 	bs, err := NewBetaseriesClient(key, "Dev050", "developer")
 	c.Assert(err, IsNil)
-	_, err = bs.EpisodesList(0, 0)
+	_, err = bs.EpisodesList(ctx, 0, 0, "", 0, 0, -1, false, false)
 	c.Assert(err, NotNil)
 	// meaning null/nil return
 	c.Assert(err.Error(), Equals, "")
 
-	shows, err := bs.ShowsSearch(tvShowTest)
+	shows, err := bs.ShowsSearch(ctx, tvShowTest, "", false)
 	c.Assert(err, IsNil)
 	c.Assert(len(shows), Equals, 1)
 
 	// make sure the tv show is not in the user account first
-	bs.ShowRemove(shows[0].ID, 0)
+	bs.ShowRemove(ctx, shows[0].ID, 0, "")
 
-	show, err := bs.ShowAdd(shows[0].ID, 0)
+	show, err := bs.ShowAdd(ctx, shows[0].ID, 0, "", 0)
 	c.Assert(err, IsNil)
 	c.Assert(show.InAccount, Equals, true)
 	return bs, key, shows[0].ID
 }
+
+func (s *MySuite) TestAPIErrorMapping(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	bs := f.client(c)
+	for _, test := range []struct {
+		status   int
+		code     int
+		expected error
+	}{
+		{401, 2001, ErrTokenInvalid},
+		{400, 2001, ErrTokenInvalid},
+		{403, 2005, ErrPremiumRequired},
+		{400, 1001, ErrInvalidAPIKey},
+		{403, 1002, ErrInvalidAPIKey},
+		{400, 4001, ErrNotFound},
+		{400, 4002, ErrNotFound},
+		{403, 2003, ErrProfilePrivate},
+		{400, 3001, nil},
+	} {
+		f.handleJSON("GET", "/members/infos", test.status,
+			fmt.Sprintf(`{"errors":[{"code":%d,"text":"error"}]}`, test.code))
+		_, err := bs.MembersInfos(ctx, 0, true, "")
+		c.Assert(err, NotNil)
+		for _, sentinel := range []error{ErrTokenInvalid, ErrPremiumRequired, ErrInvalidAPIKey,
+			ErrNotFound, ErrMemberNotFound, ErrProfilePrivate} {
+			c.Assert(errors.Is(err, sentinel), Equals, sentinel == test.expected,
+				Commentf("status %d, code %d, sentinel %v", test.status, test.code, sentinel))
+		}
+	}
+}
+
+func (s *MySuite) TestMemberPrivacyErrors(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	bs := f.client(c)
+	calls := map[string]func() error{
+		"/shows/favorites": func() error {
+			_, err := bs.ShowsFavorites(ctx, 42)
+			return err
+		},
+		"/members/infos": func() error {
+			_, err := bs.MembersInfos(ctx, 42, false, "shows")
+			return err
+		},
+		"/planning/member": func() error {
+			_, err := bs.PlanningMember(ctx, 42, false, "")
+			return err
+		},
+	}
+	for path, call := range calls {
+		for _, test := range []struct {
+			code     int
+			expected error
+			other    error
+		}{
+			{4001, ErrMemberNotFound, ErrProfilePrivate},
+			{2003, ErrProfilePrivate, ErrMemberNotFound},
+		} {
+			f.handleJSON("GET", path, 400,
+				fmt.Sprintf(`{"errors":[{"code":%d,"text":"error"}]}`, test.code))
+			err := call()
+			c.Assert(errors.Is(err, test.expected), Equals, true, Commentf("%s, code %d", path, test.code))
+			c.Assert(errors.Is(err, test.other), Equals, false, Commentf("%s, code %d", path, test.code))
+		}
+	}
+}
+
+func (s *MySuite) TestErrorsWithStatusOK(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	body := `{"shows":[],"show":null,"errors":[{"code":2001,"text":"Token invalide."}]}`
+	f.handleJSON("GET", "/shows/search", 200, body)
+	f.handleJSON("GET", "/episodes/list", 200, body)
+	f.handleJSON("GET", "/shows/display", 200, body)
+	bs := f.client(c)
+
+	_, err := bs.ShowsSearch(ctx, "breaking bad", "", false)
+	c.Assert(errors.Is(err, ErrTokenInvalid), Equals, true)
+	c.Assert(err, Not(Equals), ErrNoShowsFound)
+
+	_, err = bs.EpisodesList(ctx, 0, 0, "", 0, 0, -1, false, false)
+	c.Assert(errors.Is(err, ErrTokenInvalid), Equals, true)
+
+	_, err = bs.ShowDisplay(ctx, 481, 0, "")
+	c.Assert(errors.Is(err, ErrTokenInvalid), Equals, true)
+
+	// an empty errors array is not an error
+	f.handleJSON("GET", "/shows/search", 200, `{"shows":[],"errors":[]}`)
+	_, err = bs.ShowsSearch(ctx, "breaking bad", "", false)
+	c.Assert(err, Equals, ErrNoShowsFound)
+}
+
+func (s *MySuite) TestAPIVersion(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/api/shows/search", 200, `{"shows":[{"id":1}],"errors":[]}`)
+	bs, err := NewClient("key", WithBaseURL(f.URL+"/api"))
+	c.Assert(err, IsNil)
+	c.Assert(bs.endpointVersions, HasLen, 0)
+	bs.endpointVersions = map[string]string{"/shows/search": "3.0", "/search": "4.0"}
+
+	bs.ShowsSearch(ctx, "breaking bad", "", false)
+	bs.ShowDisplay(ctx, 481, 0, "")
+	c.Assert(f.callsTo("/api/shows/search")[0].Header.Get("X-BetaSeries-Version"), Equals, "3.0")
+	c.Assert(f.callsTo("/api/shows/display")[0].Header.Get("X-BetaSeries-Version"), Equals, bsVersion)
+
+	bs.SetAPIVersion("3.1")
+	bs.ShowsSearch(ctx, "breaking bad", "", false)
+	bs.ShowDisplay(ctx, 481, 0, "")
+	c.Assert(f.callsTo("/api/shows/search")[1].Header.Get("X-BetaSeries-Version"), Equals, "3.1")
+	c.Assert(f.callsTo("/api/shows/display")[1].Header.Get("X-BetaSeries-Version"), Equals, "3.1")
+
+	// the responses flagging the version as deprecated are reported
+	var deprecations []string
+	bs.OnDeprecated(func(endpoint, version string) {
+		deprecations = append(deprecations, endpoint+" "+version)
+	})
+	_, err = bs.ShowsSearch(ctx, "breaking bad", "", false)
+	c.Assert(err, IsNil)
+	c.Assert(deprecations, HasLen, 0)
+	f.handle("GET", "/api/shows/search", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Warning", `299 - "API version 3.1 is deprecated"`)
+		writeFakeJSON(w, 200, `{"shows":[{"id":1}],"errors":[]}`)
+	})
+	f.handle("GET", "/api/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		writeFakeJSON(w, 404, `{"errors":[{"code":4001,"text":"Série introuvable."}]}`)
+	})
+	_, err = bs.ShowsSearch(ctx, "breaking bad", "", false)
+	c.Assert(err, IsNil)
+	bs.ShowDisplay(ctx, 481, 0, "")
+	c.Assert(deprecations, DeepEquals, []string{"/shows/search 3.1", "/shows/display 3.1"})
+}
+
+func (s *MySuite) TestLocale(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("POST", "/members/auth", 200, `{"user":{"id":1,"login":"me"},"token":"t","errors":[]}`)
+	f.handleJSON("GET", "/shows/display", 200, `{"show":{"id":1},"errors":[]}`)
+
+	_, err := NewClient("key", WithBaseURL(f.URL), WithLocale("klingon"))
+	c.Assert(err, Equals, errInvalidLocale)
+	c.Assert(errors.Is(err, ErrInvalidInput), Equals, true)
+
+	// the locale applies to the authentication
+	bs, err := NewClient("key", WithBaseURL(f.URL), WithLocale("en"), WithCredentials("me", "password"))
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/members/auth")[0].Header.Get("X-BetaSeries-Locale"), Equals, "en")
+	c.Assert(f.callsTo("/members/auth")[0].Header.Get("Accept-Language"), Equals, "en")
+
+	for _, test := range []struct {
+		locale, header string
+		err            error
+	}{
+		{"de", "de", nil},
+		{"en-US", "de", errInvalidLocale},
+		{"EN", "de", errInvalidLocale},
+		{"", "", nil},
+		{"pt", "pt", nil},
+	} {
+		c.Assert(bs.SetLocale(test.locale), Equals, test.err)
+		_, err = bs.ShowDisplay(ctx, 1, 0, "")
+		c.Assert(err, IsNil)
+		calls := f.callsTo("/shows/display")
+		c.Assert(calls[len(calls)-1].Header.Get("X-BetaSeries-Locale"), Equals, test.header, Commentf(test.locale))
+	}
+}
+
+func (s *MySuite) TestVersionLess(c *C) {
+	c.Assert(versionLess("2.4", "3.0"), Equals, true)
+	c.Assert(versionLess("2.4", "2.10"), Equals, true)
+	c.Assert(versionLess("3.0", "2.4"), Equals, false)
+	c.Assert(versionLess("2.4", "2.4"), Equals, false)
+	c.Assert(versionLess("3", "3.0"), Equals, false)
+}
+
+// countingTransport counts the response bodies which have not been closed.
+type countingTransport struct {
+	mu   sync.Mutex
+	open int
+}
+
+type countedBody struct {
+	io.ReadCloser
+	t      *countingTransport
+	closed bool
+}
+
+func (b *countedBody) Close() error {
+	b.t.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		b.t.open--
+	}
+	b.t.mu.Unlock()
+	return b.ReadCloser.Close()
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.open++
+	t.mu.Unlock()
+	resp.Body = &countedBody{ReadCloser: resp.Body, t: t}
+	return resp, nil
+}
+
+func (s *MySuite) TestBodiesClosed(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/search", 200, `{"shows":[{"id":1}],"errors":[]}`)
+	f.handleJSON("GET", "/shows/display", 400, `{"errors":[{"code":4001,"text":"Aucune série trouvée."}]}`)
+	f.handleJSON("GET", "/shows/episodes", 200, `{"episodes":[],"errors":[{"code":2001,"text":"Token invalide."}]}`)
+	f.handleJSON("GET", "/shows/characters", 200, `{"characters":`)
+	f.handleJSON("GET", "/shows/videos", 502, `<html>Bad Gateway</html>`)
+	f.handleJSON("GET", "/pictures/shows", 200, strings.Repeat("x", 1024))
+	bs := f.client(c)
+	transport := &countingTransport{}
+	bs.httpClient = &http.Client{Transport: transport}
+
+	_, err := bs.ShowsSearch(ctx, "breaking bad", "", false)
+	c.Assert(err, IsNil)
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, NotNil)
+	_, err = bs.ShowsEpisodes(ctx, 1, 0, 0, 0, false)
+	c.Assert(err, NotNil)
+	_, err = bs.ShowsCharacters(ctx, 1, 0)
+	c.Assert(err, NotNil)
+	_, err = bs.ShowsVideos(ctx, 1, 0)
+	c.Assert(err, NotNil)
+	picture, err := bs.PicturesShows(ctx, 1, 0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(picture, HasLen, 1024)
+
+	c.Assert(f.calls, HasLen, 6)
+	c.Assert(transport.open, Equals, 0)
+}
+
+func (s *MySuite) TestDecodeLargeNumbers(c *C) {
+	// above 2^53, the first integer a float64 cannot represent
+	const id = "9007199254740993"
+	bs := &BetaSeries{}
+	var data struct {
+		Show   map[string]interface{} `json:"show"`
+		Errors []interface{}          `json:"errors"`
+	}
+	c.Assert(bs.decode(&data, []byte(`{"show":{"thetvdb_id":`+id+`},"errors":[]}`), "", ""), IsNil)
+	c.Assert(data.Show["thetvdb_id"], Equals, json.Number(id))
+
+	c.Assert(decodeJSON([]byte(`{"errors":[{"code":4001,"id":`+id+`}]}`), &data), IsNil)
+	c.Assert(data.Errors[0].(map[string]interface{})["id"], Equals, json.Number(id))
+	c.Assert(decodeJSON([]byte(`{} {}`), &data), Equals, errTrailingData)
+
+	bs.SetRawCapture(true)
+	show := &showItem{}
+	c.Assert(bs.decode(show, []byte(`{"show":{"id":1,"thetvdb_id":`+id+`}}`), "", ""), IsNil)
+	c.Assert(string(show.Show.RawJSON()), Equals, `{"id":1,"thetvdb_id":`+id+`}`)
+
+	var generic interface{}
+	c.Assert(decodeJSON([]byte(`{"show":{"thetvdb_id":`+id+`,"followers":12}}`), &generic), IsNil)
+	out, err := json.Marshal(sanitize(generic))
+	c.Assert(err, IsNil)
+	c.Assert(string(out), Equals, `{"show":{"followers":0,"thetvdb_id":`+id+`}}`)
+}
+
+func (s *MySuite) TestBodyPrefix(c *C) {
+	fixture, err := ioutil.ReadFile("testdata/recorded/shows_display__id-1161.json")
+	c.Assert(err, IsNil)
+	f := newFakeServer()
+	defer f.Close()
+	bs := f.client(c)
+	bs.SetRawCapture(true)
+	for _, prefix := range []string{"", "\xef\xbb\xbf", "\r\n  \t", "\xef\xbb\xbf\n\n"} {
+		f.handleJSON("GET", "/shows/display", 200, prefix+string(fixture))
+		show, err := bs.ShowDisplay(ctx, 1161, 0, "")
+		c.Assert(err, IsNil, Commentf("%q", prefix))
+		c.Assert(show.Title, Equals, "Breaking Bad")
+		c.Assert(json.Valid(show.RawJSON()), Equals, true)
+
+		f.handleJSON("GET", "/shows/display", 400, prefix+`{"errors":[{"code":4001,"text":"Série introuvable."}]}`)
+		_, err = bs.ShowDisplay(ctx, 1161, 0, "")
+		c.Assert(errors.Is(err, ErrNotFound), Equals, true, Commentf("%q", prefix))
+		var apiErr *APIError
+		c.Assert(errors.As(err, &apiErr), Equals, true)
+	}
+
+	body := []byte(`{"show":null}`)
+	c.Assert(trimBody(body), DeepEquals, body)
+	c.Assert(trimBody([]byte("\xef\xbb\xbf")), HasLen, 0)
+	c.Assert(trimBody(nil), IsNil)
+}
+
+// endpointCases calls every endpoint wrapper once, along with the request
+// it must send.
+var endpointCases = []struct {
+	call   func(bs *BetaSeries)
+	method string
+	path   string
+	query  string
+}{
+	{func(bs *BetaSeries) { bs.EpisodeScraper(ctx, "breaking.bad.s01e01.mkv") }, "GET", "/episodes/scraper", "file=breaking.bad.s01e01.mkv"},
+	{func(bs *BetaSeries) { bs.EpisodeLatest(ctx, 1, 0) }, "GET", "/episodes/latest", "id=1"},
+	{func(bs *BetaSeries) { bs.EpisodeDisplay(ctx, 1, 0, true) }, "GET", "/episodes/display", "id=1&subtitles=true"},
+	{func(bs *BetaSeries) { bs.EpisodeNext(ctx, 1, 0) }, "GET", "/episodes/next", "id=1"},
+	{func(bs *BetaSeries) { bs.EpisodeSearch(ctx, 1, false, "S01E01") }, "GET", "/episodes/search", "number=S01E01&show_id=1"},
+	{func(bs *BetaSeries) { bs.EpisodeDownloaded(ctx, 1, 0) }, "POST", "/episodes/downloaded", "id=1"},
+	{func(bs *BetaSeries) { bs.EpisodeNotDownloaded(ctx, 1, 0) }, "DELETE", "/episodes/downloaded", "id=1"},
+	{func(bs *BetaSeries) { bs.EpisodeWatched(ctx, 1, 0, 4, true, false) }, "POST", "/episodes/watched", "bulk=true&id=1&note=4"},
+	{func(bs *BetaSeries) { bs.EpisodeNotWatched(ctx, 1, 0) }, "DELETE", "/episodes/watched", "id=1"},
+	{func(bs *BetaSeries) { bs.EpisodeNote(ctx, 1, 0, 4) }, "POST", "/episodes/note", "id=1&note=4"},
+	{func(bs *BetaSeries) { bs.EpisodeNoteRemove(ctx, 1, 0) }, "DELETE", "/episodes/note", "id=1"},
+	{func(bs *BetaSeries) { bs.EpisodesList(ctx, 1, 0, "", 2, 3, 1, true, true) }, "GET", "/episodes/list", "limit=3&released=1&showId=1&specials=true&subtitles=true&userId=2"},
+	{func(bs *BetaSeries) { bs.FriendsList(ctx, 1, true) }, "GET", "/friends/list", "blocked=true&id=1"},
+	{func(bs *BetaSeries) { bs.FriendsRequests(ctx, true) }, "GET", "/friends/requests", "received=true"},
+	{func(bs *BetaSeries) { bs.FriendsFriend(ctx, 1) }, "POST", "/friends/friend", "id=1"},
+	{func(bs *BetaSeries) { bs.FriendsNotFriend(ctx, 1) }, "DELETE", "/friends/friend", "id=1"},
+	{func(bs *BetaSeries) { bs.FriendsBlock(ctx, 1) }, "POST", "/friends/block", "id=1"},
+	{func(bs *BetaSeries) { bs.FriendsUnblock(ctx, 1) }, "DELETE", "/friends/block", "id=1"},
+	{func(bs *BetaSeries) { bs.MembersSearch(ctx, "dev%", 10) }, "GET", "/members/search", "limit=10&login=dev%25"},
+	{func(bs *BetaSeries) { bs.MembersInfos(ctx, 1, false, "shows") }, "GET", "/members/infos", "id=1&only=shows"},
+	{func(bs *BetaSeries) { bs.memberNotificationsCount(ctx) }, "GET", "/members/notifications", "auto_delete=false&summary=true"},
+	{func(bs *BetaSeries) { bs.NewsLast(ctx, 5, true) }, "GET", "/news/last", "number=5&tailored=true"},
+	{func(bs *BetaSeries) { bs.PicturesShows(ctx, 1, 100, 200) }, "GET", "/pictures/shows", "height=200&id=1&width=100"},
+	{func(bs *BetaSeries) { bs.PlanningGeneral(ctx, "2017-01-01", "premiere", 1, 2) }, "GET", "/planning/general", "after=2&before=1&date=2017-01-01&type=premiere"},
+	{func(bs *BetaSeries) { bs.PlanningIncoming(ctx) }, "GET", "/planning/incoming", ""},
+	{func(bs *BetaSeries) { bs.PlanningMember(ctx, 1, true, "2017-01") }, "GET", "/planning/member", "id=1&month=2017-01&unseen=true"},
+	{func(bs *BetaSeries) { bs.ShowsSearch(ctx, "Breaking Bad", "title", true) }, "GET", "/shows/search", "nbpp=100&order=title&summary=true&title=breaking+bad"},
+	{func(bs *BetaSeries) { bs.ShowsRandom(ctx, 5, true) }, "GET", "/shows/random", "nb=5&summary=true"},
+	{func(bs *BetaSeries) { bs.ShowsFavorites(ctx, 1) }, "GET", "/shows/favorites", "id=1"},
+	{func(bs *BetaSeries) { bs.ShowFavorite(ctx, 1) }, "POST", "/shows/favorite", "id=1"},
+	{func(bs *BetaSeries) { bs.ShowFavoriteRemove(ctx, 1) }, "DELETE", "/shows/favorite", "id=1"},
+	{func(bs *BetaSeries) { bs.ShowsSimilars(ctx, 1, 0, true) }, "GET", "/shows/similars", "details=true&id=1"},
+	{func(bs *BetaSeries) { bs.ShowsCharacters(ctx, 1, 0) }, "GET", "/shows/characters", "id=1"},
+	{func(bs *BetaSeries) { bs.ShowsList(ctx, "", "b", "popularity", 10, 20) }, "GET", "/shows/list", "limit=20&order=popularity&start=10&starting=b"},
+	{func(bs *BetaSeries) { bs.ShowDisplay(ctx, 0, 0, "tt0903747") }, "GET", "/shows/display", "imdb_id=tt0903747"},
+	{func(bs *BetaSeries) { bs.ShowAdd(ctx, 1, 0, "", 0) }, "POST", "/shows/show", "id=1"},
+	{func(bs *BetaSeries) { bs.ShowRemove(ctx, 1, 0, "") }, "DELETE", "/shows/show", "id=1"},
+	{func(bs *BetaSeries) { bs.ShowArchive(ctx, 1, 0) }, "POST", "/shows/archive", "id=1"},
+	{func(bs *BetaSeries) { bs.ShowNotArchive(ctx, 1, 0) }, "DELETE", "/shows/archive", "id=1"},
+	{func(bs *BetaSeries) { bs.ShowsVideos(ctx, 1, 0) }, "GET", "/shows/videos", "id=1"},
+	{func(bs *BetaSeries) { bs.ShowsEpisodes(ctx, 1, 0, 2, 3, true) }, "GET", "/shows/episodes", "episode=3&id=1&season=2&subtitles=true"},
+	{func(bs *BetaSeries) { bs.ShowNote(ctx, 1, 0, 4) }, "POST", "/shows/note", "id=1&note=4"},
+	{func(bs *BetaSeries) { bs.ShowNoteRemove(ctx, 1, 0) }, "DELETE", "/shows/note", "id=1"},
+	{func(bs *BetaSeries) { bs.SubtitlesEpisode(ctx, 1, "vo") }, "GET", "/subtitles/episode", "id=1&language=vo"},
+	{func(bs *BetaSeries) { bs.SubtitlesShow(ctx, 1, "vf") }, "GET", "/subtitles/show", "id=1&language=vf"},
+	{func(bs *BetaSeries) { bs.SubtitlesLast(ctx, 5, "all") }, "GET", "/subtitles/last", "language=all&number=5"},
+	{func(bs *BetaSeries) { bs.retrieveToken(ctx, "Dev050", "developer") }, "POST", "/members/auth", "login=Dev050&password=5e8edd851d2fdfbd7415232c67367cc3"},
+}
+
+func (s *MySuite) TestEndpoints(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	bs := f.client(c)
+	var err error
+	// the endpoints are appended to the path of the base url
+	bs.baseURL, err = parseBaseURL(f.URL + "/api/")
+	c.Assert(err, IsNil)
+
+	for _, endpoint := range endpointCases {
+		endpoint.call(bs)
+		calls := f.callsTo("/api" + endpoint.path)
+		c.Assert(calls, Not(HasLen), 0, Commentf("%s %s", endpoint.method, endpoint.path))
+		call := calls[len(calls)-1]
+		c.Assert(call.Method, Equals, endpoint.method)
+		// the parameters of the mutations are sent in a form body
+		query, body, contentType := endpoint.query, "", ""
+		if endpoint.method != "GET" && endpoint.query != "" {
+			query, body, contentType = "", endpoint.query, "application/x-www-form-urlencoded"
+		}
+		comment := Commentf("%s %s", endpoint.method, endpoint.path)
+		c.Assert(call.RawQuery, Equals, query, comment)
+		c.Assert(call.Body, Equals, body, comment)
+		c.Assert(call.Header.Get("Content-Type"), Equals, contentType, comment)
+	}
+}
@@ -0,0 +1,250 @@
+// Package bstest provides a fake BetaSeries API server, so that the
+// applications built on bsclient can be tested without the network.
+//
+// The server keeps shows, episodes and members seeded by the test, answers
+// the main show, episode and member endpoints from them and records the
+// calls it receives. A client is pointed at it with bsclient.WithBaseURL:
+//
+//	server := bstest.NewServer()
+//	defer server.Close()
+//	server.AddShow(bsclient.Show{ID: 1161, Title: "Breaking Bad"})
+//	bs, err := bsclient.NewClient("key", bsclient.WithBaseURL(server.URL))
+//
+// The other endpoints answer with an API error of code
+// bsclient.CodeNotFound.
+package bstest
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	"github.com/dns-gh/bs-client/bsclient"
+)
+
+// Call is a request received by the server.
+type Call struct {
+	Method string
+	Path   string
+	// Params holds the parameters of the query and of the form body
+	Params url.Values
+}
+
+// member is a member seeded in the server, with the state of its account.
+type member struct {
+	info bsclient.Member
+	// hash is the MD5 hash of the password, as sent by the client
+	hash       string
+	shows      map[int]bool
+	archived   map[int]bool
+	seen       map[int]bool
+	downloaded map[int]bool
+}
+
+// failure is an error injected with Fail.
+type failure struct {
+	status int
+	err    bsclient.APIError
+}
+
+// Server is a fake BetaSeries API server. Its methods are safe for
+// concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	shows    []bsclient.Show
+	episodes []bsclient.Episode
+	members  []*member
+	tokens   map[string]*member
+	failures map[string]failure
+	calls    []Call
+}
+
+// handler answers a request, 'm' being the authenticated member if any.
+type handler func(s *Server, m *member, params url.Values) (int, interface{})
+
+// handlers are the endpoints implemented by the server, by method and path.
+var handlers = map[string]handler{
+	"POST /members/auth":          (*Server).auth,
+	"POST /members/destroy":       memberOnly((*Server).destroy),
+	"GET /members/infos":          (*Server).memberInfos,
+	"GET /shows/display":          (*Server).showDisplay,
+	"GET /shows/search":           (*Server).showsSearch,
+	"GET /shows/list":             (*Server).showsList,
+	"GET /shows/episodes":         (*Server).showEpisodes,
+	"POST /shows/show":            memberOnly((*Server).showAdd),
+	"DELETE /shows/show":          memberOnly((*Server).showRemove),
+	"POST /shows/archive":         memberOnly(showArchive(true)),
+	"DELETE /shows/archive":       memberOnly(showArchive(false)),
+	"GET /episodes/display":       (*Server).episodeDisplay,
+	"GET /episodes/list":          memberOnly((*Server).episodesList),
+	"POST /episodes/downloaded":   memberOnly(episodeDownloaded(true)),
+	"DELETE /episodes/downloaded": memberOnly(episodeDownloaded(false)),
+	"POST /episodes/watched":      memberOnly(episodeWatched(true)),
+	"DELETE /episodes/watched":    memberOnly(episodeWatched(false)),
+}
+
+// NewServer starts a fake server, to be closed with Close. The server
+// accepts any API key.
+func NewServer() *Server {
+	s := &Server{
+		tokens:   map[string]*member{},
+		failures: map[string]failure{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// AddShow seeds the show 'show', replacing the show of the same ID.
+func (s *Server) AddShow(show bsclient.Show) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.shows {
+		if s.shows[i].ID == show.ID {
+			s.shows[i] = show
+			return
+		}
+	}
+	s.shows = append(s.shows, show)
+}
+
+// AddEpisode seeds the episode 'episode' of the show episode.Show.ID,
+// replacing the episode of the same ID.
+func (s *Server) AddEpisode(episode bsclient.Episode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.episodes {
+		if s.episodes[i].ID == episode.ID {
+			s.episodes[i] = episode
+			return
+		}
+	}
+	s.episodes = append(s.episodes, episode)
+}
+
+// AddMember seeds a member logging in with 'login' and 'password', and
+// returns its ID.
+func (s *Server) AddMember(login, password string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := &member{
+		info:       bsclient.Member{ID: len(s.members) + 1, Login: login},
+		hash:       fmt.Sprintf("%x", md5.Sum([]byte(password))),
+		shows:      map[int]bool{},
+		archived:   map[int]bool{},
+		seen:       map[int]bool{},
+		downloaded: map[int]bool{},
+	}
+	s.members = append(s.members, m)
+	return m.info.ID
+}
+
+// Token returns a new token of the member 'login', for the clients created
+// with bsclient.NewClientFromToken. It panics if the member is unknown.
+func (s *Server) Token(login string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.members {
+		if m.info.Login == login {
+			return s.issueToken(m)
+		}
+	}
+	panic("bstest: unknown member " + login)
+}
+
+// AddToAccount adds the shows 'ids' to the account of the member 'login',
+// as ShowAdd does. It panics if the member is unknown.
+func (s *Server) AddToAccount(login string, ids ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.members {
+		if m.info.Login == login {
+			for _, id := range ids {
+				m.shows[id] = true
+			}
+			return
+		}
+	}
+	panic("bstest: unknown member " + login)
+}
+
+// Fail makes the server answer the requests of 'method' to 'path' with the
+// HTTP status 'status' and an API error of code 'code', until Restore is
+// called. It works for any endpoint, implemented by the server or not.
+func (s *Server) Fail(method, path string, status, code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures[method+" "+path] = failure{status, bsclient.APIError{
+		Code: code,
+		Text: fmt.Sprintf("bstest: injected error %d (%s)", code, bsclient.CodeName(code)),
+	}}
+}
+
+// Restore makes the server answer the requests of 'method' to 'path'
+// normally again after Fail.
+func (s *Server) Restore(method, path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.failures, method+" "+path)
+}
+
+// Calls returns the requests received by the server, in order.
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Call(nil), s.calls...)
+}
+
+// CallsTo returns the requests received by the server to 'path', in order.
+func (s *Server) CallsTo(path string) []Call {
+	var calls []Call
+	for _, call := range s.Calls() {
+		if call.Path == path {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	if r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+		body, _ := ioutil.ReadAll(r.Body)
+		form, _ := url.ParseQuery(string(body))
+		for key, values := range form {
+			params[key] = append(params[key], values...)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, Call{r.Method, r.URL.Path, params})
+
+	route := r.Method + " " + r.URL.Path
+	if f, ok := s.failures[route]; ok {
+		writeJSON(w, f.status, errorsBody(f.err))
+		return
+	}
+	if r.Header.Get("X-BetaSeries-Key") == "" {
+		writeJSON(w, http.StatusBadRequest, apiError(bsclient.CodeInvalidAPIKey, "missing API key"))
+		return
+	}
+	var m *member
+	if token := r.Header.Get("X-BetaSeries-Token"); token != "" {
+		if m = s.tokens[token]; m == nil {
+			writeJSON(w, http.StatusBadRequest, apiError(bsclient.CodeInvalidToken, "invalid token"))
+			return
+		}
+	}
+	h, ok := handlers[route]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, apiError(bsclient.CodeNotFound, "bstest: unhandled "+route))
+		return
+	}
+	status, body := h(s, m, params)
+	writeJSON(w, status, body)
+}
@@ -0,0 +1,160 @@
+package bstest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/dns-gh/bs-client/bsclient"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+var ctx = context.Background()
+
+// seed returns a server holding two shows, the episodes of the first one
+// and the member "me".
+func seed() *Server {
+	s := NewServer()
+	s.AddShow(bsclient.Show{ID: 1161, ThetvdbID: 81189, Title: "Breaking Bad"})
+	s.AddShow(bsclient.Show{ID: 481, Title: "The Wire"})
+	for i, number := range [][2]int{{1, 2}, {1, 1}, {2, 1}} {
+		episode := bsclient.Episode{ID: 10 + i, Season: number[0], Episode: number[1]}
+		episode.Show.ID = 1161
+		s.AddEpisode(episode)
+	}
+	s.AddMember("me", "secret")
+	return s
+}
+
+func (s *MySuite) TestShows(c *C) {
+	server := seed()
+	defer server.Close()
+	bs, err := bsclient.NewClient("key", bsclient.WithBaseURL(server.URL))
+	c.Assert(err, IsNil)
+
+	shows, err := bs.ShowsSearch(ctx, "BREAKING", "", false)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 1)
+	c.Assert(shows[0].Title, Equals, "Breaking Bad")
+	show, err := bs.ShowDisplay(ctx, 0, 81189, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.ID, Equals, 1161)
+	shows, err = bs.ShowsList(ctx, "", "", "", 1, 10)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 1)
+	c.Assert(shows[0].ID, Equals, 481)
+	episodes, err := bs.ShowsEpisodes(ctx, 1161, 0, 1, 0, false)
+	c.Assert(err, IsNil)
+	c.Assert(episodes, HasLen, 2)
+	c.Assert(episodes[0].ID, Equals, 11)
+
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(errors.Is(err, bsclient.ErrNotFound), Equals, true)
+
+	calls := server.CallsTo("/shows/search")
+	c.Assert(calls, HasLen, 1)
+	c.Assert(calls[0].Method, Equals, "GET")
+	c.Assert(calls[0].Params.Get("title"), Equals, "breaking")
+	c.Assert(server.Calls(), HasLen, 5)
+}
+
+func (s *MySuite) TestAccount(c *C) {
+	server := seed()
+	defer server.Close()
+
+	// member endpoints require a token
+	anonymous, err := bsclient.NewClient("key", bsclient.WithBaseURL(server.URL))
+	c.Assert(err, IsNil)
+	_, err = anonymous.EpisodeDownloaded(ctx, 10, 0)
+	c.Assert(err, Equals, bsclient.ErrNotAuthenticated)
+	unknown, err := bsclient.NewClientFromToken("key", "unknown", bsclient.WithBaseURL(server.URL))
+	c.Assert(err, IsNil)
+	_, err = unknown.EpisodeDownloaded(ctx, 10, 0)
+	c.Assert(errors.Is(err, bsclient.ErrTokenInvalid), Equals, true)
+	_, err = bsclient.NewClient("key", bsclient.WithBaseURL(server.URL), bsclient.WithCredentials("me", "wrong"))
+	c.Assert(err, NotNil)
+
+	bs, err := bsclient.NewClient("key", bsclient.WithBaseURL(server.URL), bsclient.WithCredentials("me", "secret"))
+	c.Assert(err, IsNil)
+	c.Assert(bs.Token(), Not(Equals), "")
+	auth := server.CallsTo("/members/auth")
+	c.Assert(auth, HasLen, 2)
+	c.Assert(auth[1].Method, Equals, "POST")
+	c.Assert(auth[1].Params.Get("login"), Equals, "me")
+
+	show, err := bs.ShowAdd(ctx, 1161, 0, "", 0)
+	c.Assert(err, IsNil)
+	c.Assert(show.InAccount, Equals, true)
+	_, err = bs.ShowAdd(ctx, 1161, 0, "", 0)
+	c.Assert(errors.Is(err, bsclient.ErrInvalidInput), Equals, true)
+	episode, err := bs.EpisodeDownloaded(ctx, 10, 0)
+	c.Assert(err, IsNil)
+	c.Assert(episode.User.Downloaded, Equals, true)
+	episode, err = bs.EpisodeWatched(ctx, 10, 0, 0, true, false)
+	c.Assert(err, IsNil)
+	c.Assert(episode.User.Seen, Equals, true)
+	c.Assert(server.CallsTo("/episodes/watched")[0].Params.Get("id"), Equals, "10")
+
+	// the previous episode was marked too
+	shows, err := bs.EpisodesList(ctx, 0, 0, "", 0, 0, -1, false, false)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 1)
+	c.Assert(shows[0].Unseen, HasLen, 1)
+	c.Assert(shows[0].Unseen[0].ID, Equals, 12)
+	member, err := bs.MembersInfos(ctx, 0, false, "shows")
+	c.Assert(err, IsNil)
+	c.Assert(member.Login, Equals, "me")
+	c.Assert(member.Shows, HasLen, 1)
+
+	_, err = bs.ShowRemove(ctx, 1161, 0, "")
+	c.Assert(err, IsNil)
+	member, err = bs.MembersInfos(ctx, 0, false, "shows")
+	c.Assert(err, IsNil)
+	c.Assert(member.Shows, HasLen, 0)
+
+	// the token can be issued without logging in
+	server.AddToAccount("me", 481)
+	bs, err = bsclient.NewClientFromToken("key", server.Token("me"), bsclient.WithBaseURL(server.URL))
+	c.Assert(err, IsNil)
+	member, err = bs.MembersInfos(ctx, 0, false, "shows")
+	c.Assert(err, IsNil)
+	c.Assert(member.Shows[0].ID, Equals, 481)
+
+	// logging out destroys the token
+	token := bs.Token()
+	c.Assert(bs.Logout(ctx), IsNil)
+	bs, err = bsclient.NewClientFromToken("key", token, bsclient.WithBaseURL(server.URL))
+	c.Assert(err, IsNil)
+	_, err = bs.MembersInfos(ctx, 0, false, "shows")
+	c.Assert(errors.Is(err, bsclient.ErrTokenInvalid), Equals, true)
+}
+
+func (s *MySuite) TestFail(c *C) {
+	server := seed()
+	defer server.Close()
+	bs, err := bsclient.NewClient("key", bsclient.WithBaseURL(server.URL))
+	c.Assert(err, IsNil)
+
+	server.Fail("GET", "/shows/display", http.StatusBadRequest, bsclient.CodePremiumRequired)
+	_, err = bs.ShowDisplay(ctx, 1161, 0, "")
+	c.Assert(errors.Is(err, bsclient.ErrPremiumRequired), Equals, true)
+	var apiErr *bsclient.APIError
+	c.Assert(errors.As(err, &apiErr), Equals, true)
+	c.Assert(apiErr.Code, Equals, bsclient.CodePremiumRequired)
+
+	server.Restore("GET", "/shows/display")
+	_, err = bs.ShowDisplay(ctx, 1161, 0, "")
+	c.Assert(err, IsNil)
+
+	// endpoints the server does not implement can fail too
+	server.Fail("GET", "/news/last", http.StatusBadRequest, bsclient.CodeInvalidAPIKey)
+	_, err = bs.NewsLast(ctx, 1, false)
+	c.Assert(errors.Is(err, bsclient.ErrInvalidAPIKey), Equals, true)
+}
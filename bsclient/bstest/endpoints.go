@@ -0,0 +1,333 @@
+package bstest
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dns-gh/bs-client/bsclient"
+)
+
+// body is the JSON body of a response
+type body map[string]interface{}
+
+// errorsBody returns a body holding the API errors 'errs'.
+func errorsBody(errs ...bsclient.APIError) body {
+	return body{"errors": errs}
+}
+
+// apiError returns a body holding an API error.
+func apiError(code int, text string) body {
+	return errorsBody(bsclient.APIError{Code: code, Text: text})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// ok returns a successful body holding 'value' under 'key'.
+func ok(key string, value interface{}) (int, interface{}) {
+	return http.StatusOK, body{key: value, "errors": []bsclient.APIError{}}
+}
+
+// memberOnly returns a handler answering with the invalid token error to
+// the requests which are not authenticated.
+func memberOnly(h handler) handler {
+	return func(s *Server, m *member, params url.Values) (int, interface{}) {
+		if m == nil {
+			return http.StatusBadRequest, apiError(bsclient.CodeInvalidToken, "authentication required")
+		}
+		return h(s, m, params)
+	}
+}
+
+// issueToken returns a new token of the member 'm'.
+func (s *Server) issueToken(m *member) string {
+	token := fmt.Sprintf("%x", md5.Sum([]byte(fmt.Sprintf("%s-%d", m.info.Login, len(s.tokens)))))
+	s.tokens[token] = m
+	return token
+}
+
+func (s *Server) auth(_ *member, params url.Values) (int, interface{}) {
+	for _, m := range s.members {
+		if m.info.Login == params.Get("login") && m.hash == params.Get("password") {
+			return http.StatusOK, body{
+				"user":   body{"id": m.info.ID, "login": m.info.Login},
+				"token":  s.issueToken(m),
+				"hash":   m.hash,
+				"errors": []bsclient.APIError{},
+			}
+		}
+	}
+	return http.StatusBadRequest, apiError(bsclient.CodeInvalidToken, "invalid login or password")
+}
+
+// destroy logs the member out, destroying all its tokens.
+func (s *Server) destroy(m *member, _ url.Values) (int, interface{}) {
+	for token, owner := range s.tokens {
+		if owner == m {
+			delete(s.tokens, token)
+		}
+	}
+	return http.StatusOK, errorsBody()
+}
+
+func (s *Server) memberInfos(m *member, params url.Values) (int, interface{}) {
+	if id := atoi(params.Get("id")); id > 0 {
+		if id > len(s.members) {
+			return http.StatusNotFound, apiError(bsclient.CodeNotFound, "member not found")
+		}
+		m = s.members[id-1]
+	} else if m == nil {
+		return http.StatusBadRequest, apiError(bsclient.CodeInvalidToken, "authentication required")
+	}
+	info := m.info
+	if params.Get("summary") != "true" {
+		info.Shows = []bsclient.Show{}
+		for _, show := range s.shows {
+			if m.shows[show.ID] {
+				info.Shows = append(info.Shows, s.memberShow(m, show))
+			}
+		}
+	}
+	return ok("member", info)
+}
+
+// memberShow returns 'show' as seen by the member 'm', if any.
+func (s *Server) memberShow(m *member, show bsclient.Show) bsclient.Show {
+	if m != nil {
+		show.InAccount = m.shows[show.ID]
+		show.User.Archived = m.archived[show.ID]
+	}
+	return show
+}
+
+// memberEpisode returns 'episode' as seen by the member 'm', if any.
+func (s *Server) memberEpisode(m *member, episode bsclient.Episode) bsclient.Episode {
+	if m != nil {
+		episode.User.Seen = m.seen[episode.ID]
+		episode.User.Downloaded = m.downloaded[episode.ID]
+	}
+	return episode
+}
+
+// findShow returns the index of the show of the ids of 'params', -1 if
+// there is none.
+func (s *Server) findShow(params url.Values) int {
+	id, tvdbID, imdbID := atoi(params.Get("id")), atoi(params.Get("thetvdb_id")), params.Get("imdb_id")
+	for i, show := range s.shows {
+		if id > 0 && show.ID == id || tvdbID > 0 && show.ThetvdbID == tvdbID ||
+			imdbID != "" && show.ImdbID == imdbID {
+			return i
+		}
+	}
+	return -1
+}
+
+// findEpisode returns the index of the episode of the ids of 'params', -1
+// if there is none.
+func (s *Server) findEpisode(params url.Values) int {
+	id, tvdbID := atoi(params.Get("id")), atoi(params.Get("thetvdb_id"))
+	for i, episode := range s.episodes {
+		if id > 0 && episode.ID == id || tvdbID > 0 && episode.ThetvdbID == tvdbID {
+			return i
+		}
+	}
+	return -1
+}
+
+func showNotFound() (int, interface{}) {
+	return http.StatusNotFound, apiError(bsclient.CodeShowNotFound, "show not found")
+}
+
+func episodeNotFound() (int, interface{}) {
+	return http.StatusNotFound, apiError(bsclient.CodeEpisodeNotFound, "episode not found")
+}
+
+func (s *Server) showDisplay(m *member, params url.Values) (int, interface{}) {
+	i := s.findShow(params)
+	if i < 0 {
+		return showNotFound()
+	}
+	return ok("show", s.memberShow(m, s.shows[i]))
+}
+
+// showsPage returns the page of the shows 'shows' starting at 'start',
+// 'limit' long if positive.
+func (s *Server) showsPage(m *member, shows []bsclient.Show, start, limit int) (int, interface{}) {
+	page := []bsclient.Show{}
+	for i := start; i < len(shows) && (limit <= 0 || i < start+limit); i++ {
+		page = append(page, s.memberShow(m, shows[i]))
+	}
+	return ok("shows", page)
+}
+
+func (s *Server) showsSearch(m *member, params url.Values) (int, interface{}) {
+	title := strings.ToLower(params.Get("title"))
+	var found []bsclient.Show
+	for _, show := range s.shows {
+		if strings.Contains(strings.ToLower(show.Title), title) {
+			found = append(found, show)
+		}
+	}
+	nbpp := atoi(params.Get("nbpp"))
+	page := atoi(params.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	return s.showsPage(m, found, (page-1)*nbpp, nbpp)
+}
+
+func (s *Server) showsList(m *member, params url.Values) (int, interface{}) {
+	return s.showsPage(m, s.shows, atoi(params.Get("start")), atoi(params.Get("limit")))
+}
+
+// showEpisodes returns the episodes of the show 'showID', in season and
+// episode order.
+func (s *Server) showEpisodes(m *member, params url.Values) (int, interface{}) {
+	i := s.findShow(params)
+	if i < 0 {
+		return showNotFound()
+	}
+	season, number := atoi(params.Get("season")), atoi(params.Get("episode"))
+	episodes := []bsclient.Episode{}
+	for _, episode := range s.sortedEpisodes(s.shows[i].ID) {
+		if season > 0 && episode.Season != season || number > 0 && episode.Episode != number {
+			continue
+		}
+		episodes = append(episodes, s.memberEpisode(m, episode))
+	}
+	return ok("episodes", episodes)
+}
+
+// sortedEpisodes returns the episodes of the show 'showID', in season and
+// episode order.
+func (s *Server) sortedEpisodes(showID int) []bsclient.Episode {
+	var episodes []bsclient.Episode
+	for _, episode := range s.episodes {
+		if episode.Show.ID == showID {
+			episodes = append(episodes, episode)
+		}
+	}
+	sort.SliceStable(episodes, func(i, j int) bool {
+		if episodes[i].Season != episodes[j].Season {
+			return episodes[i].Season < episodes[j].Season
+		}
+		return episodes[i].Episode < episodes[j].Episode
+	})
+	return episodes
+}
+
+func (s *Server) showAdd(m *member, params url.Values) (int, interface{}) {
+	i := s.findShow(params)
+	if i < 0 {
+		return showNotFound()
+	}
+	if m.shows[s.shows[i].ID] {
+		return http.StatusBadRequest, apiError(bsclient.CodeAlreadyInAccount, "show already in the account")
+	}
+	m.shows[s.shows[i].ID] = true
+	return ok("show", s.memberShow(m, s.shows[i]))
+}
+
+func (s *Server) showRemove(m *member, params url.Values) (int, interface{}) {
+	i := s.findShow(params)
+	if i < 0 {
+		return showNotFound()
+	}
+	delete(m.shows, s.shows[i].ID)
+	delete(m.archived, s.shows[i].ID)
+	return ok("show", s.memberShow(m, s.shows[i]))
+}
+
+// showArchive returns the handler archiving or restoring a show.
+func showArchive(archived bool) handler {
+	return func(s *Server, m *member, params url.Values) (int, interface{}) {
+		i := s.findShow(params)
+		if i < 0 {
+			return showNotFound()
+		}
+		m.archived[s.shows[i].ID] = archived
+		return ok("show", s.memberShow(m, s.shows[i]))
+	}
+}
+
+func (s *Server) episodeDisplay(m *member, params url.Values) (int, interface{}) {
+	i := s.findEpisode(params)
+	if i < 0 {
+		return episodeNotFound()
+	}
+	return ok("episode", s.memberEpisode(m, s.episodes[i]))
+}
+
+// episodesList returns the shows of the member with their unseen episodes.
+func (s *Server) episodesList(m *member, params url.Values) (int, interface{}) {
+	showID := atoi(params.Get("showId"))
+	limit := atoi(params.Get("limit"))
+	shows := []bsclient.Show{}
+	for _, show := range s.shows {
+		if !m.shows[show.ID] || showID > 0 && show.ID != showID {
+			continue
+		}
+		show = s.memberShow(m, show)
+		show.Unseen = []bsclient.Episode{}
+		for _, episode := range s.sortedEpisodes(show.ID) {
+			if !m.seen[episode.ID] && (limit <= 0 || len(show.Unseen) < limit) {
+				show.Unseen = append(show.Unseen, s.memberEpisode(m, episode))
+			}
+		}
+		show.Remaining = len(show.Unseen)
+		shows = append(shows, show)
+	}
+	return ok("shows", shows)
+}
+
+// episodeDownloaded returns the handler marking an episode as downloaded or
+// not.
+func episodeDownloaded(downloaded bool) handler {
+	return func(s *Server, m *member, params url.Values) (int, interface{}) {
+		i := s.findEpisode(params)
+		if i < 0 {
+			return episodeNotFound()
+		}
+		m.downloaded[s.episodes[i].ID] = downloaded
+		return ok("episode", s.memberEpisode(m, s.episodes[i]))
+	}
+}
+
+// episodeWatched returns the handler marking an episode as watched or not.
+// Unless the bulk parameter is false, the previous episodes of the show are
+// marked as watched too.
+func episodeWatched(seen bool) handler {
+	return func(s *Server, m *member, params url.Values) (int, interface{}) {
+		i := s.findEpisode(params)
+		if i < 0 {
+			return episodeNotFound()
+		}
+		target := s.episodes[i]
+		m.seen[target.ID] = seen
+		if seen && params.Get("bulk") != "false" {
+			for _, episode := range s.sortedEpisodes(target.Show.ID) {
+				if episode.Season > target.Season ||
+					episode.Season == target.Season && episode.Episode >= target.Episode {
+					break
+				}
+				m.seen[episode.ID] = true
+			}
+		}
+		return ok("episode", s.memberEpisode(m, target))
+	}
+}
+
+// atoi converts the integer parameter 's', 0 if unset or invalid.
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,50 @@
+package bstest_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dns-gh/bs-client/bsclient"
+	"github.com/dns-gh/bs-client/bsclient/bstest"
+)
+
+// addShow is the application code under test.
+func addShow(ctx context.Context, bs *bsclient.BetaSeries, title string) (int, error) {
+	shows, err := bs.ShowsSearch(ctx, title, "", true)
+	if err != nil {
+		return 0, err
+	}
+	show, err := bs.ShowAdd(ctx, shows[0].ID, 0, "", 0)
+	if err != nil {
+		return 0, err
+	}
+	return show.ID, nil
+}
+
+func Example() {
+	server := bstest.NewServer()
+	defer server.Close()
+	server.AddShow(bsclient.Show{ID: 1161, Title: "Breaking Bad"})
+	server.AddMember("me", "secret")
+
+	bs, err := bsclient.NewClient("key", bsclient.WithBaseURL(server.URL),
+		bsclient.WithCredentials("me", "secret"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	id, err := addShow(context.Background(), bs, "breaking bad")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("added", id)
+	for _, call := range server.Calls() {
+		fmt.Println(call.Method, call.Path, call.Params.Encode())
+	}
+	// Output:
+	// added 1161
+	// POST /members/auth login=me&password=5ebe2294ecd0e0f08eab7690d2a6ee69
+	// GET /shows/search nbpp=100&order=popularity&summary=true&title=breaking+bad
+	// POST /shows/show id=1161
+}
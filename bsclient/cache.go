@@ -0,0 +1,376 @@
+package bsclient
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// responseCache keeps the bodies of the GET responses holding an ETag or a
+// Last-Modified header, to send conditional requests. The least recently
+// used entries are evicted beyond 'max' entries, or beyond 'maxBytes' bytes
+// of bodies if positive.
+type responseCache struct {
+	mu       sync.Mutex
+	max      int
+	maxBytes int
+	order    *list.List
+	entries  map[string]*list.Element
+	// bodies are the entries by the first byte of their body, which the
+	// requests return as is, see decoded
+	bodies map[*byte]*cacheEntry
+	// bytes is the size of the bodies of the entries
+	bytes     int
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+type cacheEntry struct {
+	key          string
+	etag         string
+	lastModified string
+	body         []byte
+	// decoded is a pointer to the value decoded from the body, never
+	// modified once set
+	decoded reflect.Value
+}
+
+// CacheStats are the counters of the response cache, see CacheStats.
+type CacheStats struct {
+	// Entries is the number of responses in the cache
+	Entries int
+	// Bytes is the size of their bodies
+	Bytes int
+	// Hits is the number of requests answered from the cache, after a 304
+	// Not Modified response
+	Hits int64
+	// Misses is the number of GET requests answered with a full body
+	Misses int64
+	// Evictions is the number of entries evicted to keep the cache within
+	// its bounds
+	Evictions int64
+}
+
+func newResponseCache(max, maxBytes int) *responseCache {
+	return &responseCache{
+		max:      max,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+		bodies:   map[*byte]*cacheEntry{},
+	}
+}
+
+// WithCache enables a cache of the GET responses holding an ETag or a
+// Last-Modified header, of at most 'size' entries: the requests of cached
+// urls are sent with If-None-Match and If-Modified-Since, and the cached
+// body is used when the API answers 304 Not Modified. The cache is disabled
+// by default, and with a size which is not positive.
+// The value decoded from a cached body is kept as well: a hit returns a copy
+// of it without decoding the body again, so that the results of the calls
+// never share data.
+// The entries are kept per token and locale. A successful mutation, such as
+// ShowAdd or EpisodeWatched, removes the entries of the token it may have
+// changed; see ForceRefresh for the changes made elsewhere.
+// See WithCacheMaxBytes to bound the size of the cached bodies and
+// CacheStats for the counters of the cache.
+func WithCache(size int) Option {
+	return func(o *clientOptions) {
+		o.cacheSize = size
+	}
+}
+
+// WithCacheMaxBytes bounds the size of the bodies kept by the cache enabled
+// with WithCache to 'size' bytes, the least recently used entries being
+// evicted beyond it. A body larger than 'size' is not cached. The cache is
+// only bounded by its number of entries by default, and with a size which
+// is not positive.
+func WithCacheMaxBytes(size int) Option {
+	return func(o *clientOptions) {
+		o.cacheMaxBytes = size
+	}
+}
+
+// CacheStats returns the counters of the response cache, see WithCache.
+// They are all zero if the cache is disabled. ResetStats resets the hits,
+// misses and evictions.
+func (bs *BetaSeries) CacheStats() CacheStats {
+	if bs.cache == nil {
+		return CacheStats{}
+	}
+	return bs.cache.stats()
+}
+
+// forceRefreshKey marks the contexts of the requests bypassing the cache
+type forceRefreshKey struct{}
+
+// ForceRefresh returns a copy of 'ctx' whose requests bypass the cache
+// enabled with WithCache: they are sent without If-None-Match nor
+// If-Modified-Since, and the full response replaces the cached one. The
+// client marks the responses changed by its own mutations as stale, see
+// WithCache, but cannot know about those made elsewhere, for instance
+// episodes marked as watched on another device.
+func ForceRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceRefreshKey{}, true)
+}
+
+// forceRefresh reports whether the requests of 'ctx' bypass the cache.
+func forceRefresh(ctx context.Context) bool {
+	force, _ := ctx.Value(forceRefreshKey{}).(bool)
+	return force
+}
+
+// staleAfter holds, by group of endpoints, the groups of the cached
+// responses a successful mutation of the member data makes stale: the shows,
+// episodes, planning and profile of the member change together.
+var staleAfter = map[string][]string{
+	"/shows/":    {"/shows/", "/episodes/", "/planning/", "/members/", "/timeline/"},
+	"/episodes/": {"/shows/", "/episodes/", "/planning/", "/members/", "/timeline/"},
+	"/friends/":  {"/friends/", "/members/", "/timeline/"},
+	"/members/":  {"/members/"},
+}
+
+// endpointGroup returns the group of 'endpoint', "/shows/" for
+// "/shows/show" for instance.
+func endpointGroup(endpoint string) string {
+	parts := strings.SplitN(endpoint, "/", 3)
+	if len(parts) < 3 {
+		return endpoint
+	}
+	return "/" + parts[1] + "/"
+}
+
+// markStale removes from the cache the responses to the token of the client
+// which the successful mutation of 'endpoint' may have changed.
+func (bs *BetaSeries) markStale(endpoint string) {
+	groups := staleAfter[endpointGroup(endpoint)]
+	if len(groups) == 0 {
+		return
+	}
+	token, _ := bs.getToken()
+	base := bs.baseURL.String()
+	bs.cache.invalidate(func(key string) bool {
+		// see cacheKey
+		parts := strings.SplitN(key, "\x00", 3)
+		if len(parts) != 3 || parts[0] != token {
+			return false
+		}
+		endpoint := strings.TrimPrefix(parts[2], base)
+		for _, group := range groups {
+			if strings.HasPrefix(endpoint, group) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// cacheKey returns the key of the request to 'url' in the cache.
+func (bs *BetaSeries) cacheKey(url string) string {
+	token, _ := bs.getToken()
+	return token + "\x00" + bs.locale + "\x00" + url
+}
+
+// get returns the entry of 'key', if any, marking it as recently used.
+func (c *responseCache) get(key string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry)
+}
+
+// hit counts a request answered from the cache.
+func (c *responseCache) hit() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hits++
+}
+
+// put keeps the body of the response 'resp' to the request 'key', if it
+// can be validated later, and counts a miss.
+func (c *responseCache) put(key string, resp *http.Response, body []byte) {
+	entry := &cacheEntry{
+		key:          key,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		body:         body,
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.misses++
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	if entry.etag == "" && entry.lastModified == "" {
+		return
+	}
+	if c.maxBytes > 0 && len(body) > c.maxBytes {
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	if len(body) > 0 {
+		c.bodies[&body[0]] = entry
+	}
+	c.bytes += len(body)
+	for c.order.Len() > c.max || c.maxBytes > 0 && c.bytes > c.maxBytes {
+		c.remove(c.order.Back())
+		c.evictions++
+	}
+}
+
+// remove removes the entry 'elem'. c.mu must be held.
+func (c *responseCache) remove(elem *list.Element) {
+	entry := c.order.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.key)
+	if len(entry.body) > 0 {
+		delete(c.bodies, &entry.body[0])
+	}
+	c.bytes -= len(entry.body)
+}
+
+// decoded sets 'data' to a copy of the value decoded earlier from 'body', if
+// it is the body of an entry already decoded into the type of 'data'. It
+// reports whether it did.
+func (c *responseCache) decoded(body []byte, data interface{}) bool {
+	if len(body) == 0 {
+		return false
+	}
+	c.mu.Lock()
+	var decoded reflect.Value
+	if entry := c.bodies[&body[0]]; entry != nil {
+		decoded = entry.decoded
+	}
+	c.mu.Unlock()
+	v := reflect.ValueOf(data)
+	if !decoded.IsValid() || decoded.Type() != v.Type() || v.IsNil() {
+		return false
+	}
+	v.Elem().Set(deepCopy(decoded.Elem()))
+	return true
+}
+
+// keepDecoded keeps a copy of 'data', decoded from 'body', if it is the body
+// of an entry.
+func (c *responseCache) keepDecoded(body []byte, data interface{}) {
+	v := reflect.ValueOf(data)
+	if len(body) == 0 || v.Kind() != reflect.Ptr {
+		return
+	}
+	c.mu.Lock()
+	entry := c.bodies[&body[0]]
+	c.mu.Unlock()
+	if entry == nil {
+		return
+	}
+	decoded := deepCopy(v)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.decoded = decoded
+}
+
+// deepCopy returns a copy of 'v' sharing no pointer, slice nor map with it.
+// The unexported fields, such as the JSON of the raw capture, are copied as
+// is.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(deepCopy(v.Elem()))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(deepCopy(v.Elem()))
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				copied.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return copied
+	}
+	return v
+}
+
+// invalidate removes the entries whose key is 'stale'.
+func (c *responseCache) invalidate(stale func(key string) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, elem := range c.entries {
+		if stale(key) {
+			c.remove(elem)
+		}
+	}
+}
+
+// len returns the number of entries in the cache.
+func (c *responseCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// stats returns the counters of the cache.
+func (c *responseCache) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{
+		Entries:   c.order.Len(),
+		Bytes:     c.bytes,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
+
+// resetCounters resets the hits, misses and evictions.
+func (c *responseCache) resetCounters() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hits, c.misses, c.evictions = 0, 0, 0
+}
+
+// setConditions adds the validators of 'entry' to the request.
+func (entry *cacheEntry) setConditions(req *http.Request) {
+	if entry.etag != "" {
+		req.Header.Set("If-None-Match", entry.etag)
+	}
+	if entry.lastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.lastModified)
+	}
+}
@@ -0,0 +1,345 @@
+package bsclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	. "gopkg.in/check.v1"
+)
+
+// etagShow answers with the ETag of the current version of the show, or
+// 304 when the request holds it.
+func etagShow(version *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		etag := fmt.Sprintf(`"v%d"`, *version)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		writeFakeJSON(w, 200, fmt.Sprintf(`{"show":{"id":1,"title":"Version %d"},"errors":[]}`, *version))
+	}
+}
+
+func (s *MySuite) TestCache(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	version := 1
+	f.handle("GET", "/shows/display", etagShow(&version))
+	bs, err := NewClient("key", WithBaseURL(f.URL), WithCache(2))
+	c.Assert(err, IsNil)
+	bs.SetToken("token")
+
+	for i := 0; i < 2; i++ {
+		show, err := bs.ShowDisplay(ctx, 1, 0, "")
+		c.Assert(err, IsNil)
+		c.Assert(show.Title, Equals, "Version 1")
+		// the results do not share data
+		show.Title = "changed"
+	}
+	calls := f.callsTo("/shows/display")
+	c.Assert(calls, HasLen, 2)
+	c.Assert(calls[0].Header.Get("If-None-Match"), Equals, "")
+	c.Assert(calls[1].Header.Get("If-None-Match"), Equals, `"v1"`)
+
+	version = 2
+	show, err := bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.Title, Equals, "Version 2")
+	show, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.Title, Equals, "Version 2")
+	c.Assert(f.callsTo("/shows/display")[3].Header.Get("If-None-Match"), Equals, `"v2"`)
+	stats := bs.CacheStats()
+	c.Assert(stats.Hits, Equals, int64(2))
+	c.Assert(stats.Misses, Equals, int64(2))
+	c.Assert(stats.Entries, Equals, 1)
+	c.Assert(stats.Bytes, Equals, len(`{"show":{"id":1,"title":"Version 2"},"errors":[]}`))
+
+	// not shared across tokens
+	bs.SetToken("other")
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/shows/display")[4].Header.Get("If-None-Match"), Equals, "")
+	c.Assert(bs.cache.len(), Equals, 2)
+
+	// the least recently used entry is evicted
+	_, err = bs.ShowDisplay(ctx, 2, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(bs.cache.len(), Equals, 2)
+	bs.SetToken("token")
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/shows/display")[6].Header.Get("If-None-Match"), Equals, "")
+	c.Assert(bs.CacheStats().Evictions, Equals, int64(2))
+	bs.ResetStats()
+	c.Assert(bs.CacheStats(), Equals, CacheStats{Entries: 2, Bytes: bs.cache.bytes})
+
+	// cache hits honor the context
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = bs.ShowDisplay(canceled, 1, 0, "")
+	c.Assert(err, Equals, context.Canceled)
+}
+
+func (s *MySuite) TestCacheDisabled(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	version := 1
+	f.handle("GET", "/shows/display", etagShow(&version))
+	f.handleJSON("POST", "/shows/show", 200, `{"show":{"id":1},"errors":[]}`)
+	bs := f.client(c)
+	c.Assert(bs.cache, IsNil)
+	for i := 0; i < 2; i++ {
+		_, err := bs.ShowDisplay(ctx, 1, 0, "")
+		c.Assert(err, IsNil)
+	}
+	c.Assert(f.callsTo("/shows/display")[1].Header.Get("If-None-Match"), Equals, "")
+
+	// only GET requests are cached, without validator nothing is kept
+	bs, err := NewClient("key", WithBaseURL(f.URL), WithCache(10))
+	c.Assert(err, IsNil)
+	bs.SetToken("token")
+	_, err = bs.ShowAdd(ctx, 1, 0, "", 0)
+	c.Assert(err, IsNil)
+	f.handleJSON("GET", "/shows/display", 200, `{"show":{"id":1},"errors":[]}`)
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(bs.cache.len(), Equals, 0)
+
+	bs, err = NewClient("key", WithBaseURL(f.URL), WithCache(0))
+	c.Assert(err, IsNil)
+	c.Assert(bs.cache, IsNil)
+	c.Assert(bs.CacheStats(), Equals, CacheStats{})
+}
+
+// cachedResponse returns a response which can be cached.
+func cachedResponse(etag string) *http.Response {
+	return &http.Response{Header: http.Header{"Etag": {etag}}}
+}
+
+func (s *MySuite) TestCacheMaxBytes(c *C) {
+	cache := newResponseCache(10, 100)
+	cache.put("a", cachedResponse(`"a"`), make([]byte, 40))
+	cache.put("b", cachedResponse(`"b"`), make([]byte, 40))
+	c.Assert(cache.stats(), Equals, CacheStats{Entries: 2, Bytes: 80, Misses: 2})
+
+	// the least recently used entries are evicted beyond the bytes bound
+	c.Assert(cache.get("a"), NotNil)
+	cache.put("c", cachedResponse(`"c"`), make([]byte, 30))
+	c.Assert(cache.get("b"), IsNil)
+	c.Assert(cache.stats(), Equals, CacheStats{Entries: 2, Bytes: 70, Misses: 3, Evictions: 1})
+
+	// replacing an entry accounts for its new size
+	cache.put("a", cachedResponse(`"a2"`), make([]byte, 10))
+	c.Assert(cache.stats().Bytes, Equals, 40)
+
+	// a body larger than the bound is not kept
+	cache.put("d", cachedResponse(`"d"`), make([]byte, 101))
+	c.Assert(cache.get("d"), IsNil)
+	c.Assert(cache.stats(), Equals, CacheStats{Entries: 2, Bytes: 40, Misses: 5, Evictions: 1})
+
+	f := newFakeServer()
+	defer f.Close()
+	f.handle("GET", "/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"`+r.URL.Query().Get("id")+`"`)
+		writeFakeJSON(w, 200, `{"show":{"id":1,"title":"`+strings.Repeat("x", 100)+`"},"errors":[]}`)
+	})
+	bs, err := NewClient("key", WithBaseURL(f.URL), WithCache(10), WithCacheMaxBytes(300))
+	c.Assert(err, IsNil)
+	for id := 1; id <= 5; id++ {
+		_, err = bs.ShowDisplay(ctx, id, 0, "")
+		c.Assert(err, IsNil)
+	}
+	stats := bs.CacheStats()
+	c.Assert(stats.Entries, Equals, 2)
+	c.Assert(stats.Evictions, Equals, int64(3))
+	c.Assert(stats.Bytes <= 300, Equals, true)
+}
+
+func (s *MySuite) TestCacheConcurrent(c *C) {
+	cache := newResponseCache(20, 2000)
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := fmt.Sprintf("%d", (worker*7+i)%50)
+				if entry := cache.get(key); entry != nil {
+					cache.hit()
+					continue
+				}
+				cache.put(key, cachedResponse(`"`+key+`"`), make([]byte, (worker*13+i)%150))
+				cache.stats()
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	stats := cache.stats()
+	c.Assert(stats.Entries <= 20, Equals, true)
+	c.Assert(stats.Bytes <= 2000, Equals, true)
+	c.Assert(stats.Hits+stats.Misses, Equals, int64(8*500))
+	bytes := 0
+	for elem := cache.order.Front(); elem != nil; elem = elem.Next() {
+		bytes += len(elem.Value.(*cacheEntry).body)
+	}
+	c.Assert(stats.Bytes, Equals, bytes)
+	c.Assert(cache.entries, HasLen, stats.Entries)
+}
+
+func (s *MySuite) TestCacheForceRefresh(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	// the ETag does not change with the show, as when another device
+	// changed it
+	version := 1
+	f.handle("GET", "/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v"`)
+		writeFakeJSON(w, 200, fmt.Sprintf(`{"show":{"id":1,"title":"Version %d"},"errors":[]}`, version))
+	})
+	bs, err := NewClient("key", WithBaseURL(f.URL), WithCache(10))
+	c.Assert(err, IsNil)
+	bs.SetToken("token")
+
+	show, err := bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.Title, Equals, "Version 1")
+
+	// the cached show is returned normally
+	version = 2
+	show, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.Title, Equals, "Version 1")
+	c.Assert(bs.CacheStats().Hits, Equals, int64(1))
+
+	// and bypassed under the flag
+	show, err = bs.ShowDisplay(ForceRefresh(ctx), 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.Title, Equals, "Version 2")
+	calls := f.callsTo("/shows/display")
+	c.Assert(calls, HasLen, 3)
+	c.Assert(calls[2].Header.Get("If-None-Match"), Equals, "")
+
+	// the cache is updated afterward
+	show, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.Title, Equals, "Version 2")
+	c.Assert(f.callsTo("/shows/display")[3].Header.Get("If-None-Match"), Equals, `"v"`)
+	c.Assert(bs.CacheStats().Hits, Equals, int64(2))
+}
+
+func (s *MySuite) TestCacheMarkStale(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	version := 1
+	f.handle("GET", "/shows/display", etagShow(&version))
+	f.handle("GET", "/news/last", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"news"`)
+		writeFakeJSON(w, 200, `{"news":[{"id":"1"}],"errors":[]}`)
+	})
+	f.handleJSON("POST", "/shows/show", 200, `{"show":{"id":1},"errors":[]}`)
+	f.handleJSON("POST", "/episodes/watched", 200, `{"episode":{"id":2},"errors":[]}`)
+	bs, err := NewClient("key", WithBaseURL(f.URL), WithCache(10))
+	c.Assert(err, IsNil)
+	bs.SetToken("other")
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	bs.SetToken("token")
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	_, err = bs.NewsLast(ctx, 1, false)
+	c.Assert(err, IsNil)
+	c.Assert(bs.cache.len(), Equals, 3)
+
+	// the mutations remove the responses of the token they may change
+	_, err = bs.ShowAdd(ctx, 1, 0, "", 0)
+	c.Assert(err, IsNil)
+	c.Assert(bs.cache.len(), Equals, 2)
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/shows/display")[2].Header.Get("If-None-Match"), Equals, "")
+	c.Assert(bs.cache.len(), Equals, 3)
+	_, err = bs.EpisodeWatched(ctx, 2, 0, 0, false, false)
+	c.Assert(err, IsNil)
+	c.Assert(bs.cache.len(), Equals, 2)
+
+	// the news and the responses to the other token are kept
+	_, err = bs.NewsLast(ctx, 1, false)
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/news/last")[1].Header.Get("If-None-Match"), Equals, `"news"`)
+	bs.SetToken("other")
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/shows/display")[3].Header.Get("If-None-Match"), Equals, `"v1"`)
+
+	c.Assert(endpointGroup("/shows/show"), Equals, "/shows/")
+	c.Assert(endpointGroup("/shows"), Equals, "/shows")
+}
+
+func (s *MySuite) TestCacheDecoded(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handle("GET", "/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v"`)
+		writeFakeJSON(w, 200, `{"show":{"id":1,"title":"Show","genres":{"Drama":"Drame"}},"errors":[]}`)
+	})
+	bs, err := NewClient("key", WithBaseURL(f.URL), WithCache(10))
+	c.Assert(err, IsNil)
+	bs.SetToken("token")
+
+	show, err := bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	show.Genres[0].Key = "changed"
+
+	// a hit returns the decoded show without decoding the body again
+	entry := bs.cache.get(bs.cacheKey(f.URL + "/shows/display?id=1"))
+	c.Assert(entry, NotNil)
+	c.Assert(entry.decoded.IsValid(), Equals, true)
+	for i := range entry.body {
+		entry.body[i] = ' '
+	}
+	for i := 0; i < 2; i++ {
+		show, err = bs.ShowDisplay(ctx, 1, 0, "")
+		c.Assert(err, IsNil)
+		c.Assert(show.Title, Equals, "Show")
+		c.Assert(show.Genres.Keys(), DeepEquals, []string{"Drama"})
+		show.Title = "changed"
+	}
+	c.Assert(bs.CacheStats().Hits, Equals, int64(2))
+
+	// the copies share nothing
+	type nested struct {
+		Shows  []Show
+		ByID   map[int]*Show
+		Any    interface{}
+		hidden []int
+	}
+	value := &nested{
+		Shows:  []Show{{ID: 1, Genres: Genres{{Key: "Drama"}}}},
+		ByID:   map[int]*Show{1: {ID: 1}},
+		Any:    []interface{}{"a"},
+		hidden: []int{1},
+	}
+	copied := deepCopy(reflect.ValueOf(value)).Interface().(*nested)
+	c.Assert(copied, DeepEquals, value)
+	copied.Shows[0].Genres[0].Key = "changed"
+	copied.ByID[1].ID = 2
+	copied.Any.([]interface{})[0] = "b"
+	c.Assert(value.Shows[0].Genres[0].Key, Equals, "Drama")
+	c.Assert(value.ByID[1].ID, Equals, 1)
+	c.Assert(value.Any, DeepEquals, []interface{}{"a"})
+}
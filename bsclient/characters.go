@@ -0,0 +1,99 @@
+package bsclient
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+var (
+	errEmptyActorName = newError(ErrInvalidInput, "empty actor name")
+)
+
+// findActorConcurrency is the number of concurrent requests of FindActor.
+const findActorConcurrency = 4
+
+// CharactersForShows returns the characters of the shows 'ids', keyed by show
+// id, requesting at most 'concurrency' shows at once (1 if 'concurrency' is
+// not positive). Shows without characters are left out of the map.
+// The other failures do not stop the batch: the characters of the shows
+// which succeeded are returned along with a BatchError. If 'ctx' is done,
+// they are returned along with its error.
+func (bs *BetaSeries) CharactersForShows(ctx context.Context, ids []int, concurrency int) (map[int][]Character, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var mu sync.Mutex
+	found := map[int][]Character{}
+	failed := BatchError{}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for _, id := range ids {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			characters, err := bs.ShowsCharacters(ctx, id, 0)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				found[id] = characters
+			case !errors.Is(err, ErrNotFound):
+				failed[id] = err
+			}
+		}(id)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return found, err
+	}
+	if len(failed) > 0 {
+		return found, failed
+	}
+	return found, nil
+}
+
+// CharacterMatch is a character played by the actor searched by FindActor.
+type CharacterMatch struct {
+	ShowID    int
+	Character Character
+}
+
+// FindActor returns the characters of the shows 'ids' whose actor name
+// contains 'name', ignoring case and accents ("berenice" finds "Bérénice
+// Bejo"). The matches follow the order of 'ids', then the characters order.
+// Like CharactersForShows, failing shows are reported with a BatchError
+// along with the matches found in the other shows.
+func (bs *BetaSeries) FindActor(ctx context.Context, ids []int, name string) ([]CharacterMatch, error) {
+	key := foldString(strings.Join(strings.Fields(name), " "))
+	if key == "" {
+		return nil, errEmptyActorName
+	}
+	found, err := bs.CharactersForShows(ctx, ids, findActorConcurrency)
+	var batchErr BatchError
+	if err != nil && !errors.As(err, &batchErr) {
+		return nil, err
+	}
+	var matches []CharacterMatch
+	for _, id := range ids {
+		for _, character := range found[id] {
+			if strings.Contains(foldString(character.Actor), key) {
+				matches = append(matches, CharacterMatch{ShowID: id, Character: character})
+			}
+		}
+		// a show listed twice is only searched once
+		delete(found, id)
+	}
+	return matches, err
+}
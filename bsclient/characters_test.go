@@ -0,0 +1,112 @@
+package bsclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestFoldString(c *C) {
+	c.Assert(foldString("Bérénice"), Equals, "berenice")
+	c.Assert(foldString("BÉRÉNICE"), Equals, "berenice")
+	// decomposed accents are folded as well
+	c.Assert(foldString("Bérénice"), Equals, "berenice")
+	c.Assert(foldString("Zoë Kravitz"), Equals, "zoe kravitz")
+	c.Assert(foldString("Ça Ŝ'Ĕst Passé À Žilina"), Equals, "ca s'est passe a zilina")
+	// letters without decomposition are kept
+	c.Assert(foldString("Œdipe Øre"), Equals, "œdipe øre")
+}
+
+func (s *MySuite) TestCharactersForShows(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	release := make(chan struct{})
+	f.handle("GET", "/shows/characters", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		if r.URL.Query().Get("id") == "1" {
+			<-release
+		}
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+		switch r.URL.Query().Get("id") {
+		case "1":
+			writeFakeJSON(w, 200, `{"characters":[{"id":10,"show_id":1,"name":"Walter White","actor":"Bryan Cranston"}]}`)
+		case "2":
+			writeFakeJSON(w, 200, `{"characters":[
+				{"id":20,"show_id":2,"name":"Thomas Shelby","actor":"Cillian Murphy"},
+				{"id":21,"show_id":2,"name":"Grace","actor":"Annabelle Wallis"}]}`)
+		case "3":
+			writeFakeJSON(w, 200, `{"characters":[{"id":30,"show_id":3,"name":"Julie","actor":"Bérénice Bejo"}]}`)
+		case "4":
+			writeFakeJSON(w, 200, `{"characters":[]}`)
+		default:
+			writeFakeJSON(w, 503, ``)
+		}
+	})
+	bs := f.client(c)
+
+	go func() {
+		// let the other shows be requested while the first one blocks
+		for {
+			mu.Lock()
+			done := maxRunning >= 2
+			mu.Unlock()
+			if done {
+				close(release)
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	found, err := bs.CharactersForShows(ctx, []int{1, 2, 3, 4, 5}, 2)
+	c.Assert(maxRunning, Equals, 2)
+	c.Assert(err, FitsTypeOf, BatchError{})
+	c.Assert(err.(BatchError), HasLen, 1)
+	c.Assert(err.(BatchError)[5], NotNil)
+	c.Assert(err, ErrorMatches, "1 failed: 5: .*")
+	c.Assert(found, HasLen, 3)
+	c.Assert(found[2], HasLen, 2)
+	c.Assert(f.callsTo("/shows/characters"), HasLen, 5)
+
+	matches, err := bs.FindActor(ctx, []int{3, 2, 4}, "  berenice ")
+	c.Assert(err, IsNil)
+	c.Assert(matches, HasLen, 1)
+	c.Assert(matches[0].ShowID, Equals, 3)
+	c.Assert(matches[0].Character.Name, Equals, "Julie")
+
+	matches, err = bs.FindActor(ctx, []int{2, 1, 5}, "CILLIAN")
+	c.Assert(err, FitsTypeOf, BatchError{})
+	c.Assert(matches, HasLen, 1)
+	c.Assert(matches[0].Character.ID, Equals, 20)
+
+	_, err = bs.FindActor(ctx, []int{1}, " ")
+	c.Assert(err, Equals, errEmptyActorName)
+}
+
+func (s *MySuite) TestCharactersForShowsCanceled(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/characters", 200, `{"characters":[]}`)
+	f.setLatency(time.Minute)
+	bs := f.client(c)
+
+	// the other shows wait for the slot of the first one
+	cancelDuring(c, 50*time.Millisecond, func(ctx context.Context) error {
+		_, err := bs.CharactersForShows(ctx, []int{1, 2, 3}, 1)
+		return err
+	})
+	c.Assert(f.callsTo("/shows/characters"), HasLen, 1)
+}
@@ -0,0 +1,68 @@
+package bsclient
+
+// Codes of the API errors, see APIError
+const (
+	// CodeInvalidAPIKey tells the API key is unknown
+	CodeInvalidAPIKey = 1001
+	// CodeDisabledAPIKey tells the API key was disabled
+	CodeDisabledAPIKey = 1002
+	// CodeRateLimited tells too many requests were sent with the API key
+	CodeRateLimited = 1003
+	// CodeInvalidToken tells the member token is invalid or expired
+	CodeInvalidToken = 2001
+	// CodePrivateProfile tells the member does not share the requested data
+	CodePrivateProfile = 2003
+	// CodeAlreadyInAccount tells the show or episode is already in the
+	// member's account, or already marked
+	CodeAlreadyInAccount = 2004
+	// CodePremiumRequired tells the feature is reserved to premium accounts
+	CodePremiumRequired = 2005
+	// CodeNotFound tells the requested show, or other entity, does not exist
+	CodeNotFound = 4001
+	// CodeShowNotFound tells the requested show does not exist, the API
+	// using the generic not found code for shows
+	CodeShowNotFound = CodeNotFound
+	// CodeEpisodeNotFound tells the requested episode does not exist
+	CodeEpisodeNotFound = 4002
+)
+
+// apiCode describes an API error code
+type apiCode struct {
+	// name is the stable name returned by CodeName
+	name string
+	// class is the error matched by the API errors of the code
+	class error
+}
+
+// apiCodes describes the known API error codes: adding a code only takes a
+// constant and an entry here.
+var apiCodes = map[int]apiCode{
+	CodeInvalidAPIKey:    {"invalid_api_key", ErrInvalidAPIKey},
+	CodeDisabledAPIKey:   {"disabled_api_key", ErrInvalidAPIKey},
+	CodeRateLimited:      {"rate_limited", ErrRateLimited},
+	CodeInvalidToken:     {"invalid_token", ErrTokenInvalid},
+	CodePrivateProfile:   {"private_profile", ErrProfilePrivate},
+	CodeAlreadyInAccount: {"already_in_account", ErrInvalidInput},
+	CodePremiumRequired:  {"premium_required", ErrPremiumRequired},
+	CodeNotFound:         {"not_found", ErrNotFound},
+	CodeEpisodeNotFound:  {"episode_not_found", ErrNotFound},
+}
+
+// CodeName returns a stable English identifier of the API error 'code',
+// unlike the error texts which depend on the locale (see SetLocale). It
+// returns "unknown" for the codes it does not know.
+func CodeName(code int) string {
+	if known, ok := apiCodes[code]; ok {
+		return known.name
+	}
+	return "unknown"
+}
+
+// codeClass returns the error matched by the API errors of 'code',
+// ErrUnknownAPIError for the codes it does not know.
+func codeClass(code int) error {
+	if known, ok := apiCodes[code]; ok {
+		return known.class
+	}
+	return ErrUnknownAPIError
+}
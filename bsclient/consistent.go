@@ -0,0 +1,53 @@
+package bsclient
+
+import (
+	"context"
+)
+
+// ConsistentMeta tells how ConsistentList built its result.
+type ConsistentMeta struct {
+	// Listed is the number of shows of the snapshot of the listing
+	Listed int
+	// Duplicates is the number of shows listed more than once in the
+	// snapshot, returned once
+	Duplicates int
+	// Requests is the number of requests sent
+	Requests int
+}
+
+// ConsistentShows is the result of ConsistentList.
+type ConsistentShows struct {
+	Shows []Show
+	Meta  ConsistentMeta
+}
+
+// ConsistentList returns the shows of the member 'userID' (the
+// authenticated member if 0) in the order of their listing, each show once
+// even if the listing changes during the read.
+// The whole listing is captured in one members/infos request, whose shows
+// are returned as is: unlike a read by pages, the snapshot cannot skip nor
+// duplicate shows, and no other request is needed for their details.
+func (bs *BetaSeries) ConsistentList(ctx context.Context, userID int) (*ConsistentShows, error) {
+	result := &ConsistentShows{}
+	member, err := bs.MembersInfos(ctx, userID, false, "shows")
+	result.Meta.Requests++
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return result, nil
+	}
+	seen := map[int]bool{}
+	for _, show := range member.Shows {
+		switch {
+		case show.ID == 0:
+		case seen[show.ID]:
+			result.Meta.Duplicates++
+		default:
+			seen[show.ID] = true
+			result.Shows = append(result.Shows, show)
+		}
+	}
+	result.Meta.Listed = len(result.Shows)
+	return result, nil
+}
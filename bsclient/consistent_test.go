@@ -0,0 +1,69 @@
+package bsclient
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// memberLibrary is the fake listing of the shows of a member, changing
+// after each read.
+type memberLibrary struct {
+	shows []int
+	// onList is called once the listing is written
+	onList func()
+}
+
+func (l *memberLibrary) serve(f *fakeServer) {
+	f.handle("GET", "/members/infos", func(w http.ResponseWriter, r *http.Request) {
+		shows := []string{}
+		for _, id := range l.shows {
+			shows = append(shows, fmt.Sprintf(`{"id":%d,"title":"Show %d","in_account":true}`, id, id))
+		}
+		writeFakeJSON(w, 200, `{"member":{"id":1,"shows":[`+strings.Join(shows, ",")+`]},"errors":[]}`)
+		if l.onList != nil {
+			l.onList()
+		}
+	})
+}
+
+func (s *MySuite) TestConsistentList(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	library := &memberLibrary{shows: []int{1, 2, 3, 2, 4, 0, 5}}
+	library.serve(f)
+	// the listing changes once read
+	library.onList = func() {
+		library.shows = []int{10, 11, 1, 3, 5}
+	}
+	bs := f.client(c)
+
+	result, err := bs.ConsistentList(ctx, 0)
+	c.Assert(err, IsNil)
+	c.Assert(showIDs(result.Shows), DeepEquals, []int{1, 2, 3, 4, 5})
+	c.Assert(result.Shows[1].Title, Equals, "Show 2")
+	c.Assert(result.Shows[1].InAccount, Equals, true)
+	c.Assert(result.Meta, Equals, ConsistentMeta{Listed: 5, Duplicates: 1, Requests: 1})
+	// the snapshot is the only request
+	c.Assert(f.calls, HasLen, 1)
+	c.Assert(f.callsTo("/members/infos")[0].Query.Get("only"), Equals, "shows")
+
+	result, err = bs.ConsistentList(ctx, 42)
+	c.Assert(err, IsNil)
+	c.Assert(showIDs(result.Shows), DeepEquals, []int{10, 11, 1, 3, 5})
+	c.Assert(f.callsTo("/members/infos")[1].Query.Get("id"), Equals, "42")
+
+	// a member without shows
+	f.handleJSON("GET", "/members/infos", 200, `{"errors":[]}`)
+	result, err = bs.ConsistentList(ctx, 0)
+	c.Assert(err, IsNil)
+	c.Assert(result.Shows, HasLen, 0)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = bs.ConsistentList(canceled, 0)
+	c.Assert(err, Equals, context.Canceled)
+}
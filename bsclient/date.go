@@ -0,0 +1,99 @@
+package bsclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Layouts of the API dates, which may carry a time of day. Dates without
+// offset are local times of Europe/Paris, where BetaSeries is run.
+const (
+	apiDateLayout     = "2006-01-02"
+	apiDateTimeLayout = "2006-01-02 15:04:05"
+)
+
+// apiLocation is the time zone of the API dates, Central European Time
+// without daylight saving if the time zone database is missing.
+var apiLocation = loadAPILocation()
+
+func loadAPILocation() *time.Location {
+	loc, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		return time.FixedZone("CET", 3600)
+	}
+	return loc
+}
+
+// Date is a date of the API, such as the air date of an episode, with or
+// without time of day. Missing dates, sent as null, "" or "0000-00-00", are
+// zero. Dates which cannot be parsed are decoded as zero as well rather
+// than failing the decoding of the whole response.
+type Date struct {
+	t     time.Time
+	timed bool
+}
+
+// ParseDate parses an API date, "2014-10-12" or "2014-10-12 22:30:00" in the
+// time zone of Paris, or a RFC 3339 date. Missing dates give the zero Date.
+func ParseDate(s string) (Date, error) {
+	if s == "" || strings.HasPrefix(s, "0000-00-00") {
+		return Date{}, nil
+	}
+	if t, err := time.ParseInLocation(apiDateTimeLayout, s, apiLocation); err == nil {
+		return Date{t, true}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return Date{t.In(apiLocation), true}, nil
+	}
+	t, err := time.ParseInLocation(apiDateLayout, s, apiLocation)
+	if err != nil {
+		return Date{}, err
+	}
+	return Date{t: t}, nil
+}
+
+// Time returns the date in the time zone of Paris, midnight if it has no
+// time of day, or the zero time.
+func (d Date) Time() time.Time {
+	return d.t
+}
+
+// IsZero reports whether the date is missing.
+func (d Date) IsZero() bool {
+	return d.t.IsZero()
+}
+
+// HasTime reports whether the date carries a time of day.
+func (d Date) HasTime() bool {
+	return d.timed
+}
+
+// String returns the date in the layout of the API, "" if it is missing.
+func (d Date) String() string {
+	switch {
+	case d.IsZero():
+		return ""
+	case d.timed:
+		return d.t.Format(apiDateTimeLayout)
+	}
+	return d.t.Format(apiDateLayout)
+}
+
+// UnmarshalJSON decodes an API date, see ParseDate. Invalid dates are
+// decoded as zero without error.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if bytes.Equal(data, []byte("null")) || json.Unmarshal(data, &s) != nil {
+		*d = Date{}
+		return nil
+	}
+	*d, _ = ParseDate(s)
+	return nil
+}
+
+// MarshalJSON encodes the date in the layout of the API, see String.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
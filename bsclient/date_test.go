@@ -0,0 +1,71 @@
+package bsclient
+
+import (
+	"encoding/json"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// apiDate parses the API date 's', which must be valid.
+func apiDate(s string) Date {
+	date, err := ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return date
+}
+
+func (s *MySuite) TestDate(c *C) {
+	if _, err := time.LoadLocation("Europe/Paris"); err != nil {
+		c.Skip("no time zone database")
+	}
+	for _, test := range []struct {
+		json    string
+		utc     string
+		timed   bool
+		marshal string
+	}{
+		// Paris is one hour ahead of UTC in winter, two in summer
+		{`"2014-01-12 22:30:00"`, "2014-01-12T21:30:00Z", true, `"2014-01-12 22:30:00"`},
+		{`"2014-10-12 22:30:00"`, "2014-10-12T20:30:00Z", true, `"2014-10-12 22:30:00"`},
+		{`"2014-10-12"`, "2014-10-11T22:00:00Z", false, `"2014-10-12"`},
+		{`"2014-10-12T22:30:00Z"`, "2014-10-12T22:30:00Z", true, `"2014-10-13 00:30:00"`},
+		{`""`, "", false, `""`},
+		{`null`, "", false, `""`},
+		{`"0000-00-00"`, "", false, `""`},
+		{`"0000-00-00 00:00:00"`, "", false, `""`},
+		// invalid dates are decoded as missing
+		{`"soon"`, "", false, `""`},
+		{`"2014-13-45"`, "", false, `""`},
+		{`20141012`, "", false, `""`},
+	} {
+		var date Date
+		c.Assert(json.Unmarshal([]byte(test.json), &date), IsNil, Commentf(test.json))
+		c.Assert(date.IsZero(), Equals, test.utc == "", Commentf(test.json))
+		if test.utc != "" {
+			c.Assert(date.Time().UTC().Format(time.RFC3339), Equals, test.utc, Commentf(test.json))
+			c.Assert(date.Time().Location().String(), Equals, "Europe/Paris")
+		}
+		c.Assert(date.HasTime(), Equals, test.timed, Commentf(test.json))
+		data, err := json.Marshal(date)
+		c.Assert(err, IsNil)
+		c.Assert(string(data), Equals, test.marshal, Commentf(test.json))
+	}
+
+	_, err := ParseDate("soon")
+	c.Assert(err, NotNil)
+
+	// an invalid date does not fail the decoding of its episode
+	var episode Episode
+	err = json.Unmarshal([]byte(`{"id":1,"date":"2014-02-30","code":"S01E01"}`), &episode)
+	c.Assert(err, IsNil)
+	c.Assert(episode.ID, Equals, 1)
+	c.Assert(episode.Code, Equals, "S01E01")
+	c.Assert(episode.Date.IsZero(), Equals, true)
+
+	var show Show
+	err = json.Unmarshal([]byte(`{"id":1,"user":{"next":{"id":2,"date":"2014-10-12"}}}`), &show)
+	c.Assert(err, IsNil)
+	c.Assert(show.User.Next.Date, Equals, apiDate("2014-10-12"))
+}
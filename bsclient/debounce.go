@@ -0,0 +1,162 @@
+package bsclient
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// SearchDebouncer rate limits the show searches of a search-as-you-type
+// field: only the last query typed within 'delay' is sent, results are
+// cached per normalized query and queries shorter than 'minChars' are
+// ignored. It is safe for concurrent use.
+type SearchDebouncer struct {
+	bs       *BetaSeries
+	delay    time.Duration
+	minChars int
+
+	// afterFunc schedules the searches, replaced by tests
+	afterFunc func(d time.Duration, f func()) (stop func() bool)
+
+	mu      sync.Mutex
+	pending *searchQuery
+	cache   map[string][]Show
+}
+
+// searchQuery is a query waiting for its results.
+type searchQuery struct {
+	// ctx is canceled when the query is superseded or finished
+	ctx     context.Context
+	cancel  context.CancelFunc
+	text    string
+	key     string
+	stop    func() bool
+	results chan []Show
+	errs    chan error
+}
+
+// finish delivers the outcome of the query, if any, and closes its channels.
+func (q *searchQuery) finish(shows []Show, err error) {
+	q.cancel()
+	if err != nil {
+		q.errs <- err
+	} else if shows != nil {
+		q.results <- shows
+	}
+	close(q.results)
+	close(q.errs)
+}
+
+// NewSearchDebouncer creates a debouncer sending the searches through 'bs'
+// once no other query has been made for 'delay'.
+func NewSearchDebouncer(bs *BetaSeries, delay time.Duration, minChars int) *SearchDebouncer {
+	return &SearchDebouncer{
+		bs:       bs,
+		delay:    delay,
+		minChars: minChars,
+		afterFunc: func(d time.Duration, f func()) func() bool {
+			return time.AfterFunc(d, f).Stop
+		},
+		cache: map[string][]Show{},
+	}
+}
+
+// searchKey normalizes a query for the cache: trimmed, inner spaces
+// collapsed, ignoring case and accents.
+func searchKey(text string) string {
+	return foldString(strings.Join(strings.Fields(text), " "))
+}
+
+// Query searches shows matching 'text'. At most one value is sent on either
+// channel, then both are closed: a query superseded by a later one, or
+// shorter than the minimum number of characters, is closed without value.
+// A search matching no show yields an empty slice.
+// The search request is sent with a context derived from 'ctx': if 'ctx' is
+// done before the delay elapses, the search is not sent and its error is
+// sent at once. The context is canceled when the query is superseded,
+// aborting the search if it was already sent.
+func (d *SearchDebouncer) Query(ctx context.Context, text string) (<-chan []Show, <-chan error) {
+	ctx, cancel := context.WithCancel(ctx)
+	q := &searchQuery{
+		ctx:     ctx,
+		cancel:  cancel,
+		text:    text,
+		key:     searchKey(text),
+		results: make(chan []Show, 1),
+		errs:    make(chan error, 1),
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.supersede()
+	if utf8.RuneCountInString(q.key) < d.minChars {
+		q.finish(nil, nil)
+		return q.results, q.errs
+	}
+	if shows, ok := d.cache[q.key]; ok {
+		q.finish(shows, nil)
+		return q.results, q.errs
+	}
+	d.pending = q
+	q.stop = d.afterFunc(d.delay, func() { d.search(q) })
+	go d.abandon(q)
+	return q.results, q.errs
+}
+
+// abandon closes 'q' with the error of its context if the context is done
+// while the query waits for the delay.
+func (d *SearchDebouncer) abandon(q *searchQuery) {
+	<-q.ctx.Done()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pending == q && q.stop() {
+		d.pending = nil
+		q.finish(nil, q.ctx.Err())
+	}
+}
+
+// supersede drops the pending query, aborting its search if it was sent.
+// d.mu must be held.
+func (d *SearchDebouncer) supersede() {
+	if d.pending == nil {
+		return
+	}
+	d.pending.cancel()
+	if d.pending.stop() {
+		// the search was not started, nobody else will close the query
+		d.pending.finish(nil, nil)
+	}
+	d.pending = nil
+}
+
+// search sends the search of 'q' and delivers its results unless it has
+// been superseded meanwhile.
+func (d *SearchDebouncer) search(q *searchQuery) {
+	d.mu.Lock()
+	current := d.pending == q
+	d.mu.Unlock()
+	if !current {
+		q.finish(nil, nil)
+		return
+	}
+
+	shows, err := d.bs.ShowsSearch(q.ctx, q.text, "", true)
+	if errors.Is(err, ErrNotFound) {
+		shows, err = []Show{}, nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err == nil {
+		d.cache[q.key] = shows
+	}
+	if d.pending != q {
+		q.finish(nil, nil)
+		return
+	}
+	d.pending = nil
+	q.finish(shows, err)
+}
@@ -0,0 +1,164 @@
+package bsclient
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// fakeTimer is a search scheduled on a fakeClock.
+type fakeTimer struct {
+	delay   time.Duration
+	f       func()
+	stopped bool
+	fired   bool
+}
+
+// fakeClock records the searches scheduled by a debouncer, which only run
+// when the test fires them.
+type fakeClock struct {
+	timers []*fakeTimer
+}
+
+func (clock *fakeClock) afterFunc(d time.Duration, f func()) func() bool {
+	t := &fakeTimer{delay: d, f: f}
+	clock.timers = append(clock.timers, t)
+	return func() bool {
+		if t.stopped || t.fired {
+			return false
+		}
+		t.stopped = true
+		return true
+	}
+}
+
+func (clock *fakeClock) fire(c *C, i int) {
+	t := clock.timers[i]
+	c.Assert(t.stopped, Equals, false)
+	t.fired = true
+	t.f()
+}
+
+// assertDropped checks that a query has been closed without value.
+func assertDropped(c *C, results <-chan []Show, errs <-chan error) {
+	_, ok := <-results
+	c.Assert(ok, Equals, false)
+	_, ok = <-errs
+	c.Assert(ok, Equals, false)
+}
+
+func (s *MySuite) TestSearchDebouncer(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/search", 200, `{"shows":[{"id":1,"title":"Breaking Bad"}],"errors":[]}`)
+	clock := &fakeClock{}
+	d := NewSearchDebouncer(f.client(c), 300*time.Millisecond, 3)
+	d.afterFunc = clock.afterFunc
+
+	// too short: never scheduled
+	results, errs := d.Query(ctx, "br ")
+	assertDropped(c, results, errs)
+	c.Assert(clock.timers, HasLen, 0)
+
+	r1, e1 := d.Query(ctx, "bre")
+	r2, e2 := d.Query(ctx, "brea")
+	r3, e3 := d.Query(ctx, "Breaking")
+	c.Assert(clock.timers, HasLen, 3)
+	c.Assert(clock.timers[2].delay, Equals, 300*time.Millisecond)
+	assertDropped(c, r1, e1)
+	assertDropped(c, r2, e2)
+	c.Assert(clock.timers[0].stopped, Equals, true)
+	c.Assert(clock.timers[1].stopped, Equals, true)
+	clock.fire(c, 2)
+	shows := <-r3
+	c.Assert(shows, HasLen, 1)
+	c.Assert(shows[0].Title, Equals, "Breaking Bad")
+	assertDropped(c, r3, e3)
+	calls := f.callsTo("/shows/search")
+	c.Assert(calls, HasLen, 1)
+	c.Assert(calls[0].Query.Get("title"), Equals, "breaking")
+
+	// normalized queries are served from the cache
+	results, _ = d.Query(ctx, "  BREAKING ")
+	c.Assert(<-results, HasLen, 1)
+	c.Assert(clock.timers, HasLen, 3)
+	c.Assert(f.callsTo("/shows/search"), HasLen, 1)
+
+	// no match is an empty result
+	f.handleJSON("GET", "/shows/search", 200, `{"shows":[],"errors":[]}`)
+	results, errs = d.Query(ctx, "zzz")
+	clock.fire(c, 3)
+	shows, ok := <-results
+	c.Assert(ok, Equals, true)
+	c.Assert(shows, HasLen, 0)
+	assertDropped(c, results, errs)
+
+	// a query typed while a search is in flight supersedes it and aborts
+	// the search, whose results are neither delivered nor cached
+	var r5 <-chan []Show
+	var e5 <-chan error
+	aborted := make(chan bool, 1)
+	f.handle("GET", "/shows/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("title") == "lost" {
+			r5, e5 = d.Query(ctx, "lost in space")
+			select {
+			case <-r.Context().Done():
+				aborted <- true
+			case <-time.After(time.Second):
+				aborted <- false
+			}
+			return
+		}
+		writeFakeJSON(w, 200, `{"shows":[{"id":2,"title":"Lost"}],"errors":[]}`)
+	})
+	r4, e4 := d.Query(ctx, "lost")
+	clock.fire(c, 4)
+	assertDropped(c, r4, e4)
+	c.Assert(<-aborted, Equals, true)
+	clock.fire(c, 5)
+	c.Assert(<-r5, HasLen, 1)
+	assertDropped(c, r5, e5)
+	f.handleJSON("GET", "/shows/search", 200, `{"shows":[{"id":2,"title":"Lost"}],"errors":[]}`)
+	results, _ = d.Query(ctx, "Lost")
+	c.Assert(clock.timers, HasLen, 7)
+	clock.fire(c, 6)
+	c.Assert(<-results, HasLen, 1)
+
+	// errors are delivered and not cached
+	f.handleJSON("GET", "/shows/search", 503, ``)
+	results, errs = d.Query(ctx, "error")
+	clock.fire(c, 7)
+	c.Assert(<-errs, ErrorMatches, ".*503.*")
+	assertDropped(c, results, errs)
+	d.Query(ctx, "error")
+	c.Assert(clock.timers, HasLen, 9)
+}
+
+func (s *MySuite) TestSearchDebouncerCanceled(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/search", 200, `{"shows":[{"id":1,"title":"Breaking Bad"}],"errors":[]}`)
+	d := NewSearchDebouncer(f.client(c), time.Minute, 3)
+
+	// canceled while waiting for the delay
+	cancelDuring(c, 50*time.Millisecond, func(ctx context.Context) error {
+		results, errs := d.Query(ctx, "breaking")
+		_, ok := <-results
+		c.Assert(ok, Equals, false)
+		return <-errs
+	})
+	c.Assert(f.callsTo("/shows/search"), HasLen, 0)
+
+	// canceled while searching
+	d = NewSearchDebouncer(f.client(c), 0, 3)
+	f.setLatency(time.Minute)
+	cancelDuring(c, 50*time.Millisecond, func(ctx context.Context) error {
+		results, errs := d.Query(ctx, "breaking")
+		_, ok := <-results
+		c.Assert(ok, Equals, false)
+		return <-errs
+	})
+	c.Assert(f.callsTo("/shows/search"), HasLen, 1)
+}
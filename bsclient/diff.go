@@ -0,0 +1,144 @@
+package bsclient
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Change is a difference between two snapshots of a show, as returned by
+// DiffShows.
+type Change struct {
+	// Field is the path of the changed field, named after its JSON keys (for
+	// instance "status", "seasons_details.4.episodes" or "images.poster")
+	Field string
+	Old   string
+	New   string
+	// Message describes the change, for instance "Status changed from
+	// Continuing to Ended"
+	Message string
+}
+
+// DiffShows returns the changes from the 'old' snapshot of a show to the
+// 'new' one: status, network, episodes of the seasons, next episode to
+// watch and images. Changes come in that order, seasons by number.
+// Counters changing all the time (followers and comments) are ignored, see
+// DiffShowsAll.
+func DiffShows(old, new Show) []Change {
+	return diffShows(old, new, false)
+}
+
+// DiffShowsAll returns the changes of DiffShows followed by those of the
+// followers and comments counters.
+func DiffShowsAll(old, new Show) []Change {
+	return diffShows(old, new, true)
+}
+
+func diffShows(old, new Show, volatile bool) []Change {
+	changes := []Change{}
+	add := func(field, label, before, after string) {
+		if before != after {
+			changes = append(changes, Change{field, before, after, changeMessage(label, before, after)})
+		}
+	}
+
+	add("status", "Status", old.Status, new.Status)
+	add("network", "Network", old.Network, new.Network)
+	changes = append(changes, diffSeasons(old.SeasonsDetails, new.SeasonsDetails)...)
+	changes = append(changes, diffNext(old, new)...)
+	add("images.show", "Show image", old.Images.Show, new.Images.Show)
+	add("images.banner", "Banner image", old.Images.Banner, new.Images.Banner)
+	add("images.box", "Box image", old.Images.Box, new.Images.Box)
+	add("images.poster", "Poster image", old.Images.Poster, new.Images.Poster)
+	if volatile {
+		add("followers", "Followers", old.Followers.String(), new.Followers.String())
+		add("comments", "Comments", old.Comments.String(), new.Comments.String())
+	}
+	return changes
+}
+
+// changeMessage describes the change of a field from 'before' to 'after'.
+func changeMessage(label, before, after string) string {
+	switch {
+	case before == "":
+		return fmt.Sprintf("%s set to %s", label, after)
+	case after == "":
+		return fmt.Sprintf("%s removed (was %s)", label, before)
+	}
+	return fmt.Sprintf("%s changed from %s to %s", label, before, after)
+}
+
+// diffSeasons returns the seasons added or removed and the changes of their
+// number of episodes.
+func diffSeasons(old, new []seasonDetails) []Change {
+	before := map[int]int{}
+	for _, season := range old {
+		before[season.Number] = season.Episodes
+	}
+	after := map[int]int{}
+	for _, season := range new {
+		after[season.Number] = season.Episodes
+	}
+	numbers := []int{}
+	for number := range before {
+		numbers = append(numbers, number)
+	}
+	for number := range after {
+		if _, ok := before[number]; !ok {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Ints(numbers)
+
+	changes := []Change{}
+	for _, number := range numbers {
+		field := "seasons_details." + strconv.Itoa(number) + ".episodes"
+		name := "Season " + strconv.Itoa(number)
+		was, had := before[number]
+		is, has := after[number]
+		change := Change{Field: field}
+		if had {
+			change.Old = strconv.Itoa(was)
+		}
+		if has {
+			change.New = strconv.Itoa(is)
+		}
+		switch {
+		case !had:
+			change.Message = fmt.Sprintf("%s added with %s", name, episodesCount(is))
+		case !has:
+			change.Message = name + " removed"
+		case is > was:
+			change.Message = fmt.Sprintf("%s added to %s", episodesCount(is-was), name)
+		case is < was:
+			change.Message = fmt.Sprintf("%s removed from %s", episodesCount(was-is), name)
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// episodesCount returns "1 episode" or "n episodes".
+func episodesCount(n int) string {
+	if n == 1 {
+		return "1 episode"
+	}
+	return strconv.Itoa(n) + " episodes"
+}
+
+// diffNext returns the change of the next episode to watch, or of its date
+// if it is the same episode.
+func diffNext(old, new Show) []Change {
+	before, after := old.User.Next, new.User.Next
+	switch {
+	case before.ID != after.ID:
+		return []Change{{"user.next", before.Code, after.Code,
+			changeMessage("Next episode", before.Code, after.Code)}}
+	case before.ID != 0 && before.Date.String() != after.Date.String():
+		from, to := before.Date.String(), after.Date.String()
+		return []Change{{"user.next.date", from, to, changeMessage("Date of next episode "+after.Code, from, to)}}
+	}
+	return nil
+}
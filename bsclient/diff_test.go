@@ -0,0 +1,124 @@
+package bsclient
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+// diffBase is the snapshot the show changes are applied to.
+const diffBase = `{"id":1,"title":"Breaking Bad","status":"Continuing","network":"AMC",
+	"followers":"100","comments":"10",
+	"seasons_details":[{"number":1,"episodes":7},{"number":2,"episodes":13},{"number":3,"episodes":8}],
+	"user":{"next":{"id":21,"code":"S02E01","date":"2009-03-08"}},
+	"images":{"show":"s.jpg","banner":"b.jpg","box":"x.jpg","poster":"p.jpg"}}`
+
+// diffShow returns the base show modified by 'change'.
+func diffShow(c *C, change func(*Show)) Show {
+	show := Show{}
+	c.Assert(json.Unmarshal([]byte(diffBase), &show), IsNil)
+	if change != nil {
+		change(&show)
+	}
+	return show
+}
+
+func (s *MySuite) TestDiffShows(c *C) {
+	for _, test := range []struct {
+		name     string
+		change   func(*Show)
+		expected []Change
+	}{
+		{"unchanged", nil, []Change{}},
+		{"status", func(s *Show) { s.Status = "Ended" },
+			[]Change{{"status", "Continuing", "Ended", "Status changed from Continuing to Ended"}}},
+		{"network", func(s *Show) { s.Network = "Netflix" },
+			[]Change{{"network", "AMC", "Netflix", "Network changed from AMC to Netflix"}}},
+		{"network removed", func(s *Show) { s.Network = "" },
+			[]Change{{"network", "AMC", "", "Network removed (was AMC)"}}},
+		{"episodes added", func(s *Show) { s.SeasonsDetails[2].Episodes = 10 },
+			[]Change{{"seasons_details.3.episodes", "8", "10", "2 episodes added to Season 3"}}},
+		{"episode removed", func(s *Show) { s.SeasonsDetails[1].Episodes = 12 },
+			[]Change{{"seasons_details.2.episodes", "13", "12", "1 episode removed from Season 2"}}},
+		{"season added", func(s *Show) {
+			s.SeasonsDetails = append(s.SeasonsDetails, seasonDetails{4, 1})
+		}, []Change{{"seasons_details.4.episodes", "", "1", "Season 4 added with 1 episode"}}},
+		{"season removed", func(s *Show) { s.SeasonsDetails = s.SeasonsDetails[1:] },
+			[]Change{{"seasons_details.1.episodes", "7", "", "Season 1 removed"}}},
+		{"seasons reordered", func(s *Show) {
+			d := s.SeasonsDetails
+			s.SeasonsDetails = []seasonDetails{d[2], d[0], d[1]}
+		}, []Change{}},
+		{"seasons in number order", func(s *Show) {
+			s.SeasonsDetails = []seasonDetails{{5, 2}, {3, 9}, {1, 7}, {2, 13}}
+		}, []Change{
+			{"seasons_details.3.episodes", "8", "9", "1 episode added to Season 3"},
+			{"seasons_details.5.episodes", "", "2", "Season 5 added with 2 episodes"},
+		}},
+		{"next episode", func(s *Show) {
+			s.User.Next.ID, s.User.Next.Code, s.User.Next.Date = 22, "S02E02", apiDate("2009-03-15")
+		}, []Change{{"user.next", "S02E01", "S02E02", "Next episode changed from S02E01 to S02E02"}}},
+		{"no next episode", func(s *Show) { s.User.Next.ID, s.User.Next.Code, s.User.Next.Date = 0, "", Date{} },
+			[]Change{{"user.next", "S02E01", "", "Next episode removed (was S02E01)"}}},
+		{"next episode date", func(s *Show) { s.User.Next.Date = apiDate("2009-03-09") },
+			[]Change{{"user.next.date", "2009-03-08", "2009-03-09",
+				"Date of next episode S02E01 changed from 2009-03-08 to 2009-03-09"}}},
+		{"images", func(s *Show) {
+			s.Images.Show, s.Images.Banner, s.Images.Box, s.Images.Poster = "s2.jpg", "", "x.jpg", "p2.jpg"
+		}, []Change{
+			{"images.show", "s.jpg", "s2.jpg", "Show image changed from s.jpg to s2.jpg"},
+			{"images.banner", "b.jpg", "", "Banner image removed (was b.jpg)"},
+			{"images.poster", "p.jpg", "p2.jpg", "Poster image changed from p.jpg to p2.jpg"},
+		}},
+		{"volatile ignored", func(s *Show) { s.Followers, s.Comments = 120, 11 }, []Change{}},
+		{"other fields ignored", func(s *Show) { s.Title, s.Description, s.InAccount = "BB", "Walt", true },
+			[]Change{}},
+		{"all", func(s *Show) {
+			s.Status = "Ended"
+			s.Network = "Netflix"
+			s.SeasonsDetails[0].Episodes = 8
+			s.User.Next.ID, s.User.Next.Code = 22, "S02E02"
+			s.Images.Poster = "p2.jpg"
+			s.Followers = 120
+		}, []Change{
+			{"status", "Continuing", "Ended", "Status changed from Continuing to Ended"},
+			{"network", "AMC", "Netflix", "Network changed from AMC to Netflix"},
+			{"seasons_details.1.episodes", "7", "8", "1 episode added to Season 1"},
+			{"user.next", "S02E01", "S02E02", "Next episode changed from S02E01 to S02E02"},
+			{"images.poster", "p.jpg", "p2.jpg", "Poster image changed from p.jpg to p2.jpg"},
+		}},
+	} {
+		old := diffShow(c, nil)
+		new := diffShow(c, test.change)
+		c.Assert(DiffShows(old, new), DeepEquals, test.expected, Commentf(test.name))
+	}
+}
+
+func (s *MySuite) TestDiffShowsAll(c *C) {
+	old := diffShow(c, nil)
+	new := diffShow(c, func(s *Show) {
+		s.Status = "Ended"
+		s.Followers = 120
+		s.Comments = 0
+	})
+	c.Assert(DiffShowsAll(old, new), DeepEquals, []Change{
+		{"status", "Continuing", "Ended", "Status changed from Continuing to Ended"},
+		{"followers", "100", "120", "Followers changed from 100 to 120"},
+		{"comments", "10", "0", "Comments changed from 10 to 0"},
+	})
+	c.Assert(DiffShowsAll(old, old), HasLen, 0)
+
+	// from a show never fetched
+	c.Assert(DiffShows(Show{}, old), DeepEquals, []Change{
+		{"status", "", "Continuing", "Status set to Continuing"},
+		{"network", "", "AMC", "Network set to AMC"},
+		{"seasons_details.1.episodes", "", "7", "Season 1 added with 7 episodes"},
+		{"seasons_details.2.episodes", "", "13", "Season 2 added with 13 episodes"},
+		{"seasons_details.3.episodes", "", "8", "Season 3 added with 8 episodes"},
+		{"user.next", "", "S02E01", "Next episode set to S02E01"},
+		{"images.show", "", "s.jpg", "Show image set to s.jpg"},
+		{"images.banner", "", "b.jpg", "Banner image set to b.jpg"},
+		{"images.box", "", "x.jpg", "Box image set to x.jpg"},
+		{"images.poster", "", "p.jpg", "Poster image set to p.jpg"},
+	})
+}
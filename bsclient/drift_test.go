@@ -0,0 +1,187 @@
+package bsclient
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// Schema drift detection: every recorded fixture is decoded into the type
+// used by its endpoint and marshalled back, the keys of the fixture missing
+// from the output being fields the structs do not model. Knowingly ignored
+// keys are listed in the allowlist, any other one fails the test.
+
+const (
+	fixturesDir    = "testdata/recorded"
+	driftAllowlist = "testdata/drift_allowlist.txt"
+)
+
+// fixtureTypes returns the value the responses of each endpoint, named as
+// in the fixture names, are decoded into.
+var fixtureTypes = map[string]func() interface{}{
+	"shows_display":    func() interface{} { return &showItem{} },
+	"shows_search":     func() interface{} { return &shows{} },
+	"shows_list":       func() interface{} { return &shows{} },
+	"shows_random":     func() interface{} { return &shows{} },
+	"shows_similars":   func() interface{} { return &similars{} },
+	"shows_characters": func() interface{} { return &characters{} },
+	"shows_videos":     func() interface{} { return &videos{} },
+	"shows_episodes":   func() interface{} { return &episodes{} },
+	"episodes_display": func() interface{} { return &episodeItem{} },
+	"planning_general": func() interface{} { return &episodes{} },
+	"members_infos":    func() interface{} { return &memberItem{} },
+	"news_last":        func() interface{} { return &news{} },
+	"subtitles_last":   func() interface{} { return &subtitles{} },
+	"subtitles_show":   func() interface{} { return &subtitles{} },
+}
+
+// fixtureEndpoint returns the endpoint of the fixture file 'name', as
+// written by fixtureName.
+func fixtureEndpoint(name string) string {
+	name = strings.TrimSuffix(name, ".json")
+	if i := strings.Index(name, "__"); i >= 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+// unmodeledKeys returns the dotted paths of the keys of 'body' that are lost
+// once decoded into 'v' and marshalled back, "[]" standing for the elements
+// of lists.
+func unmodeledKeys(body []byte, v interface{}) ([]string, error) {
+	if err := decodeJSON(body, v); err != nil {
+		return nil, err
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var original, marshalled interface{}
+	if err := decodeJSON(body, &original); err != nil {
+		return nil, err
+	}
+	if err := decodeJSON(out, &marshalled); err != nil {
+		return nil, err
+	}
+	missing := map[string]bool{}
+	diffKeys("", original, marshalled, missing)
+	keys := make([]string, 0, len(missing))
+	for key := range missing {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// diffKeys adds to 'missing' the paths of the keys of 'original' absent from
+// 'marshalled'. Values whose shape changed, such as the object form of the
+// genres marshalled as a list, are not compared.
+func diffKeys(path string, original, marshalled interface{}, missing map[string]bool) {
+	switch o := original.(type) {
+	case map[string]interface{}:
+		m, ok := marshalled.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for key, value := range o {
+			sub := key
+			if path != "" {
+				sub = path + "." + key
+			}
+			if _, ok := m[key]; !ok {
+				missing[sub] = true
+				continue
+			}
+			diffKeys(sub, value, m[key], missing)
+		}
+	case []interface{}:
+		m, ok := marshalled.([]interface{})
+		if !ok {
+			return
+		}
+		for i := 0; i < len(o) && i < len(m); i++ {
+			diffKeys(path+"[]", o[i], m[i], missing)
+		}
+	}
+}
+
+// readAllowlist reads the "endpoint key" lines of the allowlist, ignoring
+// blank lines and comments.
+func readAllowlist(file string) (map[string]bool, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	allowed := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: want \"endpoint key\", got %q", file, line, text)
+		}
+		allowed[fields[0]+" "+fields[1]] = true
+	}
+	return allowed, scanner.Err()
+}
+
+func (s *MySuite) TestSchemaDrift(c *C) {
+	allowed, err := readAllowlist(driftAllowlist)
+	c.Assert(err, IsNil)
+	files, err := filepath.Glob(filepath.Join(fixturesDir, "*.json"))
+	c.Assert(err, IsNil)
+	c.Assert(files, Not(HasLen), 0)
+
+	used := map[string]bool{}
+	for _, file := range files {
+		endpoint := fixtureEndpoint(filepath.Base(file))
+		newValue, ok := fixtureTypes[endpoint]
+		if !ok {
+			c.Errorf("%s: no type for endpoint %s, add it to fixtureTypes", file, endpoint)
+			continue
+		}
+		body, err := ioutil.ReadFile(file)
+		c.Assert(err, IsNil)
+		keys, err := unmodeledKeys(body, newValue())
+		if err != nil {
+			c.Errorf("%s: %v", file, err)
+			continue
+		}
+		for _, key := range keys {
+			if allowed[endpoint+" "+key] {
+				used[endpoint+" "+key] = true
+				continue
+			}
+			c.Errorf("%s: key %q is not modeled (%s), model it or add \"%s %s\" to %s",
+				endpoint, key, filepath.Base(file), endpoint, key, driftAllowlist)
+		}
+	}
+	for entry := range allowed {
+		if !used[entry] {
+			c.Errorf("%s: %q is not found in the fixtures anymore, remove it", driftAllowlist, entry)
+		}
+	}
+}
+
+func (s *MySuite) TestUnmodeledKeys(c *C) {
+	body := `{"show":{"id":1,"title":"Dexter","platform":"Netflix",` +
+		`"notes":{"total":1,"rank":3},"seasons_details":[{"number":1,"episodes":12,"image":null}],` +
+		`"genres":{"Drama":"Drame"},"aliases":{"1":"Dexter (2006)"}},"errors":[]}`
+	keys, err := unmodeledKeys([]byte(body), &showItem{})
+	c.Assert(err, IsNil)
+	c.Assert(keys, DeepEquals, []string{"show.notes.rank", "show.platform", "show.seasons_details[].image"})
+
+	c.Assert(fixtureEndpoint("shows_display__id-1161.json"), Equals, "shows_display")
+	c.Assert(fixtureEndpoint("planning_incoming.json"), Equals, "planning_incoming")
+}
@@ -0,0 +1,216 @@
+package bsclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+)
+
+var (
+	errUnknownDumpKind = newError(ErrInvalidInput, "unknown dump kind")
+)
+
+// DumpKind selects the data written by DumpJSONL.
+type DumpKind int
+
+// Kinds of dumps
+const (
+	// DumpCatalog dumps every show of the catalog, one Show per line
+	DumpCatalog DumpKind = iota + 1
+	// DumpMyShows dumps the shows of the authenticated member, one Show per
+	// line
+	DumpMyShows
+	// DumpMyEpisodes dumps the unseen episodes of the authenticated member,
+	// one Episode per line
+	DumpMyEpisodes
+)
+
+var dumpKindNames = map[DumpKind]string{
+	DumpCatalog:    "catalog",
+	DumpMyShows:    "my_shows",
+	DumpMyEpisodes: "my_episodes",
+}
+
+func (k DumpKind) String() string {
+	if name, ok := dumpKindNames[k]; ok {
+		return name
+	}
+	return "DumpKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// dumpSchema is the version of the format of the dumps, increased when the
+// lines change incompatibly.
+const dumpSchema = 1
+
+// dumpPageSize is the number of catalog shows requested at once.
+const dumpPageSize = 100
+
+// DumpHeader is the first line of a dump.
+type DumpHeader struct {
+	Schema int       `json:"schema"`
+	Kind   string    `json:"kind"`
+	Time   time.Time `json:"time"`
+}
+
+// DumpError is returned when a dump is interrupted. Written is the number of
+// entity lines written before the interruption and Resume the position to
+// give to ResumeDumpJSONL to continue the dump.
+type DumpError struct {
+	Written int
+	Resume  int
+	Err     error
+}
+
+func (e *DumpError) Error() string {
+	return "dump interrupted after " + strconv.Itoa(e.Written) + " lines: " + e.Err.Error()
+}
+
+// Unwrap returns the error which interrupted the dump.
+func (e *DumpError) Unwrap() error {
+	return e.Err
+}
+
+// DumpJSONL writes the data selected by 'what' to 'w' as JSON Lines: a
+// DumpHeader line followed by one entity per line, encoded like the
+// package types are. Lines are written as soon as their page is received,
+// and 'w' is flushed after each page if it has a Flush method.
+// If the dump is interrupted, the error is a *DumpError telling how to
+// resume it.
+func (bs *BetaSeries) DumpJSONL(ctx context.Context, w io.Writer, what DumpKind) error {
+	if _, ok := dumpKindNames[what]; !ok {
+		return errUnknownDumpKind
+	}
+	line, err := json.Marshal(DumpHeader{dumpSchema, what.String(), timeNow().UTC()})
+	if err == nil {
+		_, err = w.Write(append(line, '\n'))
+	}
+	if err != nil {
+		return &DumpError{0, 0, err}
+	}
+	return bs.ResumeDumpJSONL(ctx, w, what, 0)
+}
+
+// ResumeDumpJSONL continues an interrupted dump from the 'resume' position
+// of its *DumpError, writing the remaining lines without header.
+// The catalog is listed in alphabetical order by pages of 100 shows. Shows
+// listed again because shows were added during the dump are only written
+// once, unless the dump was interrupted meanwhile.
+func (bs *BetaSeries) ResumeDumpJSONL(ctx context.Context, w io.Writer, what DumpKind, resume int) error {
+	d := &dumper{w: w, position: resume}
+	var err error
+	switch what {
+	case DumpCatalog:
+		err = bs.dumpCatalog(ctx, d)
+	case DumpMyShows:
+		err = bs.dumpMyShows(ctx, d)
+	case DumpMyEpisodes:
+		err = bs.dumpMyEpisodes(ctx, d)
+	default:
+		return errUnknownDumpKind
+	}
+	if err != nil {
+		return &DumpError{d.written, d.position, err}
+	}
+	return nil
+}
+
+// dumper writes the lines of a dump.
+type dumper struct {
+	w       io.Writer
+	written int
+	// position is the number of entities of the listing handled
+	position int
+}
+
+// write writes the line of 'v', the next entity of the listing.
+func (d *dumper) write(v interface{}) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := d.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	d.written++
+	d.position++
+	return nil
+}
+
+// flush flushes the writer, if it buffers the lines.
+func (d *dumper) flush() error {
+	if f, ok := d.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func (bs *BetaSeries) dumpCatalog(ctx context.Context, d *dumper) error {
+	seen := map[int]bool{}
+	for {
+		shows, err := bs.showsList(ctx, "", "", "alphabetical", d.position, dumpPageSize)
+		if errors.Is(err, ErrNoShowsFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for i := range shows {
+			if seen[shows[i].ID] || bs.excluded(&shows[i]) {
+				d.position++
+				continue
+			}
+			seen[shows[i].ID] = true
+			if err := d.write(&shows[i]); err != nil {
+				return err
+			}
+		}
+		if err := d.flush(); err != nil {
+			return err
+		}
+		if len(shows) < dumpPageSize {
+			return nil
+		}
+	}
+}
+
+func (bs *BetaSeries) dumpMyShows(ctx context.Context, d *dumper) error {
+	member, err := bs.MembersInfos(ctx, 0, false, "shows")
+	if err != nil {
+		return err
+	}
+	if member != nil {
+		for i := d.position; i < len(member.Shows); i++ {
+			if err := d.write(&member.Shows[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return d.flush()
+}
+
+func (bs *BetaSeries) dumpMyEpisodes(ctx context.Context, d *dumper) error {
+	shows, err := bs.EpisodesList(ctx, 0, 0, "", 0, 0, -1, false, false)
+	if err != nil && !errors.Is(err, ErrNoShowsFound) {
+		return err
+	}
+	var episodes []Episode
+	for _, show := range shows {
+		for _, episode := range show.Unseen {
+			if episode.Show.ID == 0 {
+				episode.Show.ID = show.ID
+				episode.Show.ThetvdbID = show.ThetvdbID
+				episode.Show.Title = show.Title
+			}
+			episodes = append(episodes, episode)
+		}
+	}
+	for i := d.position; i < len(episodes); i++ {
+		if err := d.write(&episodes[i]); err != nil {
+			return err
+		}
+	}
+	return d.flush()
+}
@@ -0,0 +1,144 @@
+package bsclient
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// flushCounter counts the flushes of the lines written to it.
+type flushCounter struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (f *flushCounter) Flush() error {
+	f.flushes++
+	return nil
+}
+
+// dumpLines decodes the lines of a dump, checking each of them is valid.
+func dumpLines(c *C, dump string) []map[string]interface{} {
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(strings.NewReader(dump))
+	for scanner.Scan() {
+		line := map[string]interface{}{}
+		c.Assert(json.Unmarshal(scanner.Bytes(), &line), IsNil, Commentf(scanner.Text()))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (s *MySuite) TestDumpCatalog(c *C) {
+	defer fixedNow("2017-06-01")()
+	f := newFakeServer()
+	defer f.Close()
+	catalog := []int{}
+	for id := 1; id <= 250; id++ {
+		catalog = append(catalog, id)
+	}
+	failAt := 200
+	f.handle("GET", "/shows/list", func(w http.ResponseWriter, r *http.Request) {
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if start == failAt {
+			failAt = -1
+			writeFakeJSON(w, 502, "Bad Gateway")
+			return
+		}
+		if start == 100 {
+			// a show added at the beginning of the catalog shifts the pages
+			catalog = append([]int{1000}, catalog...)
+		}
+		shows := []string{}
+		for i := start; i < start+limit && i < len(catalog); i++ {
+			shows = append(shows, fmt.Sprintf(`{"id":%d,"title":"Show %d"}`, catalog[i], catalog[i]))
+		}
+		writeFakeJSON(w, 200, `{"shows":[`+strings.Join(shows, ",")+`],"errors":[]}`)
+	})
+	bs := f.client(c)
+
+	out := &flushCounter{}
+	err := bs.DumpJSONL(ctx, out, DumpCatalog)
+	dumpErr, ok := err.(*DumpError)
+	c.Assert(ok, Equals, true)
+	c.Assert(dumpErr.Written, Equals, 199)
+	c.Assert(dumpErr.Resume, Equals, 200)
+	c.Assert(errors.Is(err, ErrServiceUnavailable), Equals, true)
+	c.Assert(err, ErrorMatches, "dump interrupted after 199 lines: .*")
+	c.Assert(out.flushes, Equals, 2)
+
+	c.Assert(bs.ResumeDumpJSONL(ctx, out, DumpCatalog, dumpErr.Resume), IsNil)
+	lines := dumpLines(c, out.String())
+	c.Assert(lines[0], DeepEquals, map[string]interface{}{
+		"schema": float64(1), "kind": "catalog", "time": "2017-06-01T00:00:00Z"})
+	seen := map[float64]bool{}
+	for _, line := range lines[1:] {
+		id := line["id"].(float64)
+		c.Assert(seen[id], Equals, false, Commentf("show %v written twice", id))
+		seen[id] = true
+	}
+	// show 1000 was added before the pages already listed
+	c.Assert(seen, HasLen, 250)
+
+	calls := f.callsTo("/shows/list")
+	starts := []string{}
+	for _, call := range calls {
+		starts = append(starts, call.Query.Get("start"))
+		c.Assert(call.Query.Get("order"), Equals, "alphabetical")
+	}
+	c.Assert(starts, DeepEquals, []string{"", "100", "200", "200"})
+}
+
+const dumpEpisodes = `{"shows":[
+	{"id":1,"thetvdb_id":81189,"title":"Breaking Bad","unseen":[
+		{"id":11,"code":"S01E02"},{"id":12,"code":"S01E03"}]},
+	{"id":2,"title":"Lost","unseen":[{"id":21,"code":"S01E01","show":{"id":2,"title":"Lost"}}]}
+],"errors":[]}`
+
+func (s *MySuite) TestDumpMember(c *C) {
+	defer fixedNow("2017-06-01")()
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/members/infos", 200, `{"member":{"id":1,"shows":[
+		{"id":1,"title":"Breaking Bad"},{"id":2,"title":"Lost"}]},"errors":[]}`)
+	f.handleJSON("GET", "/episodes/list", 200, dumpEpisodes)
+	bs := f.client(c)
+
+	var out bytes.Buffer
+	c.Assert(bs.DumpJSONL(ctx, &out, DumpMyShows), IsNil)
+	lines := dumpLines(c, out.String())
+	c.Assert(lines, HasLen, 3)
+	c.Assert(lines[0]["kind"], Equals, "my_shows")
+	show := Show{}
+	c.Assert(json.Unmarshal(bytes.Split(out.Bytes(), []byte("\n"))[2], &show), IsNil)
+	c.Assert(show.ID, Equals, 2)
+	c.Assert(show.Title, Equals, "Lost")
+
+	out.Reset()
+	c.Assert(bs.DumpJSONL(ctx, &out, DumpMyEpisodes), IsNil)
+	lines = dumpLines(c, out.String())
+	c.Assert(lines, HasLen, 4)
+	c.Assert(lines[0]["kind"], Equals, "my_episodes")
+	c.Assert(lines[1]["id"], Equals, float64(11))
+	c.Assert(lines[1]["show"], DeepEquals, map[string]interface{}{
+		"id": float64(1), "thetvdb_id": float64(81189), "title": "Breaking Bad"})
+	c.Assert(lines[3]["id"], Equals, float64(21))
+
+	// resuming skips the lines written
+	out.Reset()
+	c.Assert(bs.ResumeDumpJSONL(ctx, &out, DumpMyEpisodes, 2), IsNil)
+	lines = dumpLines(c, out.String())
+	c.Assert(lines, HasLen, 1)
+	c.Assert(lines[0]["id"], Equals, float64(21))
+
+	c.Assert(bs.DumpJSONL(ctx, &out, DumpKind(0)), Equals, errUnknownDumpKind)
+	c.Assert(DumpKind(9).String(), Equals, "DumpKind(9)")
+}
@@ -0,0 +1,72 @@
+package bsclient
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+
+	. "gopkg.in/check.v1"
+)
+
+// envelopes returns the recorded response 'fixture' of a single item
+// endpoint in its object form, {"<key>":{...}}, and in its list form,
+// {"<key>s":[{...}]}.
+func envelopes(c *C, fixture, key string) (object, list string) {
+	body, err := ioutil.ReadFile(fixturesDir + "/" + fixture)
+	c.Assert(err, IsNil)
+	var data map[string]json.RawMessage
+	c.Assert(json.Unmarshal(body, &data), IsNil)
+	item, ok := data[key]
+	c.Assert(ok, Equals, true)
+	return string(body), `{"` + key + `s":[` + string(item) + `],"errors":[]}`
+}
+
+func (s *MySuite) TestSingleItemEnvelopes(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	bs := f.client(c)
+	showObject, showList := envelopes(c, "shows_display__id-1161.json", "show")
+	episodeObject, episodeList := envelopes(c, "episodes_display__id-225403.json", "episode")
+
+	for _, body := range []string{showObject, showList} {
+		f.handleJSON("GET", "/shows/display", 200, body)
+		show, err := bs.ShowDisplay(ctx, 1161, 0, "")
+		c.Assert(err, IsNil)
+		c.Assert(show.ID, Equals, 1161)
+		c.Assert(show.Title, Equals, "Breaking Bad")
+
+		f.handleJSON("POST", "/shows/favorite", 200, body)
+		show, err = bs.ShowFavorite(ctx, 1161)
+		c.Assert(err, IsNil)
+		c.Assert(show.ID, Equals, 1161)
+	}
+	for _, body := range []string{episodeObject, episodeList} {
+		f.handleJSON("GET", "/episodes/display", 200, body)
+		episode, err := bs.EpisodeDisplay(ctx, 225403, 0, false)
+		c.Assert(err, IsNil)
+		c.Assert(episode.ID, Equals, 225403)
+		c.Assert(episode.Show.Title, Equals, "Breaking Bad")
+
+		f.handleJSON("POST", "/episodes/watched", 200, body)
+		episode, err = bs.EpisodeWatched(ctx, 225403, 0, 0, false, false)
+		c.Assert(err, IsNil)
+		c.Assert(episode.ID, Equals, 225403)
+	}
+
+	// the entity is never nil without error
+	for _, body := range []string{`{"show":null,"errors":[]}`, `{"shows":[],"errors":[]}`,
+		`{"show":{},"errors":[]}`, `{"errors":[]}`} {
+		f.handleJSON("GET", "/shows/display", 200, body)
+		show, err := bs.ShowDisplay(ctx, 1161, 0, "")
+		c.Assert(show, IsNil)
+		c.Assert(errors.Is(err, ErrNotFound), Equals, true, Commentf(body))
+		c.Assert(err, Equals, ErrNoShowsFound)
+	}
+	for _, body := range []string{`{"episode":null,"errors":[]}`, `{"episodes":[],"errors":[]}`, `{}`} {
+		f.handleJSON("POST", "/episodes/watched", 200, body)
+		episode, err := bs.EpisodeWatched(ctx, 225403, 0, 0, false, false)
+		c.Assert(episode, IsNil)
+		c.Assert(errors.Is(err, ErrNotFound), Equals, true, Commentf(body))
+		c.Assert(err, Equals, ErrNoEpisodesFound)
+	}
+}
@@ -0,0 +1,84 @@
+package bsclient
+
+import (
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidEpisodeCode is returned by ParseEpisodeCode for codes it
+	// cannot read. It is an ErrInvalidInput.
+	ErrInvalidEpisodeCode = newError(ErrInvalidInput, "invalid episode code")
+)
+
+// maxCodeDigits is the number of digits accepted for the season and episode
+// numbers of an episode code.
+const maxCodeDigits = 4
+
+// FormatEpisodeCode returns the SxxEyy code of the episode 'episode' of the
+// season 'season', as the API writes it (S01E02).
+func FormatEpisodeCode(season, episode int) string {
+	b := make([]byte, 0, 8)
+	b = append(b, 'S')
+	b = appendCodeNumber(b, season)
+	b = append(b, 'E')
+	b = appendCodeNumber(b, episode)
+	return string(b)
+}
+
+// appendCodeNumber appends 'n' to 'b' on two digits at least.
+func appendCodeNumber(b []byte, n int) []byte {
+	if n >= 0 && n < 10 {
+		b = append(b, '0')
+	}
+	return strconv.AppendInt(b, int64(n), 10)
+}
+
+// ParseEpisodeCode returns the season and episode numbers of an episode
+// code, as written by the API or in release names: S01E02, s1e2 or 1x02.
+// Codes of several episodes (S01E02E03, S01E02-E03) return the first one.
+// Other codes return an ErrInvalidInput error.
+func ParseEpisodeCode(code string) (season, episode int, err error) {
+	s := strings.TrimSpace(code)
+	sep := byte('x')
+	if len(s) > 0 && (s[0] == 'S' || s[0] == 's') {
+		s = s[1:]
+		sep = 'e'
+	}
+	season, s, ok := codeNumber(s)
+	if !ok || len(s) == 0 || s[0]|0x20 != sep {
+		return 0, 0, ErrInvalidEpisodeCode
+	}
+	episode, s, ok = codeNumber(s[1:])
+	if !ok {
+		return 0, 0, ErrInvalidEpisodeCode
+	}
+	// another episode may follow
+	if len(s) > 0 && s[0] != '-' && s[0]|0x20 != 'e' {
+		return 0, 0, ErrInvalidEpisodeCode
+	}
+	return season, episode, nil
+}
+
+// codeNumber parses the number at the beginning of 's' and returns it with
+// the rest of 's'.
+func codeNumber(s string) (int, string, bool) {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == 0 || i > maxCodeDigits {
+		return 0, s, false
+	}
+	n, _ := strconv.Atoi(s[:i])
+	return n, s[i:], true
+}
+
+// CodeParts returns the season and episode numbers of the code of the
+// episode, or its Season and Episode fields if it has no code.
+func (e *Episode) CodeParts() (season, episode int, err error) {
+	if e.Code == "" {
+		return e.Season, e.Episode, nil
+	}
+	return ParseEpisodeCode(e.Code)
+}
@@ -0,0 +1,92 @@
+package bsclient
+
+import (
+	"math/rand"
+	"testing/quick"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestFormatEpisodeCode(c *C) {
+	c.Assert(FormatEpisodeCode(1, 2), Equals, "S01E02")
+	c.Assert(FormatEpisodeCode(0, 10), Equals, "S00E10")
+	c.Assert(FormatEpisodeCode(2017, 123), Equals, "S2017E123")
+}
+
+func (s *MySuite) TestParseEpisodeCode(c *C) {
+	for _, t := range []struct {
+		code            string
+		season, episode int
+	}{
+		{"S01E02", 1, 2},
+		{"s1e2", 1, 2},
+		{"S1E02", 1, 2},
+		{"1x02", 1, 2},
+		{"01X2", 1, 2},
+		{"S01E02E03", 1, 2},
+		{"S01E02-E03", 1, 2},
+		{"s01e02-03", 1, 2},
+		{" S00E11 ", 0, 11},
+		{"S2017E123", 2017, 123},
+	} {
+		season, episode, err := ParseEpisodeCode(t.code)
+		c.Assert(err, IsNil, Commentf("%q", t.code))
+		c.Assert([]int{season, episode}, DeepEquals, []int{t.season, t.episode}, Commentf("%q", t.code))
+	}
+	for _, code := range []string{"", "S", "SE", "S01", "S01E", "E02", "1e02", "S01x02", "1x",
+		"S01E02x", "S01E02 720p", "S-1E02", "S01E+2", "S12345E01", "S01E12345", "1x2x3"} {
+		_, _, err := ParseEpisodeCode(code)
+		c.Assert(err, Equals, ErrInvalidEpisodeCode, Commentf("%q", code))
+	}
+}
+
+func (s *MySuite) TestEpisodeCodeRoundTrip(c *C) {
+	config := &quick.Config{MaxCount: 5000, Rand: rand.New(rand.NewSource(1))}
+	roundTrip := func(season, episode uint16) bool {
+		s, e := int(season)%10000, int(episode)%10000
+		parsedSeason, parsedEpisode, err := ParseEpisodeCode(FormatEpisodeCode(s, e))
+		return err == nil && parsedSeason == s && parsedEpisode == e
+	}
+	c.Assert(quick.Check(roundTrip, config), IsNil)
+
+	// parsing never panics and only returns codes it can format back
+	parse := func(code string) bool {
+		season, episode, err := ParseEpisodeCode(code)
+		if err != nil {
+			return season == 0 && episode == 0
+		}
+		again, _, err := ParseEpisodeCode(FormatEpisodeCode(season, episode))
+		return err == nil && again == season
+	}
+	c.Assert(quick.Check(parse, config), IsNil)
+	alphabet := []byte("Ssx-Ee0123456789 ")
+	mutated := func(seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		code := make([]byte, r.Intn(12))
+		for i := range code {
+			code[i] = alphabet[r.Intn(len(alphabet))]
+		}
+		return parse(string(code))
+	}
+	c.Assert(quick.Check(mutated, config), IsNil)
+}
+
+func (s *MySuite) TestEpisodeCodeParts(c *C) {
+	episode := &Episode{Season: 3, Episode: 4}
+	season, number, err := episode.CodeParts()
+	c.Assert(err, IsNil)
+	c.Assert([]int{season, number}, DeepEquals, []int{3, 4})
+	episode.Code = "S01E02"
+	season, number, err = episode.CodeParts()
+	c.Assert(err, IsNil)
+	c.Assert([]int{season, number}, DeepEquals, []int{1, 2})
+	episode.Code = "special"
+	_, _, err = episode.CodeParts()
+	c.Assert(err, Equals, ErrInvalidEpisodeCode)
+}
+
+func (s *MySuite) BenchmarkParseEpisodeCode(c *C) {
+	for i := 0; i < c.N; i++ {
+		ParseEpisodeCode("S01E02")
+	}
+}
@@ -1,13 +1,17 @@
 package bsclient
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"net/url"
 	"strconv"
 )
 
 var (
-	errNoEpisodesFound = errors.New("no episodes found")
+	// ErrNoEpisodesFound is returned by the episode listings when no episode
+	// matches. It is an ErrNotFound.
+	ErrNoEpisodesFound = newError(ErrNotFound, "no episodes found")
 )
 
 // Episode represents the episode data returned by the betaserie API
@@ -27,11 +31,11 @@ type Episode struct {
 	Global      int    `json:"global"`
 	Special     int    `json:"special"`
 	Description string `json:"description"`
-	Date        string `json:"date"`
+	Date        Date   `json:"date"`
 	Note        struct {
 		Total int     `json:"total"`
 		Mean  float32 `json:"mean"`
-		User  int     `json:"user"`
+		User  float32 `json:"user"`
 	} `json:"note"`
 	User struct {
 		Seen       bool `json:"seen"`
@@ -39,11 +43,37 @@ type Episode struct {
 	} `json:"user"`
 	Comments  string     `json:"comments"`
 	Subtitles []Subtitle `json:"subtitles"`
+
+	raw json.RawMessage
+}
+
+// CommentsCount returns the number of comments on the episode, or 0 if the
+// count is unknown.
+func (e *Episode) CommentsCount() int {
+	count, _ := strconv.Atoi(e.Comments)
+	return count
 }
 
+// episodeItem is the response of the single episode endpoints. The episode
+// comes alone, or in a list of one episode depending on the form of the ids.
 type episodeItem struct {
-	Episode *Episode      `json:"episode"`
-	Errors  []interface{} `json:"errors"`
+	Episode  *Episode      `json:"episode"`
+	Episodes []Episode     `json:"episodes"`
+	Errors   []interface{} `json:"errors"`
+}
+
+// episode returns the episode of the response, whichever envelope holds
+// it, or ErrNoEpisodesFound if both are empty.
+func (item *episodeItem) episode() (*Episode, error) {
+	if item.Episode != nil && item.Episode.ID != 0 {
+		return item.Episode, nil
+	}
+	for i := range item.Episodes {
+		if item.Episodes[i].ID != 0 {
+			return &item.Episodes[i], nil
+		}
+	}
+	return nil, ErrNoEpisodesFound
 }
 
 type episodes struct {
@@ -51,21 +81,19 @@ type episodes struct {
 	Errors   []interface{} `json:"errors"`
 }
 
-func (bs *BetaSeries) doGetEpisodes(u *url.URL, usedAPI string) ([]Episode, error) {
-	resp, err := bs.do("GET", u)
+func (bs *BetaSeries) doGetEpisodes(ctx context.Context, u *url.URL, usedAPI string) ([]Episode, error) {
+	body, err := bs.do(ctx, "GET", u)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
 	data := &episodes{}
-	err = bs.decode(data, resp, usedAPI, u.RawQuery)
+	err = bs.decode(data, body, usedAPI, u.RawQuery)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(data.Episodes) < 1 {
-		return nil, errNoEpisodesFound
+		return nil, ErrNoEpisodesFound
 	}
 
 	return data.Episodes, nil
@@ -73,14 +101,11 @@ func (bs *BetaSeries) doGetEpisodes(u *url.URL, usedAPI string) ([]Episode, erro
 
 // episodeGet returns an episode
 // Note: scraper and list cannot be requested with this method
-func (bs *BetaSeries) episodeGet(endPoint string, id, theTvdbID int,
+func (bs *BetaSeries) episodeGet(ctx context.Context, endPoint string, id, theTvdbID int,
 	subtitles bool, number string) (*Episode, error) {
 	// endPoint can be: display, latest, next, search
 	usedAPI := "/episodes/" + endPoint
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 
 	if endPoint == "search" {
@@ -90,10 +115,11 @@ func (bs *BetaSeries) episodeGet(endPoint string, id, theTvdbID int,
 		if number != "" {
 			q.Set("number", number)
 		}
-	} else if id > 0 {
-		q.Set("id", strconv.Itoa(id))
-	} else if theTvdbID > 0 {
-		q.Set("thetvdb_id", strconv.Itoa(theTvdbID))
+	} else if id > 0 || theTvdbID > 0 {
+		err := bs.setRef(q, episodeRefParams, ShowRef{ID: id, TheTvdbID: theTvdbID})
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if subtitles {
@@ -102,64 +128,64 @@ func (bs *BetaSeries) episodeGet(endPoint string, id, theTvdbID int,
 
 	u.RawQuery = q.Encode()
 
-	resp, err := bs.do("GET", u)
+	body, err := bs.do(ctx, "GET", u)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	episode := &episodeItem{}
-	err = bs.decode(episode, resp, usedAPI, u.RawQuery)
+	item := &episodeItem{}
+	err = bs.decode(item, body, usedAPI, u.RawQuery)
 	if err != nil {
 		return nil, err
 	}
 
-	return episode.Episode, nil
+	return item.episode()
 }
 
-func (bs *BetaSeries) episodeUpdate(method, endpoint string, id, theTvdbID int) (*Episode, error) {
+func (bs *BetaSeries) episodeUpdate(ctx context.Context, method, endpoint string, id, theTvdbID int) (*Episode, error) {
 	usedAPI := "/episodes/" + endpoint
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 
-	if id > 0 {
-		q.Set("id", strconv.Itoa(id))
-	} else if theTvdbID > 0 {
-		q.Set("thetvdb_id", strconv.Itoa(theTvdbID))
+	if id > 0 || theTvdbID > 0 {
+		err := bs.setRef(q, episodeRefParams, ShowRef{ID: id, TheTvdbID: theTvdbID})
+		if err != nil {
+			return nil, err
+		}
 	}
 	u.RawQuery = q.Encode()
 
-	resp, err := bs.do(method, u)
+	before := bs.before(usedAPI, func() (interface{}, error) {
+		return bs.episodeGet(ctx, "display", id, theTvdbID, false, "")
+	})
+	body, err := bs.do(ctx, method, u)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	episode := &episodeItem{}
-	err = bs.decode(episode, resp, usedAPI, u.RawQuery)
+	item := &episodeItem{}
+	err = bs.decode(item, body, usedAPI, u.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+	episode, err := item.episode()
 	if err != nil {
 		return nil, err
 	}
+	bs.record(method, usedAPI, u, episode.ID, before, episode)
 
-	return episode.Episode, nil
+	return episode, nil
 }
 
-func (bs *BetaSeries) episodeUpdateEpisode(endPoint string, id, theTvdbID, note int, bulk, delete bool) (*Episode, error) {
+func (bs *BetaSeries) episodeUpdateEpisode(ctx context.Context, endPoint string, id, theTvdbID, note int, bulk, delete bool) (*Episode, error) {
 	method := "POST"
 	usedAPI := "/episodes/" + endPoint
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 
-	if id > 0 {
-		q.Set("id", strconv.Itoa(id))
-	} else if theTvdbID > 0 {
-		q.Set("thetvdb_id", strconv.Itoa(theTvdbID))
+	if id > 0 || theTvdbID > 0 {
+		err := bs.setRef(q, episodeRefParams, ShowRef{ID: id, TheTvdbID: theTvdbID})
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if endPoint == "watched" {
@@ -174,99 +200,284 @@ func (bs *BetaSeries) episodeUpdateEpisode(endPoint string, id, theTvdbID, note
 	}
 	u.RawQuery = q.Encode()
 
-	resp, err := bs.do(method, u)
+	before := bs.before(usedAPI, func() (interface{}, error) {
+		return bs.episodeGet(ctx, "display", id, theTvdbID, false, "")
+	})
+	body, err := bs.do(ctx, method, u)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	episode := &episodeItem{}
-	err = bs.decode(episode, resp, usedAPI, u.RawQuery)
+	item := &episodeItem{}
+	err = bs.decode(item, body, usedAPI, u.RawQuery)
 	if err != nil {
 		return nil, err
 	}
+	episode, err := item.episode()
+	if err != nil {
+		return nil, err
+	}
+	bs.record(method, usedAPI, u, episode.ID, before, episode)
 
-	return episode.Episode, nil
+	return episode, nil
 }
 
 // EpisodeScraper returns an episode from a file name
-func (bs *BetaSeries) EpisodeScraper(fileName string) (*Episode, error) {
+func (bs *BetaSeries) EpisodeScraper(ctx context.Context, fileName string) (*Episode, error) {
 	usedAPI := "/episodes/scraper"
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 	q.Set("file", fileName)
 	u.RawQuery = q.Encode()
 
-	resp, err := bs.do("GET", u)
+	body, err := bs.do(ctx, "GET", u)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	episode := &episodeItem{}
-	err = bs.decode(episode, resp, usedAPI, u.RawQuery)
+	item := &episodeItem{}
+	err = bs.decode(item, body, usedAPI, u.RawQuery)
 	if err != nil {
 		return nil, err
 	}
 
-	return episode.Episode, nil
+	return item.episode()
 }
 
 // EpisodeLatest returns the latest episode for a given show
-func (bs *BetaSeries) EpisodeLatest(showID, theTvdbShowID int) (*Episode, error) {
-	return bs.episodeGet("latest", showID, theTvdbShowID, false, "")
+func (bs *BetaSeries) EpisodeLatest(ctx context.Context, showID, theTvdbShowID int) (*Episode, error) {
+	return bs.episodeGet(ctx, "latest", showID, theTvdbShowID, false, "")
 }
 
 // EpisodeDisplay returns the latest episode for a given show
-func (bs *BetaSeries) EpisodeDisplay(showID, theTvdbShowID int, subtitles bool) (*Episode, error) {
-	return bs.episodeGet("display", showID, theTvdbShowID, subtitles, "")
+// A response without episode is reported with ErrNoEpisodesFound.
+func (bs *BetaSeries) EpisodeDisplay(ctx context.Context, showID, theTvdbShowID int, subtitles bool) (*Episode, error) {
+	return bs.episodeGet(ctx, "display", showID, theTvdbShowID, subtitles, "")
 }
 
 // EpisodeNext returns the next episode for a given show
-func (bs *BetaSeries) EpisodeNext(showID, theTvdbShowID int) (*Episode, error) {
-	return bs.episodeGet("next", showID, theTvdbShowID, false, "")
+func (bs *BetaSeries) EpisodeNext(ctx context.Context, showID, theTvdbShowID int) (*Episode, error) {
+	return bs.episodeGet(ctx, "next", showID, theTvdbShowID, false, "")
 }
 
 // EpisodeSearch returns an episode for a given show based on its number
-func (bs *BetaSeries) EpisodeSearch(showID int, subtitles bool, number string) (*Episode, error) {
-	return bs.episodeGet("search", showID, 0, subtitles, number)
+func (bs *BetaSeries) EpisodeSearch(ctx context.Context, showID int, subtitles bool, number string) (*Episode, error) {
+	return bs.episodeGet(ctx, "search", showID, 0, subtitles, number)
 }
 
 // EpisodeDownloaded marks the episode with the given id as downloaded.
-func (bs *BetaSeries) EpisodeDownloaded(bsID, theTvdbID int) (*Episode, error) {
-	return bs.episodeUpdate("POST", "downloaded", bsID, theTvdbID)
+func (bs *BetaSeries) EpisodeDownloaded(ctx context.Context, bsID, theTvdbID int) (*Episode, error) {
+	return bs.episodeUpdate(ctx, "POST", "downloaded", bsID, theTvdbID)
 }
 
 // EpisodeNotDownloaded marks the episode with the given id as not downloaded.
-func (bs *BetaSeries) EpisodeNotDownloaded(bsID, theTvdbID int) (*Episode, error) {
-	return bs.episodeUpdate("DELETE", "downloaded", bsID, theTvdbID)
+func (bs *BetaSeries) EpisodeNotDownloaded(ctx context.Context, bsID, theTvdbID int) (*Episode, error) {
+	return bs.episodeUpdate(ctx, "DELETE", "downloaded", bsID, theTvdbID)
 }
 
 // EpisodeWatched marks the episode with the given id as watched.
 // 'note' is optional (unset if equal to 0)
 // If bulk is true, all previous episodes are marked as watched.
 // If delete is true, latest episodes are not marked as watched.
-func (bs *BetaSeries) EpisodeWatched(bsID, theTvdbID, note int, bulk, delete bool) (*Episode, error) {
-	return bs.episodeUpdateEpisode("watched", bsID, theTvdbID, note, bulk, delete)
+func (bs *BetaSeries) EpisodeWatched(ctx context.Context, bsID, theTvdbID, note int, bulk, delete bool) (*Episode, error) {
+	if note != 0 && !validNote(note) {
+		return nil, ErrInvalidNote
+	}
+	return bs.episodeUpdateEpisode(ctx, "watched", bsID, theTvdbID, note, bulk, delete)
 }
 
 // EpisodeNotWatched marks the episode with the given id as not watched.
-func (bs *BetaSeries) EpisodeNotWatched(bsID, theTvdbID int) (*Episode, error) {
-	return bs.episodeUpdate("DELETE", "watched", bsID, theTvdbID)
+func (bs *BetaSeries) EpisodeNotWatched(ctx context.Context, bsID, theTvdbID int) (*Episode, error) {
+	return bs.episodeUpdate(ctx, "DELETE", "watched", bsID, theTvdbID)
 }
 
 // EpisodeNote sets the note (rating) for the given episode.
-func (bs *BetaSeries) EpisodeNote(bsID, theTvdbID, note int) (*Episode, error) {
-	if note < 1 || note > 5 {
-		return nil, errInvalidNote
+// It returns ErrInvalidNote without calling the API if 'note' is not
+// within 1 to 5.
+func (bs *BetaSeries) EpisodeNote(ctx context.Context, bsID, theTvdbID, note int) (*Episode, error) {
+	if !validNote(note) {
+		return nil, ErrInvalidNote
 	}
-	return bs.episodeUpdateEpisode("note", bsID, theTvdbID, note, false, false)
+	return bs.episodeUpdateEpisode(ctx, "note", bsID, theTvdbID, note, false, false)
 }
 
 // EpisodeNoteRemove deletes the current note for the given episode.
-func (bs *BetaSeries) EpisodeNoteRemove(bsID, theTvdbID int) (*Episode, error) {
-	return bs.episodeUpdate("DELETE", "note", bsID, theTvdbID)
+func (bs *BetaSeries) EpisodeNoteRemove(ctx context.Context, bsID, theTvdbID int) (*Episode, error) {
+	return bs.episodeUpdate(ctx, "DELETE", "note", bsID, theTvdbID)
+}
+
+// AbsoluteNumbers maps the absolute numbers of the given episodes of a show,
+// as used by anime releases, to the episodes. Specials do not take part in
+// absolute numbering. The numbers given by the API are used when every
+// episode has one, otherwise episodes are numbered in season and episode order.
+func AbsoluteNumbers(episodes []Episode) map[int]*Episode {
+	regular := []Episode{}
+	fromAPI := true
+	for _, episode := range episodes {
+		if !counted(&episode) {
+			continue
+		}
+		regular = append(regular, episode)
+		fromAPI = fromAPI && episode.Global > 0
+	}
+	SortEpisodes(regular, EpisodesByCode)
+	numbers := map[int]*Episode{}
+	for i := range regular {
+		n := i + 1
+		if fromAPI {
+			n = regular[i].Global
+		}
+		numbers[n] = &regular[i]
+	}
+	return numbers
+}
+
+// CodeFromAbsolute returns the SxxEyy code of the episode with absolute
+// number 'n' among the episodes of a show.
+func CodeFromAbsolute(episodes []Episode, n int) (string, error) {
+	episode, ok := AbsoluteNumbers(episodes)[n]
+	if !ok {
+		return "", ErrNoEpisodesFound
+	}
+	if episode.Code != "" {
+		return episode.Code, nil
+	}
+	return FormatEpisodeCode(episode.Season, episode.Episode), nil
+}
+
+// EpisodeByAbsoluteNumber returns the episode of the show 'ref' with the
+// absolute number 'n', fetching all the episodes of the show.
+func (bs *BetaSeries) EpisodeByAbsoluteNumber(ctx context.Context, ref ShowRef, n int) (*Episode, error) {
+	episodes, err := bs.showEpisodes(ctx, ref, 0, 0, false)
+	if err != nil {
+		return nil, err
+	}
+	episode, ok := AbsoluteNumbers(episodes)[n]
+	if !ok {
+		return nil, ErrNoEpisodesFound
+	}
+	return episode, nil
+}
+
+// MarkWatchedUpTo marks the episode 'season'x'episode' of the show 'ref' as
+// watched along with all the episodes before it, in a single bulk call.
+// It returns the number of episodes up to the target (specials aside) that
+// were not seen yet, computed from the episode list fetched to resolve the
+// target before marking.
+//
+// Warning: this is the API bulk mode, it cascades to every previous
+// episode. Use MarkWatchedExact to mark only the given episodes.
+func (bs *BetaSeries) MarkWatchedUpTo(ctx context.Context, ref ShowRef, season, episode int) (int, error) {
+	episodes, err := bs.showEpisodes(ctx, ref, 0, 0, false)
+	if err != nil {
+		return 0, err
+	}
+	var target *Episode
+	for i := range episodes {
+		if episodes[i].Season == season && episodes[i].Episode == episode {
+			target = &episodes[i]
+			break
+		}
+	}
+	if target == nil {
+		return 0, ErrNoEpisodesFound
+	}
+	return bs.markWatchedUpTo(ctx, episodes, target)
+}
+
+// markWatchedUpTo marks 'target' and the episodes before it as watched with
+// a bulk call, returning the number of episodes of the list that were not
+// seen yet.
+func (bs *BetaSeries) markWatchedUpTo(ctx context.Context, episodes []Episode, target *Episode) (int, error) {
+	newlySeen := 0
+	for i := range episodes {
+		e := &episodes[i]
+		if e == target || (counted(e) && !e.User.Seen && episodeBefore(e, target)) {
+			newlySeen++
+		}
+	}
+	if target.User.Seen {
+		newlySeen--
+	}
+	_, err := bs.EpisodeWatched(ctx, target.ID, 0, 0, true, false)
+	if err != nil {
+		return 0, err
+	}
+	return newlySeen, nil
+}
+
+// episodeBefore reports whether the episode 'a' comes before 'b' in the
+// show.
+func episodeBefore(a, b *Episode) bool {
+	return a.Season < b.Season || (a.Season == b.Season && a.Episode < b.Episode)
+}
+
+// MarkWatchedExact marks exactly the episodes with the given ids as watched,
+// one call per episode with bulk disabled so that the previous episodes are
+// left untouched. It stops at the first error and returns the number of
+// episodes marked so far.
+func (bs *BetaSeries) MarkWatchedExact(ctx context.Context, ids []int) (int, error) {
+	for i, id := range ids {
+		_, err := bs.EpisodeWatched(ctx, id, 0, 0, false, false)
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(ids), nil
+}
+
+// ShowCatchUp marks every aired episode of the show 'ref' as watched, adding
+// the show to the member's account first if needed, and returns the number
+// of episodes newly marked. Specials and unaired episodes are left aside:
+// a show with no aired regular episode marks nothing.
+// If 'season' is 0, the last aired episode is marked with a bulk call which
+// cascades to the previous ones. Otherwise only the aired episodes of that
+// season are marked, one call per episode not seen yet.
+// Running it again marks nothing more and sends no watch call.
+func (bs *BetaSeries) ShowCatchUp(ctx context.Context, ref ShowRef, season int) (int, error) {
+	show, err := bs.showUpdate(ctx, "GET", "display", ref, nil)
+	if err != nil {
+		return 0, err
+	}
+	// the episodes are listed by id so that the ref is resolved only once
+	ref = ShowRef{ID: show.ID}
+	if !show.InAccount {
+		_, err = bs.showUpdate(ctx, "POST", "show", ref, nil)
+		if err != nil {
+			return 0, err
+		}
+	}
+	episodes, err := bs.showEpisodes(ctx, ref, 0, 0, false)
+	if err != nil {
+		if errors.Is(err, ErrNoEpisodesFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	if season > 0 {
+		var ids []int
+		for i := range episodes {
+			e := &episodes[i]
+			if e.Season == season && counted(e) && isAired(e) && !e.User.Seen {
+				ids = append(ids, e.ID)
+			}
+		}
+		return bs.MarkWatchedExact(ctx, ids)
+	}
+
+	var target *Episode
+	newlySeen := false
+	for i := range episodes {
+		e := &episodes[i]
+		if !counted(e) || !isAired(e) {
+			continue
+		}
+		if target == nil || episodeBefore(target, e) {
+			target = e
+		}
+		newlySeen = newlySeen || !e.User.Seen
+	}
+	if !newlySeen {
+		return 0, nil
+	}
+	return bs.markWatchedUpTo(ctx, episodes, target)
 }
@@ -1,76 +1,322 @@
 package bsclient
 
 import (
-	. "gopkg.in/check.v1"
-)
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
 
-var (
-	err2001 = errorsAPI{
-		Code: 2001,
-		Text: "Token invalide.",
-	}
+	. "gopkg.in/check.v1"
 )
 
 func (s *MySuite) TestEpisodesList(c *C) {
 	bs, key, id := makeClientAndAddShow(c)
-	shows, err := bs.EpisodesList(id, -1)
+	shows, err := bs.EpisodesList(ctx, id, 0, "", 0, 0, -1, false, false)
 	c.Assert(err, IsNil)
 	c.Assert(shows, HasLen, 1)
 
-	show, err := bs.ShowRemove(id, 0)
+	show, err := bs.ShowRemove(ctx, id, 0, "")
 	c.Assert(err, IsNil)
 	c.Assert(show.InAccount, Equals, false)
 
-	_, err = bs.EpisodesList(-1, -1)
+	_, err = bs.EpisodesList(ctx, -1, 0, "", 0, 0, -1, false, false)
 	c.Assert(err, NotNil)
-	c.Assert(err, Equals, errNoShowsFound)
+	c.Assert(err, Equals, ErrNoShowsFound)
 
 	bs, err = NewBetaseriesClient(key, "", "")
 	c.Assert(err, IsNil)
-	_, err = bs.EpisodesList(0, 0)
-	c.Assert(err, NotNil)
-	c.Assert(err, DeepEquals, &errAPI{
-		[]errorsAPI{err2001},
-	})
+	_, err = bs.EpisodesList(ctx, 0, 0, "", 0, 0, -1, false, false)
+	c.Assert(err, Equals, ErrNotAuthenticated)
 }
 
 func (s *MySuite) TestEpisodesDownloaded(c *C) {
 	bs, _, id := makeClientAndAddShow(c)
-	shows, err := bs.EpisodesList(id, -1)
+	shows, err := bs.EpisodesList(ctx, id, 0, "", 0, 0, -1, false, false)
 	c.Assert(err, IsNil)
 	c.Assert(shows, HasLen, 1)
 	c.Assert(shows[0].Unseen, HasLen, 62)
 
-	episode, err := bs.EpisodeDownloaded(shows[0].Unseen[0].ID)
+	episode, err := bs.EpisodeDownloaded(ctx, shows[0].Unseen[0].ID, 0)
 	c.Assert(err, IsNil)
 	c.Assert(episode.User.Downloaded, Equals, true)
 
-	episode, err = bs.EpisodeNotDownloaded(shows[0].Unseen[0].ID)
+	episode, err = bs.EpisodeNotDownloaded(ctx, shows[0].Unseen[0].ID, 0)
 	c.Assert(err, IsNil)
 	c.Assert(episode.User.Downloaded, Equals, false)
 
-	show, err := bs.ShowRemove(id, 0)
+	show, err := bs.ShowRemove(ctx, id, 0, "")
 	c.Assert(err, IsNil)
 	c.Assert(show.InAccount, Equals, false)
 }
 
 func (s *MySuite) TestEpisodesWatched(c *C) {
 	bs, _, id := makeClientAndAddShow(c)
-	shows, err := bs.EpisodesList(id, -1)
+	shows, err := bs.EpisodesList(ctx, id, 0, "", 0, 0, -1, false, false)
 	println("unseen:", len(shows[0].Unseen))
 	c.Assert(err, IsNil)
 	c.Assert(shows, HasLen, 1)
 	c.Assert(shows[0].Unseen, HasLen, 62)
 
-	episode, err := bs.EpisodeWatched(shows[0].Unseen[0].ID)
+	episode, err := bs.EpisodeWatched(ctx, shows[0].Unseen[0].ID, 0, 0, false, false)
 	c.Assert(err, IsNil)
 	c.Assert(episode.User.Seen, Equals, true)
 
-	episode, err = bs.EpisodeNotWatched(shows[0].Unseen[0].ID)
+	episode, err = bs.EpisodeNotWatched(ctx, shows[0].Unseen[0].ID, 0)
 	c.Assert(err, IsNil)
 	c.Assert(episode.User.Seen, Equals, false)
 
-	show, err := bs.ShowRemove(id, 0)
+	show, err := bs.ShowRemove(ctx, id, 0, "")
 	c.Assert(err, IsNil)
 	c.Assert(show.InAccount, Equals, false)
 }
+
+func (s *MySuite) TestCommentsCount(c *C) {
+	show := &Show{}
+	c.Assert(json.Unmarshal([]byte(`{"comments":"42"}`), show), IsNil)
+	c.Assert(show.CommentsCount(), Equals, 42)
+	c.Assert((&Show{}).CommentsCount(), Equals, 0)
+
+	episode := &Episode{}
+	c.Assert(json.Unmarshal([]byte(`{"comments":"7"}`), episode), IsNil)
+	c.Assert(episode.CommentsCount(), Equals, 7)
+}
+
+func (s *MySuite) TestNotes(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("POST", "/episodes/note", 200,
+		`{"episode":{"id":1,"note":{"total":2,"mean":3.5,"user":3.5}},"errors":[]}`)
+	f.handleJSON("POST", "/shows/note", 200,
+		`{"show":{"id":2,"notes":{"total":2,"mean":3.5,"user":3.5}},"errors":[]}`)
+	bs := f.client(c)
+
+	episode, err := bs.EpisodeNote(ctx, 1, 0, 4)
+	c.Assert(err, IsNil)
+	c.Assert(episode.Note.Mean, Equals, float32(3.5))
+	c.Assert(episode.Note.User, Equals, float32(3.5))
+	c.Assert(f.callsTo("/episodes/note")[0].Form.Get("note"), Equals, "4")
+
+	show, err := bs.ShowNote(ctx, 2, 0, 4)
+	c.Assert(err, IsNil)
+	c.Assert(show.Notes.Mean, Equals, float32(3.5))
+	c.Assert(show.Notes.User, Equals, float32(3.5))
+
+	for _, note := range []int{0, -1, 6} {
+		_, err = bs.EpisodeNote(ctx, 1, 0, note)
+		c.Assert(err, Equals, ErrInvalidNote)
+		_, err = bs.ShowNote(ctx, 2, 0, note)
+		c.Assert(err, Equals, ErrInvalidNote)
+	}
+	_, err = bs.EpisodeWatched(ctx, 1, 0, 6, false, false)
+	c.Assert(err, Equals, ErrInvalidNote)
+	c.Assert(f.callsTo("/episodes/note"), HasLen, 1)
+	c.Assert(f.callsTo("/shows/note"), HasLen, 1)
+	c.Assert(f.callsTo("/episodes/watched"), HasLen, 0)
+}
+
+// animeEpisodes has uneven seasons and specials outside absolute numbering.
+const animeEpisodes = `{"episodes":[
+	{"id":1,"season":1,"episode":1,"code":"S01E01","global":1},
+	{"id":2,"season":1,"episode":2,"code":"S01E02","global":2},
+	{"id":3,"season":1,"episode":3,"code":"S01E03","global":3},
+	{"id":100,"season":0,"episode":1,"code":"S00E01","global":0},
+	{"id":5,"season":2,"episode":2,"code":"S02E02","global":5},
+	{"id":4,"season":2,"episode":1,"code":"S02E01","global":4},
+	{"id":101,"season":2,"episode":3,"code":"S02E03","global":0,"special":1},
+	{"id":6,"season":2,"episode":4,"code":"S02E04","global":6},
+	{"id":7,"season":3,"episode":1,"code":"S03E01","global":7}
+],"errors":[]}`
+
+func (s *MySuite) TestAbsoluteNumbers(c *C) {
+	data := &episodes{}
+	c.Assert(json.Unmarshal([]byte(animeEpisodes), data), IsNil)
+	numbers := AbsoluteNumbers(data.Episodes)
+	c.Assert(numbers, HasLen, 7)
+	for n, episode := range numbers {
+		c.Assert(episode.ID, Equals, n)
+	}
+
+	code, err := CodeFromAbsolute(data.Episodes, 5)
+	c.Assert(err, IsNil)
+	c.Assert(code, Equals, "S02E02")
+	code, err = CodeFromAbsolute(data.Episodes, 7)
+	c.Assert(err, IsNil)
+	c.Assert(code, Equals, "S03E01")
+	_, err = CodeFromAbsolute(data.Episodes, 8)
+	c.Assert(err, Equals, ErrNoEpisodesFound)
+	_, err = CodeFromAbsolute(data.Episodes, 0)
+	c.Assert(err, Equals, ErrNoEpisodesFound)
+
+	// without numbers from the API, episodes are numbered in order
+	for i := range data.Episodes {
+		data.Episodes[i].Global = 0
+		data.Episodes[i].Code = ""
+	}
+	numbers = AbsoluteNumbers(data.Episodes)
+	c.Assert(numbers, HasLen, 7)
+	c.Assert(numbers[4].ID, Equals, 4)
+	code, err = CodeFromAbsolute(data.Episodes, 6)
+	c.Assert(err, IsNil)
+	c.Assert(code, Equals, "S02E04")
+}
+
+func (s *MySuite) TestEpisodeByAbsoluteNumber(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/episodes", 200, animeEpisodes)
+	bs := f.client(c)
+
+	episode, err := bs.EpisodeByAbsoluteNumber(ctx, ShowRef{ID: 1}, 6)
+	c.Assert(err, IsNil)
+	c.Assert(episode.Code, Equals, "S02E04")
+	_, err = bs.EpisodeByAbsoluteNumber(ctx, ShowRef{ID: 1}, 42)
+	c.Assert(err, Equals, ErrNoEpisodesFound)
+	c.Assert(f.callsTo("/shows/episodes"), HasLen, 2)
+}
+
+func (s *MySuite) TestMarkWatched(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/episodes", 200, `{"episodes":[
+		{"id":1,"season":1,"episode":1,"user":{"seen":true}},
+		{"id":2,"season":1,"episode":2},
+		{"id":100,"season":0,"episode":1},
+		{"id":3,"season":2,"episode":1},
+		{"id":4,"season":2,"episode":2},
+		{"id":5,"season":2,"episode":3}
+	],"errors":[]}`)
+	f.handleJSON("POST", "/episodes/watched", 200, `{"episode":{"id":4},"errors":[]}`)
+	bs := f.client(c)
+
+	n, err := bs.MarkWatchedUpTo(ctx, ShowRef{ID: 1}, 2, 2)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, 3)
+	calls := f.callsTo("/episodes/watched")
+	c.Assert(calls, HasLen, 1)
+	c.Assert(calls[0].Form.Get("id"), Equals, "4")
+	c.Assert(calls[0].Form.Get("bulk"), Equals, "true")
+
+	_, err = bs.MarkWatchedUpTo(ctx, ShowRef{ID: 1}, 3, 1)
+	c.Assert(err, Equals, ErrNoEpisodesFound)
+	c.Assert(f.callsTo("/episodes/watched"), HasLen, 1)
+
+	n, err = bs.MarkWatchedExact(ctx, []int{5, 3})
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, 2)
+	calls = f.callsTo("/episodes/watched")[1:]
+	c.Assert(calls, HasLen, 2)
+	for i, id := range []string{"5", "3"} {
+		c.Assert(calls[i].Form.Get("id"), Equals, id)
+		c.Assert(calls[i].Form.Get("bulk"), Equals, "false")
+	}
+
+	f.handleJSON("POST", "/episodes/watched", 400, `{"errors":[{"code":0,"text":"error"}]}`)
+	n, err = bs.MarkWatchedExact(ctx, []int{5, 3})
+	c.Assert(err, NotNil)
+	c.Assert(n, Equals, 0)
+}
+
+// catchUpServer is a fake account holding one show whose episodes can be
+// marked as watched, bulk calls cascading to the previous episodes.
+type catchUpServer struct {
+	*fakeServer
+	inAccount bool
+	episodes  []Episode
+}
+
+func newCatchUpServer(list string) *catchUpServer {
+	f := &catchUpServer{fakeServer: newFakeServer()}
+	data := &episodes{}
+	if err := json.Unmarshal([]byte(list), data); err != nil {
+		panic(err)
+	}
+	f.episodes = data.Episodes
+	f.handle("GET", "/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		writeFakeJSON(w, 200, fmt.Sprintf(`{"show":{"id":1,"in_account":%t},"errors":[]}`, f.inAccount))
+	})
+	f.handle("POST", "/shows/show", func(w http.ResponseWriter, r *http.Request) {
+		f.inAccount = true
+		writeFakeJSON(w, 200, `{"show":{"id":1,"in_account":true},"errors":[]}`)
+	})
+	f.handle("GET", "/shows/episodes", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := json.Marshal(map[string]interface{}{"episodes": f.episodes, "errors": []int{}})
+		writeFakeJSON(w, 200, string(body))
+	})
+	f.handle("POST", "/episodes/watched", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(r.FormValue("id"))
+		var target *Episode
+		for i := range f.episodes {
+			if f.episodes[i].ID == id {
+				target = &f.episodes[i]
+			}
+		}
+		for i := range f.episodes {
+			e := &f.episodes[i]
+			if e == target || (r.FormValue("bulk") == "true" && counted(e) && episodeBefore(e, target)) {
+				e.User.Seen = true
+			}
+		}
+		writeFakeJSON(w, 200, fmt.Sprintf(`{"episode":{"id":%d},"errors":[]}`, id))
+	})
+	return f
+}
+
+func (s *MySuite) TestShowCatchUp(c *C) {
+	defer fixedNow("2017-06-01")()
+	f := newCatchUpServer(`{"episodes":[
+		{"id":1,"season":1,"episode":1,"date":"2017-01-01","user":{"seen":true}},
+		{"id":2,"season":1,"episode":2,"date":"2017-01-08"},
+		{"id":3,"season":1,"episode":3,"date":"2017-01-15"},
+		{"id":100,"season":0,"episode":1,"date":"2017-02-01"},
+		{"id":4,"season":2,"episode":1,"date":"2017-05-01"},
+		{"id":5,"season":2,"episode":2,"date":"2017-05-08"},
+		{"id":6,"season":2,"episode":3,"date":"2017-07-01"}
+	]}`)
+	defer f.Close()
+	bs := f.client(c)
+
+	n, err := bs.ShowCatchUp(ctx, ShowRef{ID: 1}, 2)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, 2)
+	c.Assert(f.inAccount, Equals, true)
+	c.Assert(f.callsTo("/shows/show"), HasLen, 1)
+	calls := f.callsTo("/episodes/watched")
+	c.Assert(calls, HasLen, 2)
+	c.Assert(calls[0].Form.Get("bulk"), Equals, "false")
+
+	n, err = bs.ShowCatchUp(ctx, ShowRef{ID: 1}, 0)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, 2)
+	calls = f.callsTo("/episodes/watched")
+	c.Assert(calls, HasLen, 3)
+	c.Assert(calls[2].Form.Get("id"), Equals, "5")
+	c.Assert(calls[2].Form.Get("bulk"), Equals, "true")
+	c.Assert(f.callsTo("/shows/show"), HasLen, 1)
+	for _, e := range f.episodes {
+		c.Assert(e.User.Seen, Equals, e.ID != 6 && e.ID != 100, Commentf("episode %d", e.ID))
+	}
+
+	// idempotent
+	n, err = bs.ShowCatchUp(ctx, ShowRef{ID: 1}, 0)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, 0)
+	n, err = bs.ShowCatchUp(ctx, ShowRef{ID: 1}, 1)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, 0)
+	c.Assert(f.callsTo("/episodes/watched"), HasLen, 3)
+}
+
+func (s *MySuite) TestShowCatchUpSpecialsOnly(c *C) {
+	defer fixedNow("2017-06-01")()
+	f := newCatchUpServer(`{"episodes":[
+		{"id":100,"season":0,"episode":1,"date":"2017-02-01"},
+		{"id":1,"season":1,"episode":1,"date":"2017-09-01"}
+	]}`)
+	defer f.Close()
+	bs := f.client(c)
+
+	n, err := bs.ShowCatchUp(ctx, ShowRef{ID: 1}, 0)
+	c.Assert(err, IsNil)
+	c.Assert(n, Equals, 0)
+	c.Assert(f.callsTo("/episodes/watched"), HasLen, 0)
+}
@@ -0,0 +1,244 @@
+package bsclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Error classes. Every error returned by the package matches at most one of
+// them with errors.Is, so that callers can handle failures without relying
+// on the more specific errors nor on error strings.
+var (
+	// ErrAuthRequired is matched when the request needs an authenticated
+	// member, or one allowed to see the requested data.
+	ErrAuthRequired = errors.New("authentication required")
+	// ErrTokenInvalid is matched by API errors telling the member token is
+	// invalid or expired: the member must log in again.
+	ErrTokenInvalid = errors.New("invalid token")
+	// ErrInvalidAPIKey is matched by API errors rejecting the API key itself.
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrNotFound is matched when the requested data does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrRateLimited is matched when the API rejects the request because too
+	// many were sent.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrPremiumRequired is matched by API errors telling the feature is
+	// reserved to premium accounts: logging in again will not help.
+	ErrPremiumRequired = errors.New("premium account required")
+	// ErrServiceUnavailable is matched when the API fails on its side, the
+	// request may succeed later.
+	ErrServiceUnavailable = errors.New("service unavailable")
+	// ErrInvalidInput is matched when the arguments of a call are rejected,
+	// before or by the API.
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrEndOfResults is matched when a paginated listing has no more results.
+	ErrEndOfResults = errors.New("end of results")
+	// ErrUnknownAPIError is matched by API errors whose code the client does
+	// not know, see the Code constants, and by the responses of unexpected
+	// HTTP statuses.
+	ErrUnknownAPIError = errors.New("unknown api error")
+)
+
+var (
+	// ErrMemberNotFound is matched by the errors of calls about another
+	// member, when that member does not exist. It is an ErrNotFound.
+	ErrMemberNotFound = newError(ErrNotFound, "member not found")
+	// ErrProfilePrivate is matched by API errors telling the requested member
+	// exists but does not share that data with the authenticated member.
+	// It is an ErrAuthRequired.
+	ErrProfilePrivate = newError(ErrAuthRequired, "member profile is private")
+)
+
+// classError is an error belonging to one of the error classes. The
+// errors of the package are classError values so that they keep their
+// identity and message while matching their class.
+type classError struct {
+	msg   string
+	class error
+	// err is the error the message was built from, if any
+	err error
+}
+
+func newError(class error, msg string) error {
+	return &classError{msg, class, nil}
+}
+
+// wrapError returns an error of the class 'class' adding 'msg' to 'err',
+// which errors.Is and errors.As still find.
+func wrapError(class error, msg string, err error) error {
+	return &classError{msg + ": " + err.Error(), class, err}
+}
+
+func (e *classError) Error() string {
+	return e.msg
+}
+
+func (e *classError) Is(target error) bool {
+	return target == e.class
+}
+
+func (e *classError) Unwrap() error {
+	return e.err
+}
+
+// HTTP statuses mapped onto the error classes, for responses without API
+// errors. Without the code telling whether a premium account is required,
+// a 403 Forbidden status asks for a member allowed to see the data.
+var apiErrorStatuses = map[int]error{
+	http.StatusBadRequest:          ErrInvalidInput,
+	http.StatusUnauthorized:        ErrTokenInvalid,
+	http.StatusForbidden:           ErrAuthRequired,
+	http.StatusNotFound:            ErrNotFound,
+	http.StatusTooManyRequests:     ErrRateLimited,
+	http.StatusInternalServerError: ErrServiceUnavailable,
+	http.StatusBadGateway:          ErrServiceUnavailable,
+	http.StatusServiceUnavailable:  ErrServiceUnavailable,
+	http.StatusGatewayTimeout:      ErrServiceUnavailable,
+}
+
+// APIError is an error returned by the API, with its numeric code (see the
+// Code constants) and its text, which depends on the locale (see SetLocale).
+// It is found with errors.As in the errors of the requests the API rejected,
+// the first one being returned when the API sent several errors.
+type APIError struct {
+	Code int    `json:"code"`
+	Text string `json:"text"`
+}
+
+func (e *APIError) Error() string {
+	return e.Text
+}
+
+// Is reports whether the code maps to the target error, so that callers can
+// use errors.Is(err, ErrTokenInvalid) and friends.
+func (e *APIError) Is(target error) bool {
+	mapped := codeClass(e.Code)
+	return mapped == target || errors.Is(mapped, target)
+}
+
+// errAPI represents the errors returned by the API
+type errAPI struct {
+	Errors []APIError `json:"errors"`
+}
+
+func (e *errAPI) Error() string {
+	out := ""
+	for _, e := range e.Errors {
+		out += fmt.Sprintf("%s\n", e.Text)
+	}
+	return out
+}
+
+// Is reports whether one of the API errors maps to the target error.
+func (e *errAPI) Is(target error) bool {
+	for i := range e.Errors {
+		if e.Errors[i].Is(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As sets a target *APIError to the first of the API errors.
+func (e *errAPI) As(target interface{}) bool {
+	apiErr, ok := target.(**APIError)
+	if !ok || len(e.Errors) == 0 {
+		return false
+	}
+	*apiErr = &e.Errors[0]
+	return true
+}
+
+// errMember wraps the API errors of calls about another member, the API
+// telling a missing member with the generic not found code.
+type errMember struct {
+	*errAPI
+}
+
+func (e *errMember) Is(target error) bool {
+	if target == ErrMemberNotFound {
+		return e.errAPI.Is(ErrNotFound)
+	}
+	return e.errAPI.Is(target)
+}
+
+func (e *errMember) Unwrap() error {
+	return e.errAPI
+}
+
+// memberError returns the error of a call about the member 'id', so that
+// it matches ErrMemberNotFound when that member does not exist.
+func memberError(id int, err error) error {
+	if apiErr, ok := err.(*errAPI); ok && id > 0 {
+		return &errMember{apiErr}
+	}
+	return err
+}
+
+// maxExcerptSize is the maximum size of the body excerpt kept by errHTTP
+const maxExcerptSize = 64
+
+// errHTTP represents a non 200 OK response holding no API error, such as
+// the pages served by proxies when the API is down. It keeps the first bytes
+// of the body to help telling what answered.
+type errHTTP struct {
+	status  int
+	excerpt string
+}
+
+func (e *errHTTP) Error() string {
+	msg := fmt.Sprintf("http error %d %s", e.status, http.StatusText(e.status))
+	if e.excerpt != "" {
+		msg += ": " + strconv.Quote(e.excerpt)
+	}
+	return msg
+}
+
+// Is reports whether the HTTP status maps to the target error class.
+func (e *errHTTP) Is(target error) bool {
+	return statusClass(e.status) == target
+}
+
+// statusClass returns the error class of a response of status 'status'
+// without API errors: the other client errors are ErrInvalidInput, the
+// other server errors ErrServiceUnavailable, and the unexpected statuses
+// ErrUnknownAPIError.
+func statusClass(status int) error {
+	if class, ok := apiErrorStatuses[status]; ok {
+		return class
+	}
+	switch {
+	case status >= 500:
+		return ErrServiceUnavailable
+	case status >= 400:
+		return ErrInvalidInput
+	}
+	return ErrUnknownAPIError
+}
+
+// excerpt returns the beginning of 'body', with its spaces collapsed and
+// without a truncated trailing rune.
+func excerpt(body []byte) string {
+	if len(body) > maxExcerptSize {
+		body = body[:maxExcerptSize]
+		for len(body) > 0 && !utf8.Valid(body) {
+			body = body[:len(body)-1]
+		}
+	}
+	return strings.Join(strings.Fields(string(body)), " ")
+}
+
+// decodeErr returns the error held by a non 200 OK response, which may not
+// be JSON at all.
+func decodeErr(status int, body []byte) error {
+	err := &errAPI{}
+	if json.Unmarshal(body, err) != nil || len(err.Errors) == 0 {
+		return &errHTTP{status, excerpt(body)}
+	}
+	return err
+}
@@ -0,0 +1,322 @@
+package bsclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+var errorClasses = []error{
+	ErrAuthRequired, ErrTokenInvalid, ErrInvalidAPIKey, ErrNotFound, ErrRateLimited,
+	ErrPremiumRequired, ErrServiceUnavailable, ErrInvalidInput, ErrEndOfResults,
+	ErrUnknownAPIError,
+}
+
+func (s *MySuite) TestErrorTaxonomy(c *C) {
+	for _, test := range []struct {
+		err   error
+		class error
+	}{
+		{errNoToken, ErrAuthRequired},
+		{errInvalidBaseURL, ErrInvalidInput},
+		{errBodyTooLarge, ErrServiceUnavailable},
+		{errTrailingData, ErrServiceUnavailable},
+		{ErrMemberNotFound, ErrNotFound},
+		{ErrProfilePrivate, ErrAuthRequired},
+		{ErrNoEpisodesFound, ErrNotFound},
+		{ErrNoMembersFound, ErrNotFound},
+		{ErrNoNewsFound, ErrNotFound},
+		{ErrNoSubtitlesFound, ErrNotFound},
+		{ErrNoShowsFound, ErrNotFound},
+		{ErrNoCharactersFound, ErrNotFound},
+		{ErrNoVideosFound, ErrNotFound},
+		{ErrIteratorDone, ErrEndOfResults},
+		{ErrIDMustBeStrictlyPositive, ErrInvalidInput},
+		{ErrIDNotProperlySet, ErrInvalidInput},
+		{ErrInvalidEpisodeCode, ErrInvalidInput},
+		{wrapError(ErrInvalidInput, "invalid file", errors.New("eof")), ErrInvalidInput},
+		{ErrAmbiguousRef, ErrInvalidInput},
+		{ErrInvalidImdbID, ErrInvalidInput},
+		{ErrInvalidNote, ErrInvalidInput},
+		{ErrEpisodeShowMismatch, ErrInvalidInput},
+		{errUnsupportedSource, ErrInvalidInput},
+		{errEmptyActorName, ErrInvalidInput},
+		{ErrAmbiguousTitle, ErrInvalidInput},
+		{&AmbiguousShowError{}, ErrInvalidInput},
+		{&errAPI{Errors: []APIError{{CodeInvalidAPIKey, ""}}}, ErrInvalidAPIKey},
+		{&errAPI{Errors: []APIError{{CodeDisabledAPIKey, ""}}}, ErrInvalidAPIKey},
+		{&errAPI{Errors: []APIError{{CodeInvalidToken, ""}}}, ErrTokenInvalid},
+		{&errAPI{Errors: []APIError{{CodePrivateProfile, ""}}}, ErrAuthRequired},
+		{&errAPI{Errors: []APIError{{CodePremiumRequired, ""}}}, ErrPremiumRequired},
+		{&errAPI{Errors: []APIError{{CodeNotFound, ""}}}, ErrNotFound},
+		{&errMember{&errAPI{Errors: []APIError{{CodeNotFound, ""}}}}, ErrNotFound},
+		{&errMember{&errAPI{Errors: []APIError{{CodePrivateProfile, ""}}}}, ErrAuthRequired},
+		{&errOAuth{&errAPI{Errors: []APIError{{CodeInvalidToken, ""}}}}, ErrAuthRequired},
+		{&errOAuth{&errAPI{Errors: []APIError{{3001, ""}}}}, ErrAuthRequired},
+		{&errHTTP{status: 404}, ErrNotFound},
+		{&errHTTP{status: 429}, ErrRateLimited},
+		{&errHTTP{status: 500}, ErrServiceUnavailable},
+		{&errHTTP{status: 502}, ErrServiceUnavailable},
+		{&errHTTP{status: 503}, ErrServiceUnavailable},
+		{&errHTTP{status: 504}, ErrServiceUnavailable},
+		{&errHTTP{status: 400}, ErrInvalidInput},
+		{&errHTTP{status: 401}, ErrTokenInvalid},
+		{&errHTTP{status: 403}, ErrAuthRequired},
+		{&errHTTP{status: 409}, ErrInvalidInput},
+		{&errHTTP{status: 501}, ErrServiceUnavailable},
+		{&errHTTP{status: 302}, ErrUnknownAPIError},
+		{&errAPI{Errors: []APIError{{3001, ""}}}, ErrUnknownAPIError},
+	} {
+		for _, class := range errorClasses {
+			c.Assert(errors.Is(test.err, class), Equals, class == test.class,
+				Commentf("%q, class %q", test.err, class))
+		}
+	}
+}
+
+func (s *MySuite) TestErrorIdentity(c *C) {
+	// the errors keep their identity and message
+	c.Assert(ErrNoShowsFound, Equals, ErrNoShowsFound)
+	c.Assert(ErrNoShowsFound, Not(Equals), ErrNoEpisodesFound)
+	c.Assert(ErrNoShowsFound.Error(), Equals, "no shows found")
+	c.Assert(errors.Is(ErrNoShowsFound, ErrNoShowsFound), Equals, true)
+	c.Assert(errors.Is(ErrNoShowsFound, ErrNoEpisodesFound), Equals, false)
+
+	c.Assert((&errHTTP{status: 503}).Error(), Equals, "http error 503 Service Unavailable")
+
+	// wrapped errors are still found
+	cause := &errHTTP{status: 503}
+	err := wrapError(ErrInvalidInput, "invalid file", cause)
+	c.Assert(err.Error(), Equals, "invalid file: http error 503 Service Unavailable")
+	var httpErr *errHTTP
+	c.Assert(errors.As(err, &httpErr), Equals, true)
+	c.Assert(httpErr, Equals, cause)
+	c.Assert(errors.Is(fmt.Errorf("search: %w", ErrNoShowsFound), ErrNoShowsFound), Equals, true)
+}
+
+// TestEmptyResults checks that the listings report empty results with their
+// "no ... found" error, which is an ErrNotFound, and network failures with
+// other errors.
+func (s *MySuite) TestEmptyResults(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	bs := f.client(c)
+	for _, test := range []struct {
+		method, path, body string
+		call               func() error
+		err                error
+	}{
+		{"GET", "/shows/search", `{"shows":[],"errors":[]}`, func() error {
+			_, err := bs.ShowsSearch(ctx, "nothing", "", false)
+			return err
+		}, ErrNoShowsFound},
+		{"GET", "/shows/list", `{"shows":[],"errors":[]}`, func() error {
+			_, err := bs.ShowsList(ctx, "", "", "", 0, 0)
+			return err
+		}, ErrNoShowsFound},
+		{"GET", "/shows/random", `{"shows":[],"errors":[]}`, func() error {
+			_, err := bs.ShowsRandom(ctx, 1, false)
+			return err
+		}, ErrNoShowsFound},
+		{"GET", "/shows/similars", `{"similars":[],"errors":[]}`, func() error {
+			_, err := bs.ShowsSimilars(ctx, 1, 0, false)
+			return err
+		}, ErrNoShowsFound},
+		{"GET", "/shows/characters", `{"characters":[],"errors":[]}`, func() error {
+			_, err := bs.ShowsCharacters(ctx, 1, 0)
+			return err
+		}, ErrNoCharactersFound},
+		{"GET", "/shows/videos", `{"videos":[],"errors":[]}`, func() error {
+			_, err := bs.ShowsVideos(ctx, 1, 0)
+			return err
+		}, ErrNoVideosFound},
+		{"GET", "/shows/episodes", `{"episodes":[],"errors":[]}`, func() error {
+			_, err := bs.ShowsEpisodes(ctx, 1, 0, 0, 0, false)
+			return err
+		}, ErrNoEpisodesFound},
+		{"GET", "/episodes/list", `{"shows":[],"errors":[]}`, func() error {
+			_, err := bs.EpisodesList(ctx, 0, 0, "", 0, 0, 0, false, false)
+			return err
+		}, ErrNoShowsFound},
+		{"GET", "/planning/general", `{"episodes":[],"errors":[]}`, func() error {
+			_, err := bs.PlanningGeneral(ctx, "", "", 0, 0)
+			return err
+		}, ErrNoEpisodesFound},
+		{"GET", "/planning/incoming", `{"episodes":[],"errors":[]}`, func() error {
+			_, err := bs.PlanningIncoming(ctx)
+			return err
+		}, ErrNoEpisodesFound},
+		{"GET", "/members/search", `{"users":[],"errors":[]}`, func() error {
+			_, err := bs.MembersSearch(ctx, "nobody", 0)
+			return err
+		}, ErrNoMembersFound},
+		{"GET", "/friends/list", `{"users":[],"errors":[]}`, func() error {
+			_, err := bs.FriendsList(ctx, 0, false)
+			return err
+		}, ErrNoMembersFound},
+		{"GET", "/friends/requests", `{"users":[],"errors":[]}`, func() error {
+			_, err := bs.FriendsRequests(ctx, false)
+			return err
+		}, ErrNoMembersFound},
+		{"GET", "/news/last", `{"news":[],"errors":[]}`, func() error {
+			_, err := bs.NewsLast(ctx, 1, false)
+			return err
+		}, ErrNoNewsFound},
+		{"GET", "/subtitles/last", `{"subtitles":[],"errors":[]}`, func() error {
+			_, err := bs.SubtitlesLast(ctx, 1, "")
+			return err
+		}, ErrNoSubtitlesFound},
+		{"GET", "/subtitles/show", `{"subtitles":[],"errors":[]}`, func() error {
+			_, err := bs.SubtitlesShow(ctx, 1, "")
+			return err
+		}, ErrNoSubtitlesFound},
+		{"GET", "/subtitles/episode", `{"subtitles":[],"errors":[]}`, func() error {
+			_, err := bs.SubtitlesEpisode(ctx, 1, "")
+			return err
+		}, ErrNoSubtitlesFound},
+	} {
+		f.handleJSON(test.method, test.path, 200, test.body)
+		err := test.call()
+		c.Assert(err, Equals, test.err, Commentf(test.path))
+		c.Assert(errors.Is(err, ErrNotFound), Equals, true, Commentf(test.path))
+
+		f.handleJSON(test.method, test.path, 503, ``)
+		err = test.call()
+		c.Assert(errors.Is(err, test.err), Equals, false, Commentf(test.path))
+		c.Assert(errors.Is(err, ErrServiceUnavailable), Equals, true, Commentf(test.path))
+	}
+}
+
+func (s *MySuite) TestAPIError(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	bs := f.client(c)
+	for _, test := range []struct {
+		status int
+		body   string
+		codes  []int
+		class  error
+	}{
+		{400, `{"errors":[{"code":1001,"text":"Clé API invalide."}]}`, []int{CodeInvalidAPIKey}, ErrInvalidAPIKey},
+		{400, `{"errors":[{"code":2001,"text":"Token invalide."}]}`, []int{CodeInvalidToken}, ErrTokenInvalid},
+		{400, `{"errors":[{"code":2005,"text":"Compte premium requis."}]}`, []int{CodePremiumRequired}, ErrPremiumRequired},
+		{400, `{"errors":[{"code":4001,"text":"Série introuvable."}]}`, []int{CodeNotFound}, ErrNotFound},
+		// errors-only body answered with 200 OK
+		{200, `{"show":null,"errors":[{"code":4001,"text":"Série introuvable."}]}`, []int{CodeNotFound}, ErrNotFound},
+		// several errors: the first one is found with errors.As, each one
+		// with errors.Is
+		{400, `{"errors":[{"code":3001,"text":"Paramètre manquant."},{"code":2005,"text":"Compte premium requis."}]}`,
+			[]int{3001, CodePremiumRequired}, ErrPremiumRequired},
+	} {
+		f.handleJSON("GET", "/shows/display", test.status, test.body)
+		_, err := bs.ShowDisplay(ctx, 1, 0, "")
+		var apiErr *APIError
+		c.Assert(errors.As(err, &apiErr), Equals, true, Commentf(test.body))
+		c.Assert(apiErr.Code, Equals, test.codes[0])
+		c.Assert(errors.Is(err, test.class), Equals, true, Commentf(test.body))
+		c.Assert(err.(*errAPI).Errors, HasLen, len(test.codes))
+		for i, code := range test.codes {
+			c.Assert(err.(*errAPI).Errors[i].Code, Equals, code)
+		}
+	}
+
+	// the API errors are still found once wrapped
+	err := fmt.Errorf("refresh: %w", &errMember{&errAPI{Errors: []APIError{{CodePrivateProfile, "Profil privé."}}}})
+	var apiErr *APIError
+	c.Assert(errors.As(err, &apiErr), Equals, true)
+	c.Assert(*apiErr, Equals, APIError{CodePrivateProfile, "Profil privé."})
+	c.Assert(apiErr.Error(), Equals, "Profil privé.")
+	c.Assert(errors.Is(apiErr, ErrProfilePrivate), Equals, true)
+	c.Assert(errors.As(&errHTTP{status: 503}, &apiErr), Equals, false)
+}
+
+func (s *MySuite) TestErrorStatus(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/display", 503, `<html>maintenance</html>`)
+	bs := f.client(c)
+	_, err := bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(errors.Is(err, ErrServiceUnavailable), Equals, true)
+	f.handleJSON("GET", "/shows/search", 429, ``)
+	_, err = bs.ShowsSearch(ctx, "breaking bad", "", false)
+	c.Assert(errors.Is(err, ErrRateLimited), Equals, true)
+	c.Assert(err, ErrorMatches, "http error 429 Too Many Requests")
+
+	// the pages of proxies and the truncated or malformed error payloads
+	// give an error with the beginning of the body
+	f.handleJSON("GET", "/shows/display", 502, "<html>\n<head><title>502 Bad Gateway</title></head>\n"+
+		"<body><center><h1>502 Bad Gateway</h1></center><hr><center>nginx</center></body></html>")
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(errors.Is(err, ErrServiceUnavailable), Equals, true)
+	c.Assert(err.Error(), Equals, `http error 502 Bad Gateway: "<html> <head><title>502 Bad Gateway</title></head> <body><center"`)
+	f.handleJSON("GET", "/shows/display", 400, `{"errors":[{"code":4001,"te`)
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err.Error(), Equals, `http error 400 Bad Request: "{\"errors\":[{\"code\":4001,\"te"`)
+	var httpErr *errHTTP
+	c.Assert(errors.As(err, &httpErr), Equals, true)
+	c.Assert(httpErr.status, Equals, 400)
+
+	c.Assert(excerpt([]byte(strings.Repeat("é", 40))), Equals, strings.Repeat("é", 32))
+}
+
+func (s *MySuite) TestErrorLocale(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handle("GET", "/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		text := "Aucune série trouvée."
+		if r.Header.Get("Accept-Language") == "en" {
+			text = "No show found."
+		}
+		writeFakeJSON(w, 400, `{"errors":[{"code":4001,"text":"`+text+`"}]}`)
+	})
+	bs := f.client(c)
+
+	_, err := bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, ErrorMatches, "Aucune série trouvée.\n")
+	c.Assert(f.callsTo("/shows/display")[0].Header.Get("Accept-Language"), Equals, "")
+	bs.SetLocale("en")
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, ErrorMatches, "No show found.\n")
+	c.Assert(err.(*errAPI).Errors[0].Code, Equals, 4001)
+
+	c.Assert(CodeName(4001), Equals, "not_found")
+	c.Assert(CodeName(2001), Equals, "invalid_token")
+	c.Assert(CodeName(1001), Equals, "invalid_api_key")
+	c.Assert(CodeName(42), Equals, "unknown")
+}
+
+func (s *MySuite) TestAPICodes(c *C) {
+	codes := []int{CodeInvalidAPIKey, CodeDisabledAPIKey, CodeRateLimited, CodeInvalidToken,
+		CodePrivateProfile, CodeAlreadyInAccount, CodePremiumRequired, CodeNotFound,
+		CodeShowNotFound, CodeEpisodeNotFound}
+	for _, code := range codes {
+		c.Assert(CodeName(code), Not(Equals), "unknown", Commentf("code %d", code))
+		classes := 0
+		for _, class := range errorClasses {
+			if errors.Is(&APIError{Code: code}, class) {
+				classes++
+			}
+		}
+		c.Assert(classes, Equals, 1, Commentf("code %d", code))
+	}
+	// every code of the table has a constant
+	known := map[int]bool{}
+	for _, code := range codes {
+		known[code] = true
+	}
+	c.Assert(apiCodes, HasLen, len(known))
+
+	// unknown codes fall back to the generic class
+	for _, code := range []int{0, -1, 3001, 999999} {
+		c.Assert(CodeName(code), Equals, "unknown")
+		for _, class := range errorClasses {
+			c.Assert(errors.Is(&APIError{Code: code}, class), Equals, class == ErrUnknownAPIError,
+				Commentf("code %d, class %q", code, class))
+		}
+	}
+	c.Assert(errors.Is(&APIError{Code: CodeRateLimited}, ErrRateLimited), Equals, true)
+	c.Assert(errors.Is(&APIError{Code: CodeAlreadyInAccount}, ErrInvalidInput), Equals, true)
+}
@@ -0,0 +1,145 @@
+package bsclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+// entityID is the only field decoded by the existence checks.
+type entityID struct {
+	ID int `json:"id"`
+}
+
+// decodeIDs returns the ids of the entities under the 'single' or 'multiple'
+// keys of the body, along with the API errors it holds: a multiple display
+// lists the entities found and the errors of the others. Only the keys of
+// the top level object are tokenized: the other values are skipped by the
+// decoder without being decoded, and decoding stops once the ids and errors
+// are known.
+func decodeIDs(body []byte, single, multiple string) ([]int, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	var ids []int
+	var apiErr *errAPI
+	var foundIDs, foundErrors bool
+	for dec.More() && !(foundIDs && foundErrors) {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch key {
+		case "errors":
+			foundErrors = true
+			var errs []APIError
+			if err := dec.Decode(&errs); err != nil {
+				return nil, err
+			}
+			if len(errs) > 0 {
+				apiErr = &errAPI{errs}
+			}
+		case single:
+			foundIDs = true
+			var entity *entityID
+			if err := dec.Decode(&entity); err != nil {
+				return nil, err
+			}
+			if entity != nil && entity.ID > 0 {
+				ids = append(ids, entity.ID)
+			}
+		case multiple:
+			foundIDs = true
+			var entities []entityID
+			if err := dec.Decode(&entities); err != nil {
+				return nil, err
+			}
+			for _, entity := range entities {
+				ids = append(ids, entity.ID)
+			}
+		default:
+			var skipped json.RawMessage
+			if err := dec.Decode(&skipped); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if apiErr != nil {
+		return ids, apiErr
+	}
+	return ids, nil
+}
+
+// exists requests the given display API and reports whether it returns an
+// entity, alone under 'single' or in the list under 'multiple'. Not found
+// errors are reported as a missing entity.
+func (bs *BetaSeries) exists(ctx context.Context, usedAPI, single, multiple string, q url.Values) (bool, error) {
+	u := bs.endpoint(usedAPI)
+	u.RawQuery = q.Encode()
+	body, err := bs.do(ctx, "GET", u)
+	if err == nil {
+		var ids []int
+		ids, err = decodeIDs(body, single, multiple)
+		if err == nil || len(ids) > 0 {
+			return len(ids) > 0, nil
+		}
+	}
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return false, err
+}
+
+// ShowExists reports whether the show 'ref' exists. It is cheaper than
+// ShowDisplay: the summarized show is requested and only its id is decoded.
+// An unknown show is not an error.
+func (bs *BetaSeries) ShowExists(ctx context.Context, ref ShowRef) (bool, error) {
+	q := url.Values{"summary": {"true"}}
+	if err := bs.setRef(q, showRefParams, ref); err != nil {
+		return false, err
+	}
+	return bs.exists(ctx, "/shows/display", "show", "shows", q)
+}
+
+// EpisodeExists reports whether the episode 'id' exists. Only the id of the
+// episode is decoded. An unknown episode is not an error.
+func (bs *BetaSeries) EpisodeExists(ctx context.Context, id int) (bool, error) {
+	return bs.exists(ctx, "/episodes/display", "episode", "episodes", url.Values{"id": {strconv.Itoa(id)}})
+}
+
+// ShowsExist reports which of the shows 'ids' exist. The summarized shows
+// are requested by chunks of 50 ids through the multiple display of
+// shows/display: one request is sent per chunk.
+func (bs *BetaSeries) ShowsExist(ctx context.Context, ids []int) (map[int]bool, error) {
+	usedAPI := "/shows/display"
+	u := bs.endpoint(usedAPI)
+	exist := make(map[int]bool, len(ids))
+	for _, chunk := range chunkIDs(ids, displayChunkSize) {
+		for _, id := range chunk {
+			exist[id] = false
+		}
+		u.RawQuery = url.Values{"id": {joinIDs(chunk)}, "summary": {"true"}}.Encode()
+
+		body, err := bs.do(ctx, "GET", u)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found, err := decodeIDs(body, "show", "shows")
+		for _, id := range found {
+			if _, ok := exist[id]; ok {
+				exist[id] = true
+			}
+		}
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+	return exist, nil
+}
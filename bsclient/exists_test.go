@@ -0,0 +1,107 @@
+package bsclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestExists(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	showsDisplay := func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("id") {
+		case "1":
+			writeFakeJSON(w, 200, `{"show":{"id":1,"title":"Breaking Bad","seasons_details":[{"number":1}]},"errors":[]}`)
+		case "1,2,3":
+			writeFakeJSON(w, 200, `{"shows":[{"id":1,"title":"Breaking Bad"},{"id":3}],"errors":[]}`)
+		default:
+			writeFakeJSON(w, 400, `{"errors":[{"code":4001,"text":"Aucune série trouvée."}]}`)
+		}
+	}
+	f.handle("GET", "/shows/display", showsDisplay)
+	f.handleJSON("GET", "/episodes/display", 200, `{"errors":[{"code":4001,"text":"Aucun épisode trouvé."}]}`)
+	bs := f.client(c)
+
+	exists, err := bs.ShowExists(ctx, ShowRef{ID: 1})
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, true)
+	c.Assert(f.callsTo("/shows/display")[0].Query.Get("summary"), Equals, "true")
+	exists, err = bs.ShowExists(ctx, ShowRef{ID: 2})
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, false)
+	exists, err = bs.EpisodeExists(ctx, 42)
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, false)
+	c.Assert(f.callsTo("/episodes/display")[0].Query.Get("id"), Equals, "42")
+
+	// a single entity may come in the envelope of the multiple display
+	f.handleJSON("GET", "/shows/display", 200, `{"shows":[{"id":4,"title":"Lost"}],"errors":[]}`)
+	exists, err = bs.ShowExists(ctx, ShowRef{ID: 4})
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, true)
+	f.handleJSON("GET", "/episodes/display", 200, `{"episodes":[{"id":43,"title":"Pilot"}],"errors":[]}`)
+	exists, err = bs.EpisodeExists(ctx, 43)
+	c.Assert(err, IsNil)
+	c.Assert(exists, Equals, true)
+	f.handle("GET", "/shows/display", showsDisplay)
+
+	found, err := bs.ShowsExist(ctx, []int{1, 2, 3})
+	c.Assert(err, IsNil)
+	c.Assert(found, DeepEquals, map[int]bool{1: true, 2: false, 3: true})
+
+	ids := make([]int, 120)
+	for i := range ids {
+		ids[i] = i + 10
+	}
+	found, err = bs.ShowsExist(ctx, ids)
+	c.Assert(err, IsNil)
+	c.Assert(found, HasLen, 120)
+	calls := f.callsTo("/shows/display")
+	c.Assert(calls, HasLen, 7)
+	c.Assert(strings.Split(calls[4].Query.Get("id"), ","), HasLen, 50)
+	c.Assert(strings.Split(calls[6].Query.Get("id"), ","), HasLen, 20)
+
+	// other errors are reported
+	f.handleJSON("GET", "/episodes/display", 503, ``)
+	_, err = bs.EpisodeExists(ctx, 42)
+	c.Assert(err, NotNil)
+	f.handleJSON("GET", "/shows/display", 200, `{"errors":[{"code":2001,"text":"Token invalide."}]}`)
+	_, err = bs.ShowsExist(ctx, []int{1})
+	c.Assert(err, ErrorMatches, "Token invalide.\n")
+}
+
+// largeShow returns the display payload of a show with many seasons.
+func largeShow() []byte {
+	seasons := make([]string, 300)
+	for i := range seasons {
+		seasons[i] = fmt.Sprintf(`{"number":%d,"episodes":24}`, i+1)
+	}
+	description := strings.Repeat("A chemistry teacher turns to crime. ", 200)
+	return []byte(fmt.Sprintf(`{"show":{"id":1,"title":"Breaking Bad","description":%q,`+
+		`"seasons":"300","episodes":"7200","genres":{"Drama":"Drame","Crime":"Crime"},`+
+		`"seasons_details":[%s]},"errors":[]}`, description, strings.Join(seasons, ",")))
+}
+
+func (s *MySuite) BenchmarkDecodeShowDisplay(c *C) {
+	body := largeShow()
+	bs := &BetaSeries{}
+	for i := 0; i < c.N; i++ {
+		if err := bs.decode(&showItem{}, body, "/shows/display", ""); err != nil {
+			c.Fatal(err)
+		}
+	}
+}
+
+func (s *MySuite) BenchmarkDecodeShowExists(c *C) {
+	body := largeShow()
+	c.Assert(json.Valid(body), Equals, true)
+	for i := 0; i < c.N; i++ {
+		if _, err := decodeIDs(body, "show", ""); err != nil {
+			c.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package bsclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// fakeCall records a request received by the fake server.
+type fakeCall struct {
+	Method   string
+	Path     string
+	Query    url.Values
+	RawQuery string
+	Header   http.Header
+	Body     string
+	// Form holds the parameters of a form encoded body
+	Form url.Values
+}
+
+// params returns the parameters of the call: the query of a GET request,
+// the form body of the others.
+func (call fakeCall) params() url.Values {
+	if call.Method == "GET" {
+		return call.Query
+	}
+	return call.Form
+}
+
+// fakeServer is a minimal stand-in for the betaseries API used by the
+// tests that must not depend on the network.
+type fakeServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	handlers map[string]http.HandlerFunc
+	calls    []fakeCall
+	// latency delays the answers, see setLatency
+	latency time.Duration
+}
+
+func newFakeServer() *fakeServer {
+	f := &fakeServer{
+		handlers: map[string]http.HandlerFunc{},
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+func (f *fakeServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	// the handlers may read the body too
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	form := url.Values{}
+	if r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+		form, _ = url.ParseQuery(string(body))
+	}
+	f.mu.Lock()
+	f.calls = append(f.calls, fakeCall{
+		Method:   r.Method,
+		Path:     r.URL.Path,
+		Query:    r.URL.Query(),
+		RawQuery: r.URL.RawQuery,
+		Header:   r.Header,
+		Body:     string(body),
+		Form:     form,
+	})
+	h, ok := f.handlers[r.Method+" "+r.URL.Path]
+	latency := f.latency
+	f.mu.Unlock()
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if !ok {
+		writeFakeJSON(w, http.StatusNotFound,
+			fmt.Sprintf(`{"errors":[{"code":0,"text":"unhandled %s %s"}]}`, r.Method, r.URL.Path))
+		return
+	}
+	h(w, r)
+}
+
+// handle registers a handler for the given method and path.
+func (f *fakeServer) handle(method, path string, h http.HandlerFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handlers[method+" "+path] = h
+}
+
+// setLatency delays the answers of the server by 'd', unless the request is
+// canceled first.
+func (f *fakeServer) setLatency(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency = d
+}
+
+// handleJSON registers a handler always answering with the given status and body.
+func (f *fakeServer) handleJSON(method, path string, status int, body string) {
+	f.handle(method, path, func(w http.ResponseWriter, r *http.Request) {
+		writeFakeJSON(w, status, body)
+	})
+}
+
+// callsTo returns the recorded requests made to the given path.
+func (f *fakeServer) callsTo(path string) []fakeCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []fakeCall
+	for _, call := range f.calls {
+		if call.Path == path {
+			out = append(out, call)
+		}
+	}
+	return out
+}
+
+// client returns a betaseries client talking to the fake server and
+// authenticated with a dummy token.
+func (f *fakeServer) client(c *C) *BetaSeries {
+	bs, err := NewClient("key", WithBaseURL(f.URL))
+	c.Assert(err, IsNil)
+	bs.token = &token{Token: "token"}
+	return bs
+}
+
+// cancelDuring runs 'call' with a context canceled after 'after', and checks
+// that the call returns promptly with an error matching context.Canceled.
+func cancelDuring(c *C, after time.Duration, call func(ctx context.Context) error) {
+	canceled, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- call(canceled)
+	}()
+	time.Sleep(after)
+	cancel()
+	start := time.Now()
+	select {
+	case err := <-errs:
+		c.Assert(time.Since(start) < 50*time.Millisecond, Equals, true, Commentf("%s", time.Since(start)))
+		c.Assert(errors.Is(err, context.Canceled), Equals, true, Commentf("%v", err))
+	case <-time.After(time.Second):
+		c.Fatal("the call ignored the cancellation")
+	}
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprint(w, body)
+}
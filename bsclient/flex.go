@@ -0,0 +1,41 @@
+package bsclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// FlexInt is a counter the API returns either as a number or as a string
+// depending on the endpoint and the API version, such as the number of
+// followers of a show. Null, an empty string and a non-numeric string are
+// decoded as 0.
+type FlexInt int
+
+// UnmarshalJSON decodes 12, "12", "" and null.
+func (n *FlexInt) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*n = 0
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*n = FlexInt(atoi(s))
+		return nil
+	}
+	var i int
+	if err := json.Unmarshal(data, &i); err != nil {
+		return err
+	}
+	*n = FlexInt(i)
+	return nil
+}
+
+// String returns the counter in decimal.
+func (n FlexInt) String() string {
+	return strconv.Itoa(int(n))
+}
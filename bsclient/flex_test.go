@@ -0,0 +1,42 @@
+package bsclient
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestFlexInt(c *C) {
+	fields := map[string]func(*Show) FlexInt{
+		"seasons":    func(s *Show) FlexInt { return s.Seasons },
+		"episodes":   func(s *Show) FlexInt { return s.Episodes },
+		"followers":  func(s *Show) FlexInt { return s.Followers },
+		"comments":   func(s *Show) FlexInt { return s.Comments },
+		"similars":   func(s *Show) FlexInt { return s.Similars },
+		"characters": func(s *Show) FlexInt { return s.Characters },
+		"length":     func(s *Show) FlexInt { return s.Length },
+	}
+	for key, field := range fields {
+		for value, expected := range map[string]FlexInt{
+			`12`: 12, `"12"`: 12, `null`: 0, `""`: 0, `"n/a"`: 0, `0`: 0,
+		} {
+			var show Show
+			err := json.Unmarshal([]byte(`{"id":1,"`+key+`":`+value+`}`), &show)
+			c.Assert(err, IsNil, Commentf("%s: %s", key, value))
+			c.Assert(field(&show), Equals, expected, Commentf("%s: %s", key, value))
+		}
+		var show Show
+		err := json.Unmarshal([]byte(`{"`+key+`":true}`), &show)
+		c.Assert(err, NotNil, Commentf(key))
+	}
+
+	// counters are marshalled as numbers, which decode back
+	var show Show
+	c.Assert(json.Unmarshal([]byte(`{"followers":"1234","length":45}`), &show), IsNil)
+	data, err := json.Marshal(struct {
+		Followers FlexInt `json:"followers"`
+	}{show.Followers})
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, `{"followers":1234}`)
+	c.Assert(show.Followers.String(), Equals, "1234")
+}
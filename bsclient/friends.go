@@ -1,43 +1,37 @@
 package bsclient
 
 import (
-	"net/url"
+	"context"
 	"strconv"
 )
 
-func (bs *BetaSeries) friendUpdate(method, endpoint string, id int) (*Member, error) {
+func (bs *BetaSeries) friendUpdate(ctx context.Context, method, endpoint string, id int) (*Member, error) {
 	usedAPI := "/friends/" + endpoint
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 	q.Set("id", strconv.Itoa(id))
 	u.RawQuery = q.Encode()
 
-	resp, err := bs.do(method, u)
+	body, err := bs.do(ctx, method, u)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
 	friend := &memberItem{}
-	err = bs.decode(friend, resp, usedAPI, u.RawQuery)
+	err = bs.decode(friend, body, usedAPI, u.RawQuery)
 	if err != nil {
 		return nil, err
 	}
+	bs.record(method, usedAPI, u, id, nil, friend.Member)
 
 	return friend.Member, nil
 }
 
 // FriendsList lists a member's friends
 // If 'blocked' is true, return the list of blocked users (only if id not set)
-func (bs *BetaSeries) FriendsList(id int, blocked bool) ([]Member, error) {
+// An empty result is reported with ErrNoMembersFound.
+func (bs *BetaSeries) FriendsList(ctx context.Context, id int, blocked bool) ([]Member, error) {
 	usedAPI := "/friends/list"
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 	if id > 0 {
 		q.Set("id", strconv.Itoa(id))
@@ -47,42 +41,40 @@ func (bs *BetaSeries) FriendsList(id int, blocked bool) ([]Member, error) {
 	}
 	u.RawQuery = q.Encode()
 
-	return bs.doGetUsers(u, usedAPI)
+	return bs.doGetUsers(ctx, u, usedAPI)
 }
 
 // FriendsRequests returns a list of members the user has sent friendship requests to
 // If 'received' is true, returns a list of members that sent friendship requests
-func (bs *BetaSeries) FriendsRequests(received bool) ([]Member, error) {
+// An empty result is reported with ErrNoMembersFound.
+func (bs *BetaSeries) FriendsRequests(ctx context.Context, received bool) ([]Member, error) {
 	usedAPI := "/friends/requests"
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 	if received {
 		q.Set("received", "true")
 	}
 	u.RawQuery = q.Encode()
 
-	return bs.doGetUsers(u, usedAPI)
+	return bs.doGetUsers(ctx, u, usedAPI)
 }
 
 // FriendsFriend adds the member 'id' to the user account
-func (bs *BetaSeries) FriendsFriend(id int) (*Member, error) {
-	return bs.friendUpdate("POST", "friend", id)
+func (bs *BetaSeries) FriendsFriend(ctx context.Context, id int) (*Member, error) {
+	return bs.friendUpdate(ctx, "POST", "friend", id)
 }
 
 // FriendsNotFriend removes the member 'id' from the user account
-func (bs *BetaSeries) FriendsNotFriend(id int) (*Member, error) {
-	return bs.friendUpdate("DELETE", "friend", id)
+func (bs *BetaSeries) FriendsNotFriend(ctx context.Context, id int) (*Member, error) {
+	return bs.friendUpdate(ctx, "DELETE", "friend", id)
 }
 
 // FriendsBlock blocks the member 'id'
-func (bs *BetaSeries) FriendsBlock(id int) (*Member, error) {
-	return bs.friendUpdate("POST", "block", id)
+func (bs *BetaSeries) FriendsBlock(ctx context.Context, id int) (*Member, error) {
+	return bs.friendUpdate(ctx, "POST", "block", id)
 }
 
 // FriendsUnblock unblocks the member 'id'
-func (bs *BetaSeries) FriendsUnblock(id int) (*Member, error) {
-	return bs.friendUpdate("DELETE", "block", id)
+func (bs *BetaSeries) FriendsUnblock(ctx context.Context, id int) (*Member, error) {
+	return bs.friendUpdate(ctx, "DELETE", "block", id)
 }
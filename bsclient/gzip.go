@@ -0,0 +1,64 @@
+package bsclient
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// gzipMagic starts every gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// WithoutCompression disables the compression of the responses, see
+// SetCompression.
+func WithoutCompression() Option {
+	return func(o *clientOptions) {
+		o.noCompression = true
+	}
+}
+
+// SetCompression enables or disables the compression of the responses.
+// When enabled, the default, the requests are sent with "Accept-Encoding:
+// gzip" and the gzipped responses are decompressed before being decoded.
+// When disabled, the requests ask for uncompressed responses, which makes
+// the traffic readable, for instance when debugging through a proxy.
+func (bs *BetaSeries) SetCompression(enabled bool) {
+	bs.noCompression = !enabled
+}
+
+// setAcceptEncoding sets the encodings of the responses accepted by 'req'.
+// Setting it also disables the transparent decompression of the transport,
+// the responses being decompressed by responseBody.
+func (bs *BetaSeries) setAcceptEncoding(req *http.Request) {
+	if bs.noCompression {
+		req.Header.Set("Accept-Encoding", "identity")
+		return
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+}
+
+// responseBody returns the reader of the decoded body of 'resp'. A body
+// announced as gzipped but sent as is, as some proxies do, is read as is.
+func responseBody(resp *http.Response) (io.Reader, error) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return resp.Body, nil
+	}
+	r := bufio.NewReader(resp.Body)
+	magic, _ := r.Peek(len(gzipMagic))
+	if !bytes.Equal(magic, gzipMagic) {
+		return r, nil
+	}
+	return gzip.NewReader(r)
+}
+
+// readResponse returns the decoded body of 'resp', see readBody.
+func readResponse(resp *http.Response) ([]byte, error) {
+	body, err := responseBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	return readBody(body)
+}
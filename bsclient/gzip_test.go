@@ -0,0 +1,93 @@
+package bsclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// gzipped returns 'body' compressed with gzip.
+func gzipped(c *C, body string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := zw.Write([]byte(body))
+	c.Assert(err, IsNil)
+	c.Assert(zw.Close(), IsNil)
+	return buf.Bytes()
+}
+
+func (s *MySuite) TestCompression(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	shows := []string{}
+	for id := 1; id <= 100; id++ {
+		shows = append(shows, fmt.Sprintf(`{"id":%d,"title":"Show %d","network":"HBO","followers":"%d"}`,
+			id, id, 1000-id))
+	}
+	list := `{"shows":[` + strings.Join(shows, ",") + `],"errors":[]}`
+	compressed := gzipped(c, list)
+	sent := 0
+	f.handle("GET", "/shows/list", func(w http.ResponseWriter, r *http.Request) {
+		body := []byte(list)
+		if r.Header.Get("Accept-Encoding") == "gzip" {
+			w.Header().Set("Content-Encoding", "gzip")
+			body = compressed
+		}
+		sent = len(body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	})
+	bs := f.client(c)
+
+	result, err := bs.ShowsList(ctx, "", "", "", 0, 100)
+	c.Assert(err, IsNil)
+	c.Assert(result, HasLen, 100)
+	c.Assert(f.callsTo("/shows/list")[0].Header.Get("Accept-Encoding"), Equals, "gzip")
+	gzipSize := sent
+
+	bs.SetCompression(false)
+	result, err = bs.ShowsList(ctx, "", "", "", 0, 100)
+	c.Assert(err, IsNil)
+	c.Assert(result, HasLen, 100)
+	c.Assert(f.callsTo("/shows/list")[1].Header.Get("Accept-Encoding"), Equals, "identity")
+	c.Logf("shows/list of 100 shows: %d bytes gzipped, %d bytes uncompressed", gzipSize, sent)
+	c.Assert(gzipSize*5 < sent, Equals, true)
+	bs.SetCompression(true)
+
+	// a body claimed gzipped but sent as is
+	f.handle("GET", "/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		writeFakeJSON(w, 200, `{"show":{"id":1,"title":"Plain"},"errors":[]}`)
+	})
+	show, err := bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.Title, Equals, "Plain")
+
+	// gzipped errors
+	f.handle("GET", "/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(400)
+		w.Write(gzipped(c, `{"errors":[{"code":4001,"text":"Série introuvable."}]}`))
+	})
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(errors.Is(err, ErrNotFound), Equals, true)
+
+	// corrupted gzip streams fail
+	f.handle("GET", "/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(gzipped(c, `{"show":{"id":1}}`)[:12])
+	})
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, NotNil)
+
+	bs, err = NewClientFromToken("key", "token", WithBaseURL(f.URL), WithoutCompression())
+	c.Assert(err, IsNil)
+	_, err = bs.ShowsList(ctx, "", "", "", 0, 100)
+	c.Assert(err, IsNil)
+	c.Assert(sent, Equals, len(list))
+}
@@ -0,0 +1,117 @@
+package bsclient
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// redacted replaces the secrets in the requests given to the hooks
+const redacted = "REDACTED"
+
+// secretHeaders are the request headers redacted for the hooks
+var secretHeaders = []string{"X-BetaSeries-Key", "X-BetaSeries-Token"}
+
+// secretParams are the parameters redacted for the hooks, in the query or
+// the form body
+var secretParams = []string{"password", "client_secret", "code", "token"}
+
+// OnRequest sets a function called before every request sent to the API,
+// the authentication included. A nil function removes it.
+// The hook is given a copy of the request with the API key, the token and
+// the passwords redacted: changing it has no effect on the request sent.
+func (bs *BetaSeries) OnRequest(hook func(req *http.Request)) {
+	bs.onRequest = hook
+}
+
+// OnResponse sets a function called after every request sent to the API,
+// with the redacted copy of the request (see OnRequest), the response or
+// the error of the request, and the time spent until the response headers
+// were received. The hook must not read nor close the response body.
+// A nil function removes it.
+func (bs *BetaSeries) OnResponse(hook func(req *http.Request, resp *http.Response, elapsed time.Duration, err error)) {
+	bs.onResponse = hook
+}
+
+// OnDeprecated sets a function called when the API answers a request
+// flagging the API version sent as deprecated (see SetAPIVersion), with
+// the path of the endpoint (for instance "/shows/display") and the
+// version. A nil function removes it.
+func (bs *BetaSeries) OnDeprecated(hook func(endpoint, version string)) {
+	bs.onDeprecated = hook
+}
+
+// OnJournalError sets a function called with the errors of the mutation
+// journal (see SetMutationJournal): failing to write an entry or to read
+// the state of an entity before a call. The calls do not fail on those
+// errors. A nil function removes it.
+func (bs *BetaSeries) OnJournalError(hook func(err error)) {
+	bs.onJournalError = hook
+}
+
+// roundTrip sends the request with the http client, calling the hooks.
+func (bs *BetaSeries) roundTrip(req *http.Request) (*http.Response, error) {
+	onRequest, onResponse := bs.onRequest, bs.onResponse
+	if onRequest == nil && onResponse == nil {
+		return bs.httpClient.Do(req)
+	}
+	redactedReq := redactRequest(req)
+	if onRequest != nil {
+		onRequest(redactedReq)
+	}
+	start := time.Now()
+	resp, err := bs.httpClient.Do(req)
+	if onResponse != nil {
+		onResponse(redactedReq, resp, time.Since(start), err)
+	}
+	return resp, err
+}
+
+// redactRequest returns a copy of the request without its secrets.
+func redactRequest(req *http.Request) *http.Request {
+	out := req.Clone(req.Context())
+	for _, name := range secretHeaders {
+		if out.Header.Get(name) != "" {
+			out.Header.Set(name, redacted)
+		}
+	}
+	if query, changed := redactParams(out.URL.Query()); changed {
+		out.URL.RawQuery = query
+	}
+	if req.GetBody != nil && req.Header.Get("Content-Type") == formContentType {
+		// the copy shares the body of the request, which must not be read
+		form := ""
+		if body, err := req.GetBody(); err == nil {
+			raw, _ := ioutil.ReadAll(body)
+			body.Close()
+			form = string(raw)
+		}
+		if params, err := url.ParseQuery(form); err == nil {
+			if redactedForm, changed := redactParams(params); changed {
+				form = redactedForm
+			}
+		}
+		out.Body = ioutil.NopCloser(strings.NewReader(form))
+		out.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader(form)), nil
+		}
+		out.ContentLength = int64(len(form))
+	}
+	return out
+}
+
+// redactParams returns the encoding of 'params' with their secrets
+// redacted, and whether there were any.
+func redactParams(params url.Values) (string, bool) {
+	changed := false
+	for _, name := range secretParams {
+		if params.Get(name) != "" {
+			params.Set(name, redacted)
+			changed = true
+		}
+	}
+	return params.Encode(), changed
+}
@@ -0,0 +1,90 @@
+package bsclient
+
+import (
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type hookCall struct {
+	url     string
+	body    string
+	key     string
+	token   string
+	status  int
+	elapsed time.Duration
+	err     error
+}
+
+func (s *MySuite) TestHooks(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("POST", "/members/auth", 200, `{"user":{"id":1,"login":"me"},"token":"secret-token","errors":[]}`)
+	f.handleJSON("GET", "/shows/display", 200, `{"show":{"id":1},"errors":[]}`)
+	f.handleJSON("GET", "/shows/search", 503, ``)
+	bs, err := NewClient("secret-key", WithBaseURL(f.URL))
+	c.Assert(err, IsNil)
+
+	var requests, responses []hookCall
+	bs.OnRequest(func(req *http.Request) {
+		call := hookCall{url: req.URL.String(),
+			key: req.Header.Get("X-BetaSeries-Key"), token: req.Header.Get("X-BetaSeries-Token")}
+		if req.Body != nil {
+			// reading the copy leaves the body sent
+			body, _ := ioutil.ReadAll(req.Body)
+			call.body = string(body)
+		}
+		requests = append(requests, call)
+		// changing the copy has no effect
+		req.Header.Set("X-BetaSeries-Key", "changed")
+	})
+	bs.OnResponse(func(req *http.Request, resp *http.Response, elapsed time.Duration, err error) {
+		call := hookCall{url: req.URL.String(), elapsed: elapsed, err: err}
+		if resp != nil {
+			call.status = resp.StatusCode
+		}
+		responses = append(responses, call)
+	})
+
+	c.Assert(bs.retrieveToken(ctx, "me", "password"), IsNil)
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	_, err = bs.ShowsSearch(ctx, "lost", "", false)
+	c.Assert(err, NotNil)
+
+	c.Assert(requests, DeepEquals, []hookCall{
+		{url: f.URL + "/members/auth", body: "login=me&password=REDACTED", key: "REDACTED"},
+		{url: f.URL + "/shows/display?id=1", key: "REDACTED", token: "REDACTED"},
+		{url: f.URL + "/shows/search?nbpp=100&order=popularity&title=lost", key: "REDACTED", token: "REDACTED"},
+	})
+	c.Assert(responses, HasLen, 3)
+	for i, status := range []int{200, 200, 503} {
+		c.Assert(responses[i].url, Equals, requests[i].url)
+		c.Assert(responses[i].status, Equals, status)
+		c.Assert(responses[i].elapsed > 0, Equals, true)
+		c.Assert(responses[i].err, IsNil)
+	}
+	// the requests sent keep their secrets
+	for _, call := range f.calls {
+		c.Assert(call.Header.Get("X-BetaSeries-Key"), Equals, "secret-key")
+	}
+	c.Assert(f.callsTo("/members/auth")[0].Form.Get("password"), Matches, "[0-9a-f]{32}")
+	c.Assert(f.callsTo("/shows/display")[0].Header.Get("X-BetaSeries-Token"), Equals, "secret-token")
+
+	// transport errors are given to the response hook
+	f.Close()
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, NotNil)
+	c.Assert(responses, HasLen, 4)
+	c.Assert(responses[3].status, Equals, 0)
+	c.Assert(responses[3].err, NotNil)
+
+	bs.OnRequest(nil)
+	bs.OnResponse(nil)
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, NotNil)
+	c.Assert(requests, HasLen, 4)
+	c.Assert(responses, HasLen, 4)
+}
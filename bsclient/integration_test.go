@@ -0,0 +1,115 @@
+//go:build integration
+// +build integration
+
+package bsclient
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "gopkg.in/check.v1"
+)
+
+// IntegrationSuite runs read-only requests against the live API. It is built
+// with the integration tag and skipped without BS_API_KEY.
+//
+// With BS_RECORD=1, the sanitized responses are written to testdata/recorded
+// so that the fixtures can be refreshed from the real API.
+// Write tests only run when BS_LOGIN is the dedicated test account named by
+// BS_TEST_LOGIN.
+type IntegrationSuite struct {
+	bs       *BetaSeries
+	recorder *recorder
+}
+
+var _ = Suite(&IntegrationSuite{})
+
+func (s *IntegrationSuite) SetUpSuite(c *C) {
+	key := os.Getenv("BS_API_KEY")
+	if key == "" {
+		c.Skip("BS_API_KEY not set")
+	}
+	bs, err := NewBetaseriesClient(key, "", "")
+	c.Assert(err, IsNil)
+	if os.Getenv("BS_RECORD") == "1" {
+		s.recorder = &recorder{
+			dir:       filepath.Join("testdata", "recorded"),
+			transport: bs.httpClient.Transport,
+		}
+		bs.httpClient.Transport = s.recorder
+	}
+	// the client logs in lazily: log in now so that the write tests can
+	// check the account
+	if login := os.Getenv("BS_LOGIN"); login != "" {
+		c.Assert(bs.Login(ctx, login, os.Getenv("BS_PASSWORD")), IsNil)
+	}
+	s.bs = bs
+}
+
+func (s *IntegrationSuite) TearDownSuite(c *C) {
+	if s.recorder != nil {
+		c.Assert(s.recorder.err, IsNil)
+	}
+}
+
+// requireTestAccount skips the calling test unless the client is logged in
+// the dedicated test account, so that write tests never touch a real account.
+func (s *IntegrationSuite) requireTestAccount(c *C) {
+	login := os.Getenv("BS_TEST_LOGIN")
+	_, member := s.bs.TokenMember()
+	if login == "" || member == "" {
+		c.Skip("BS_TEST_LOGIN not set or not logged in")
+	}
+	if member != login {
+		c.Skip("not logged in the test account " + login)
+	}
+}
+
+func (s *IntegrationSuite) TestSearchAndDisplay(c *C) {
+	shows, err := s.bs.ShowsSearch(ctx, "breaking bad", "", false)
+	c.Assert(err, IsNil)
+	c.Assert(len(shows) > 0, Equals, true)
+
+	show, err := s.bs.ShowDisplay(ctx, shows[0].ID, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.ID, Equals, shows[0].ID)
+	c.Assert(show.Title, Not(Equals), "")
+}
+
+func (s *IntegrationSuite) TestShowsEpisodes(c *C) {
+	episodes, err := s.bs.ShowsEpisodes(ctx, 481, 0, 1, 0, false)
+	c.Assert(err, IsNil)
+	c.Assert(len(episodes) > 0, Equals, true)
+	c.Assert(episodes[0].Season, Equals, 1)
+}
+
+func (s *IntegrationSuite) TestShowsList(c *C) {
+	shows, err := s.bs.ShowsList(ctx, "", "", "popularity", 0, 10)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 10)
+}
+
+func (s *IntegrationSuite) TestPlanningGeneral(c *C) {
+	// quiet days have no episodes
+	_, err := s.bs.PlanningGeneral(ctx, "now", "", 1, 1)
+	if err != nil {
+		c.Assert(errors.Is(err, ErrNotFound), Equals, true)
+	}
+}
+
+func (s *IntegrationSuite) TestNewsLast(c *C) {
+	news, err := s.bs.NewsLast(ctx, 2, false)
+	c.Assert(err, IsNil)
+	c.Assert(len(news) <= 2, Equals, true)
+}
+
+func (s *IntegrationSuite) TestShowAddRemove(c *C) {
+	s.requireTestAccount(c)
+	show, err := s.bs.ShowAdd(ctx, 481, 0, "", 0)
+	c.Assert(err, IsNil)
+	c.Assert(show.InAccount, Equals, true)
+	show, err = s.bs.ShowRemove(ctx, 481, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.InAccount, Equals, false)
+}
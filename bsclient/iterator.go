@@ -0,0 +1,147 @@
+package bsclient
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrIteratorDone is returned by the Next method of the iterators once
+// every item was returned. It is an ErrEndOfResults.
+var ErrIteratorDone = newError(ErrEndOfResults, "no more items")
+
+// defaultPageSize is the size of the pages requested by the iterators when
+// it is not set
+const defaultPageSize = 100
+
+// pager walks through the pages of a listing, requesting them with 'fetch'
+// until a page is short or empty. It holds the state shared by the typed
+// iterators, which keep the items of the current page.
+type pager struct {
+	size  int
+	start int
+	// fetch requests the page of 'size' items from 'start', and returns the
+	// number of items of the page along with their keys
+	fetch func(ctx context.Context, start, size int) (n int, keys []string, err error)
+	// last is the signature of the keys of the last page
+	last string
+	done bool
+	err  error
+}
+
+func newPager(size int, fetch func(ctx context.Context, start, size int) (int, []string, error)) pager {
+	if size <= 0 {
+		size = defaultPageSize
+	}
+	return pager{size: size, fetch: fetch}
+}
+
+// next requests the next page, reporting whether it holds items. Once the
+// listing is over or failed, it returns false and the error which ended
+// it, ErrIteratorDone if none.
+func (p *pager) next(ctx context.Context) (bool, error) {
+	if p.err != nil {
+		return false, p.err
+	}
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	if p.done {
+		return false, ErrIteratorDone
+	}
+	n, keys, err := p.fetch(ctx, p.start, p.size)
+	if err != nil {
+		p.err = err
+		return false, err
+	}
+	// a page listed again ends the listing rather than looping over it
+	signature := strings.Join(keys, ",")
+	if n == 0 || signature != "" && signature == p.last {
+		p.done = true
+		return false, ErrIteratorDone
+	}
+	p.last = signature
+	p.start += p.size
+	if n < p.size {
+		p.done = true
+	}
+	return true, nil
+}
+
+// ShowIterator returns the shows of a listing one at a time, requesting
+// the pages as needed. It is not safe for concurrent use.
+type ShowIterator struct {
+	ctx   context.Context
+	pager pager
+	shows []Show
+}
+
+// Next returns the next show of the listing. Once every show was returned,
+// it returns ErrIteratorDone. An error requesting a page is returned after
+// the shows of the previous pages, and again by the next calls.
+// The listing stops when 'ctx' is done, with its error.
+func (it *ShowIterator) Next() (*Show, error) {
+	for len(it.shows) == 0 {
+		ok, err := it.pager.next(it.ctx)
+		if !ok {
+			return nil, err
+		}
+	}
+	if err := it.ctx.Err(); err != nil {
+		return nil, err
+	}
+	show := &it.shows[0]
+	it.shows = it.shows[1:]
+	return show, nil
+}
+
+// showsPage returns the fetch function of a pager listing the shows with
+// 'list', skipping the excluded shows.
+func (bs *BetaSeries) showsPage(it *ShowIterator,
+	list func(ctx context.Context, start, size int) ([]Show, error)) func(context.Context, int, int) (int, []string, error) {
+	return func(ctx context.Context, start, size int) (int, []string, error) {
+		shows, err := list(ctx, start, size)
+		if errors.Is(err, ErrNoShowsFound) {
+			return 0, nil, nil
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		keys := make([]string, len(shows))
+		it.shows = it.shows[:0]
+		for i := range shows {
+			keys[i] = strconv.Itoa(shows[i].ID)
+			if !bs.excluded(&shows[i]) {
+				it.shows = append(it.shows, shows[i])
+			}
+		}
+		return len(shows), keys, nil
+	}
+}
+
+// ShowsListAll returns an iterator over the shows of ShowsList, whatever
+// their number, requesting them by pages of 'pageSize' shows (100 if not
+// positive, 1000 at most) until a page is short. A page listed twice in a
+// row ends the listing.
+func (bs *BetaSeries) ShowsListAll(ctx context.Context, since, starting, order string, pageSize int) *ShowIterator {
+	if pageSize > showsListLimit.max {
+		pageSize = showsListLimit.max
+	}
+	it := &ShowIterator{ctx: ctx}
+	it.pager = newPager(pageSize, bs.showsPage(it, func(ctx context.Context, start, size int) ([]Show, error) {
+		return bs.showsList(ctx, since, starting, order, start, size)
+	}))
+	return it
+}
+
+// ShowsSearchAll returns an iterator over the shows of ShowsSearch, beyond
+// its first 100 results, requesting them by pages of 'pageSize' shows (100
+// if not positive) like ShowsListAll.
+func (bs *BetaSeries) ShowsSearchAll(ctx context.Context, query, order string, pageSize int) *ShowIterator {
+	it := &ShowIterator{ctx: ctx}
+	it.pager = newPager(pageSize, bs.showsPage(it, func(ctx context.Context, start, size int) ([]Show, error) {
+		return bs.showsSearch(ctx, query, order, false, start/size+1, size)
+	}))
+	return it
+}
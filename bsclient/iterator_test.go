@@ -0,0 +1,130 @@
+package bsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// iterate returns the ids of the shows of 'it' and the error ending it.
+func iterate(it *ShowIterator) ([]int, error) {
+	ids := []int{}
+	for {
+		show, err := it.Next()
+		if err != nil {
+			return ids, err
+		}
+		ids = append(ids, show.ID)
+	}
+}
+
+func (s *MySuite) TestShowsListAll(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	size := 250
+	failAt := -1
+	repeat := false
+	f.handle("GET", "/shows/list", func(w http.ResponseWriter, r *http.Request) {
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if start == failAt {
+			writeFakeJSON(w, 502, "Bad Gateway")
+			return
+		}
+		if repeat && start > 0 {
+			// the API ignoring the start lists the first page again
+			start = 0
+		}
+		shows := []string{}
+		for id := start + 1; id <= start+limit && id <= size; id++ {
+			shows = append(shows, fmt.Sprintf(`{"id":%d}`, id))
+		}
+		writeFakeJSON(w, 200, `{"shows":[`+strings.Join(shows, ",")+`],"errors":[]}`)
+	})
+	bs := f.client(c)
+
+	ids, err := iterate(bs.ShowsListAll(ctx, "", "", "alphabetical", 0))
+	c.Assert(err, Equals, ErrIteratorDone)
+	c.Assert(ids, HasLen, 250)
+	c.Assert(ids[249], Equals, 250)
+	c.Assert(f.callsTo("/shows/list"), HasLen, 3)
+	c.Assert(f.callsTo("/shows/list")[2].Query.Get("start"), Equals, "200")
+	c.Assert(f.callsTo("/shows/list")[2].Query.Get("limit"), Equals, "100")
+
+	// a full last page is followed by an empty one
+	size = 200
+	ids, err = iterate(bs.ShowsListAll(ctx, "", "", "", 50))
+	c.Assert(err, Equals, ErrIteratorDone)
+	c.Assert(ids, HasLen, 200)
+	c.Assert(f.callsTo("/shows/list"), HasLen, 8)
+
+	// a page listed again ends the listing
+	repeat = true
+	ids, err = iterate(bs.ShowsListAll(ctx, "", "", "", 100))
+	c.Assert(err, Equals, ErrIteratorDone)
+	c.Assert(ids, HasLen, 100)
+	repeat = false
+
+	// the shows of the pages read are returned before the error
+	failAt = 100
+	it := bs.ShowsListAll(ctx, "", "", "", 100)
+	ids, err = iterate(it)
+	c.Assert(errors.Is(err, ErrServiceUnavailable), Equals, true)
+	c.Assert(ids, HasLen, 100)
+	_, err = it.Next()
+	c.Assert(errors.Is(err, ErrServiceUnavailable), Equals, true)
+
+	// invalid parameters are reported by the first call
+	_, err = bs.ShowsListAll(ctx, "", "", "random", 100).Next()
+	c.Assert(errors.Is(err, ErrInvalidInput), Equals, true)
+
+	// the iteration stops with the context
+	failAt = -1
+	cancelled, cancel := context.WithCancel(ctx)
+	it = bs.ShowsListAll(cancelled, "", "", "", 100)
+	_, err = it.Next()
+	c.Assert(err, IsNil)
+	cancel()
+	_, err = it.Next()
+	c.Assert(err, Equals, context.Canceled)
+}
+
+func (s *MySuite) TestShowsSearchAll(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handle("GET", "/shows/search", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		nbpp, _ := strconv.Atoi(r.URL.Query().Get("nbpp"))
+		shows := []string{}
+		for id := (page-1)*nbpp + 1; id <= page*nbpp && id <= 25; id++ {
+			rating := ""
+			if id%5 == 0 {
+				rating = "TV-MA"
+			}
+			shows = append(shows, fmt.Sprintf(`{"id":%d,"rating":%q}`, id, rating))
+		}
+		writeFakeJSON(w, 200, `{"shows":[`+strings.Join(shows, ",")+`],"errors":[]}`)
+	})
+	bs := f.client(c)
+
+	ids, err := iterate(bs.ShowsSearchAll(ctx, "Show", "", 10))
+	c.Assert(err, Equals, ErrIteratorDone)
+	c.Assert(ids, HasLen, 25)
+	calls := f.callsTo("/shows/search")
+	c.Assert(calls, HasLen, 3)
+	c.Assert(calls[0].RawQuery, Equals, "nbpp=10&order=popularity&title=show")
+	c.Assert(calls[2].Query.Get("page"), Equals, "3")
+
+	bs.SetExcludeAdult(true)
+	ids, err = iterate(bs.ShowsSearchAll(ctx, "Show", "", 10))
+	c.Assert(err, Equals, ErrIteratorDone)
+	c.Assert(ids, HasLen, 20)
+}
@@ -0,0 +1,120 @@
+package bsclient
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// JournalEntry is a line of the mutation journal, recording a successful
+// call changing the account.
+type JournalEntry struct {
+	Time     time.Time `json:"time"`
+	Method   string    `json:"method"`
+	Endpoint string    `json:"endpoint"`
+	// Params are the parameters of the call, credentials excluded.
+	Params url.Values `json:"params"`
+	// ID is the id of the show, episode or member changed.
+	ID int `json:"id"`
+	// Before is the state of the entity before the call, for the calls
+	// marking episodes as watched or downloaded, archiving shows and noting
+	// shows or episodes.
+	Before interface{} `json:"before,omitempty"`
+	// After is the state of the entity returned by the API after the call.
+	After interface{} `json:"after,omitempty"`
+}
+
+// journalSecrets are the parameters never written to the journal.
+var journalSecrets = map[string]bool{
+	"password":      true,
+	"client_secret": true,
+	"token":         true,
+	"key":           true,
+}
+
+// journalBefore holds the endpoints of the calls whose entries hold the
+// state of the entity before the call, read with an extra request.
+var journalBefore = map[string]bool{
+	"/episodes/watched":    true,
+	"/episodes/downloaded": true,
+	"/episodes/note":       true,
+	"/shows/archive":       true,
+	"/shows/note":          true,
+}
+
+// mutationJournal writes the JournalEntry lines to a writer.
+type mutationJournal struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// WithMutationJournal records every successful call changing the account
+// (ShowAdd, EpisodeWatched, ShowNote, ...) to 'w' as JSON Lines, one
+// JournalEntry per call, see SetMutationJournal.
+func WithMutationJournal(w io.Writer) Option {
+	return func(o *clientOptions) {
+		o.journal = w
+	}
+}
+
+// SetMutationJournal records every successful call changing the account to
+// 'w' as JSON Lines, one JournalEntry per call, in the order the calls
+// complete. A nil writer stops the journal.
+// The calls whose entries hold the state before the call send one more
+// request to read it. Failing to write an entry, or to read the state
+// before the call, does not fail the call: the error is given to the hook
+// set with OnJournalError.
+func (bs *BetaSeries) SetMutationJournal(w io.Writer) {
+	if w == nil {
+		bs.journal = nil
+		return
+	}
+	bs.journal = &mutationJournal{w: w}
+}
+
+// before returns the state of the entity before the call to 'usedAPI', read
+// with 'read', if the journal records it.
+func (bs *BetaSeries) before(usedAPI string, read func() (interface{}, error)) interface{} {
+	if bs.journal == nil || !journalBefore[usedAPI] {
+		return nil
+	}
+	state, err := read()
+	if err != nil {
+		bs.journalError(err)
+		return nil
+	}
+	return state
+}
+
+// record writes the journal entry of the successful call to 'u' changing the
+// entity 'id', if the journal is enabled.
+func (bs *BetaSeries) record(method, usedAPI string, u *url.URL, id int, before, after interface{}) {
+	journal := bs.journal
+	if journal == nil {
+		return
+	}
+	params := u.Query()
+	for name := range params {
+		if journalSecrets[name] {
+			params.Del(name)
+		}
+	}
+	line, err := json.Marshal(&JournalEntry{timeNow().UTC(), method, usedAPI, params, id, before, after})
+	if err == nil {
+		journal.mu.Lock()
+		_, err = journal.w.Write(append(line, '\n'))
+		journal.mu.Unlock()
+	}
+	if err != nil {
+		bs.journalError(err)
+	}
+}
+
+// journalError gives 'err' to the hook set with OnJournalError, if any.
+func (bs *BetaSeries) journalError(err error) {
+	if hook := bs.onJournalError; hook != nil {
+		hook(err)
+	}
+}
@@ -0,0 +1,123 @@
+package bsclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func (s *MySuite) TestMutationJournal(c *C) {
+	defer fixedNow("2017-06-01")()
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("POST", "/shows/show", 200, `{"show":{"id":1,"title":"Dexter"},"errors":[]}`)
+	f.handleJSON("POST", "/episodes/watched", 200, `{"episode":{"id":10,"code":"S01E01","user":{"seen":true}},"errors":[]}`)
+	f.handleJSON("POST", "/shows/note", 200, `{"show":{"id":1,"title":"Dexter"},"errors":[]}`)
+	f.handleJSON("GET", "/shows/display", 200, `{"show":{"id":1,"title":"Dexter","notes":{"user":3}},"errors":[]}`)
+	f.handleJSON("GET", "/episodes/display", 200, `{"episode":{"id":10,"code":"S01E01","user":{"seen":false}},"errors":[]}`)
+	f.handleJSON("DELETE", "/shows/show", 400, `{"errors":[{"code":4001,"text":"Série introuvable."}]}`)
+	bs := f.client(c)
+	var journalErrs []error
+	bs.OnJournalError(func(err error) {
+		journalErrs = append(journalErrs, err)
+	})
+
+	// no journal by default, nor request of the state before the calls
+	_, err := bs.ShowNote(ctx, 1, 0, 5)
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/shows/display"), HasLen, 0)
+
+	out := &bytes.Buffer{}
+	bs.SetMutationJournal(out)
+	_, err = bs.ShowAdd(ctx, 1, 0, "", 0)
+	c.Assert(err, IsNil)
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	_, err = bs.EpisodeWatched(ctx, 10, 0, 4, false, false)
+	c.Assert(err, IsNil)
+	_, err = bs.ShowNote(ctx, 1, 0, 5)
+	c.Assert(err, IsNil)
+	// failed calls are not recorded
+	_, err = bs.ShowRemove(ctx, 2, 0, "")
+	c.Assert(err, NotNil)
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	c.Assert(lines, HasLen, 3)
+	entries := make([]map[string]interface{}, len(lines))
+	for i, line := range lines {
+		c.Assert(json.Unmarshal([]byte(line), &entries[i]), IsNil)
+		c.Assert(entries[i]["time"], Equals, "2017-06-01T00:00:00Z")
+		c.Assert(strings.Contains(line, "token"), Equals, false)
+	}
+	c.Assert(entries[0]["method"], Equals, "POST")
+	c.Assert(entries[0]["endpoint"], Equals, "/shows/show")
+	c.Assert(entries[0]["params"], DeepEquals, map[string]interface{}{"id": []interface{}{"1"}})
+	c.Assert(entries[0]["id"], Equals, float64(1))
+	c.Assert(entries[0]["after"].(map[string]interface{})["title"], Equals, "Dexter")
+	// adding a show has no state before
+	_, ok := entries[0]["before"]
+	c.Assert(ok, Equals, false)
+	c.Assert(entries[1]["endpoint"], Equals, "/episodes/watched")
+	c.Assert(entries[1]["params"], DeepEquals, map[string]interface{}{
+		"id": []interface{}{"10"}, "bulk": []interface{}{"false"}, "note": []interface{}{"4"}})
+	c.Assert(entries[1]["id"], Equals, float64(10))
+	c.Assert(entries[1]["before"].(map[string]interface{})["user"].(map[string]interface{})["seen"], Equals, false)
+	c.Assert(entries[1]["after"].(map[string]interface{})["user"].(map[string]interface{})["seen"], Equals, true)
+	c.Assert(entries[2]["endpoint"], Equals, "/shows/note")
+	c.Assert(entries[2]["params"], DeepEquals, map[string]interface{}{
+		"id": []interface{}{"1"}, "note": []interface{}{"5"}})
+	c.Assert(entries[2]["before"].(map[string]interface{})["notes"].(map[string]interface{})["user"], Equals, float64(3))
+	c.Assert(f.callsTo("/episodes/display"), HasLen, 1)
+	c.Assert(f.callsTo("/shows/display"), HasLen, 2)
+	c.Assert(journalErrs, HasLen, 0)
+
+	// failing to read the state before does not fail the call
+	f.handleJSON("GET", "/shows/display", 503, ``)
+	f.handleJSON("POST", "/shows/archive", 200, `{"show":{"id":1,"title":"Dexter"},"errors":[]}`)
+	out.Reset()
+	_, err = bs.ShowArchive(ctx, 1, 0)
+	c.Assert(err, IsNil)
+	c.Assert(journalErrs, HasLen, 1)
+	c.Assert(errors.Is(journalErrs[0], ErrServiceUnavailable), Equals, true)
+	entry := &JournalEntry{}
+	c.Assert(json.Unmarshal(out.Bytes(), entry), IsNil)
+	c.Assert(entry.Endpoint, Equals, "/shows/archive")
+	c.Assert(entry.Before, IsNil)
+	journalErrs = nil
+
+	// write failures do not fail the calls
+	bs.SetMutationJournal(failingWriter{})
+	_, err = bs.ShowAdd(ctx, 1, 0, "", 0)
+	c.Assert(err, IsNil)
+	c.Assert(journalErrs, HasLen, 1)
+	c.Assert(journalErrs[0], ErrorMatches, "disk full")
+
+	bs.SetMutationJournal(nil)
+	_, err = bs.ShowAdd(ctx, 1, 0, "", 0)
+	c.Assert(err, IsNil)
+	c.Assert(journalErrs, HasLen, 1)
+}
+
+func (s *MySuite) TestMutationJournalSecrets(c *C) {
+	out := &bytes.Buffer{}
+	bs, err := NewClient("key", WithMutationJournal(out))
+	c.Assert(err, IsNil)
+	u := bs.endpoint("/members/auth")
+	u.RawQuery = "login=me&password=secret&client_secret=s&token=t&key=k"
+	bs.record("POST", "/members/auth", u, 0, nil, nil)
+	entry := &JournalEntry{}
+	c.Assert(json.Unmarshal(out.Bytes(), entry), IsNil)
+	c.Assert(entry.Params, DeepEquals, url.Values{"login": {"me"}})
+	c.Assert(entry.After, IsNil)
+}
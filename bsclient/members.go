@@ -1,13 +1,17 @@
 package bsclient
 
 import (
-	"errors"
+	"context"
+	"encoding/json"
 	"net/url"
 	"strconv"
+	"time"
 )
 
 var (
-	errNoMembersFound = errors.New("no members found")
+	// ErrNoMembersFound is returned by the member listings when no member
+	// matches. It is an ErrNotFound.
+	ErrNoMembersFound = newError(ErrNotFound, "no members found")
 )
 
 // Member represents the member data returned by the betaserie 'members' API
@@ -54,6 +58,8 @@ type Member struct {
 		//EpisodesTri *? `json:"episodes_tri"`
 		Friendship string `json:"friendship"`
 	} `json:"options"`
+
+	raw json.RawMessage
 }
 
 type members struct {
@@ -66,46 +72,42 @@ type memberItem struct {
 	Errors []interface{} `json:"errors"`
 }
 
-func (bs *BetaSeries) doGetUsers(u *url.URL, usedAPI string) ([]Member, error) {
-	resp, err := bs.do("GET", u)
+func (bs *BetaSeries) doGetUsers(ctx context.Context, u *url.URL, usedAPI string) ([]Member, error) {
+	body, err := bs.do(ctx, "GET", u)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
 	var users struct {
 		Users  []Member      `json:"users"`
 		Errors []interface{} `json:"errors"`
 	}
 	data := &users
-	err = bs.decode(data, resp, usedAPI, u.RawQuery)
+	err = bs.decode(data, body, usedAPI, u.RawQuery)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(data.Users) < 1 {
-		return nil, errNoMembersFound
+		return nil, ErrNoMembersFound
 	}
 
 	return data.Users, nil
 }
 
 /*
-func (bs *BetaSeries) doGetMembers(u *url.URL, usedAPI string) ([]Member, error) {
-	resp, err := bs.do("GET", u)
+func (bs *BetaSeries) doGetMembers(ctx context.Context, u *url.URL, usedAPI string) ([]Member, error) {
+	body, err := bs.do(ctx, "GET", u)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
 	data := &members{}
-	err = bs.decode(data, resp, usedAPI, u.RawQuery)
+	err = bs.decode(data, body, usedAPI, u.RawQuery)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(data.Members) < 1 {
-		return nil, errNoMembersFound
+		return nil, ErrNoMembersFound
 	}
 
 	return data.Members, nil
@@ -113,12 +115,16 @@ func (bs *BetaSeries) doGetMembers(u *url.URL, usedAPI string) ([]Member, error)
 */
 
 // MembersSearch search for members. 'login' can contain the wildcard '%'
-func (bs *BetaSeries) MembersSearch(login string, limit int) ([]Member, error) {
-	usedAPI := "/members/search"
-	u, err := url.Parse(bs.baseURL + usedAPI)
+// A negative 'limit' is rejected with an ErrInvalidInput error, a limit
+// above 100 is clamped to it with a warning returned by LastWarnings.
+// An empty result is reported with ErrNoMembersFound.
+func (bs *BetaSeries) MembersSearch(ctx context.Context, login string, limit int) ([]Member, error) {
+	limit, warning, err := membersSearchLimit.validate(limit)
 	if err != nil {
-		return nil, errURLParsing
+		return nil, err
 	}
+	usedAPI := "/members/search"
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 	q.Set("login", login)
 	if limit > 0 {
@@ -126,19 +132,20 @@ func (bs *BetaSeries) MembersSearch(login string, limit int) ([]Member, error) {
 	}
 	u.RawQuery = q.Encode()
 
-	return bs.doGetUsers(u, usedAPI)
+	members, err := bs.doGetUsers(ctx, u, usedAPI)
+	bs.setWarning(warning)
+	return members, err
 }
 
 // MembersInfos returns member information about the given user (or the
 // authenticated user if id is not set).
 // If summary is true, no data about movies and shows is returns.
 // If summary is false, only can optionally be set to 'movies' or 'shows'.
-func (bs *BetaSeries) MembersInfos(id int, summary bool, only string) (*Member, error) {
+// The error matches ErrMemberNotFound if the member 'id' does not exist and
+// ErrProfilePrivate if their profile is private.
+func (bs *BetaSeries) MembersInfos(ctx context.Context, id int, summary bool, only string) (*Member, error) {
 	usedAPI := "/members/infos"
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 	if id > 0 {
 		q.Set("id", strconv.Itoa(id))
@@ -151,17 +158,171 @@ func (bs *BetaSeries) MembersInfos(id int, summary bool, only string) (*Member,
 	}
 	u.RawQuery = q.Encode()
 
-	resp, err := bs.do("GET", u)
+	body, err := bs.do(ctx, "GET", u)
+	if err != nil {
+		return nil, memberError(id, err)
+	}
+	data := &memberItem{}
+	err = bs.decode(data, body, usedAPI, u.RawQuery)
+	if err != nil {
+		return nil, memberError(id, err)
+	}
+
+	return data.Member, nil
+}
+
+// CurrentUser returns the authenticated member, fetched with a summary
+// MembersInfos on the first call and kept until the token changes. The
+// error matches ErrAuthRequired if the client is not authenticated and
+// ErrTokenInvalid if the API rejects the token.
+func (bs *BetaSeries) CurrentUser(ctx context.Context) (*Member, error) {
+	token, err := bs.getToken()
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	bs.tokenMu.Lock()
+	user := bs.currentUser
+	bs.tokenMu.Unlock()
+	if user == nil {
+		user, err = bs.MembersInfos(ctx, 0, true, "")
+		if err != nil {
+			return nil, err
+		}
+		bs.tokenMu.Lock()
+		// the token may have changed during the request
+		if bs.token != nil && bs.token.Token == token {
+			bs.currentUser = user
+		}
+		bs.tokenMu.Unlock()
+	}
+	member := *user
+	return &member, nil
+}
 
-	data := &memberItem{}
-	err = bs.decode(data, resp, usedAPI, u.RawQuery)
+// MembersIsActive checks that the member token of the client is still
+// valid, for instance after restoring it with SetToken. The error matches
+// ErrTokenInvalid if it is not.
+func (bs *BetaSeries) MembersIsActive(ctx context.Context) error {
+	usedAPI := "/members/is_active"
+	if _, err := bs.getToken(); err != nil {
+		return err
+	}
+	u := bs.endpoint(usedAPI)
+
+	body, err := bs.do(ctx, "GET", u)
+	if err != nil {
+		return err
+	}
+	return bs.decode(&errAPI{}, body, usedAPI, u.RawQuery)
+}
+
+// Summary holds the few numbers an application needs to display the
+// dashboard of the authenticated member.
+type Summary struct {
+	Shows             int
+	EpisodesRemaining int
+	TimeRemaining     time.Duration
+	// UnreadNotifications is only meaningful if NotificationsKnown is true:
+	// failing to count the notifications does not fail the summary.
+	UnreadNotifications int
+	NotificationsKnown  bool
+}
+
+// memberNotificationsCount returns the number of unread notifications of
+// the authenticated member.
+func (bs *BetaSeries) memberNotificationsCount(ctx context.Context) (int, error) {
+	usedAPI := "/members/notifications"
+	u := bs.endpoint(usedAPI)
+	q := u.Query()
+	q.Set("summary", "true")
+	q.Set("auto_delete", "false")
+	u.RawQuery = q.Encode()
+
+	body, err := bs.do(ctx, "GET", u)
+	if err != nil {
+		return 0, err
+	}
+	data := &struct {
+		Notifications int           `json:"notifications"`
+		Errors        []interface{} `json:"errors"`
+	}{}
+	err = bs.decode(data, body, usedAPI, u.RawQuery)
+	if err != nil {
+		return 0, err
+	}
+	return data.Notifications, nil
+}
+
+// AccountSummary returns the dashboard numbers of the authenticated member.
+// The member statistics and the notifications count are requested
+// concurrently; only the former is required for the call to succeed.
+func (bs *BetaSeries) AccountSummary(ctx context.Context) (*Summary, error) {
+	type count struct {
+		n   int
+		err error
+	}
+	notifications := make(chan count, 1)
+	go func() {
+		n, err := bs.memberNotificationsCount(ctx)
+		notifications <- count{n, err}
+	}()
+
+	member, err := bs.MembersInfos(ctx, 0, true, "")
+	unread := <-notifications
 	if err != nil {
 		return nil, err
 	}
+	summary := &Summary{}
+	if member != nil && member.Stats != nil {
+		summary.Shows = member.Stats.Shows
+		summary.EpisodesRemaining = member.Stats.EpisodesToWatch
+		// the API counts time in minutes
+		summary.TimeRemaining = time.Duration(member.Stats.TimeToSpend) * time.Minute
+	}
+	if unread.err == nil {
+		summary.UnreadNotifications = unread.n
+		summary.NotificationsKnown = true
+	}
+	return summary, nil
+}
 
-	return data.Member, nil
+// MemberShowOrder selects the order used by MyShowsOrdered.
+type MemberShowOrder int
+
+// Orders available for MyShowsOrdered
+const (
+	// MyShowsAlphabetical sorts by title, ignoring case, accents and
+	// leading articles
+	MyShowsAlphabetical MemberShowOrder = iota
+	// MyShowsLastWatched keeps the order of the member's shows as listed by
+	// the API, which exposes no watch date per show
+	MyShowsLastWatched
+	// MyShowsRemaining sorts by decreasing number of episodes left to watch
+	MyShowsRemaining
+	// MyShowsNextEpisode sorts by air date of the next episode to watch,
+	// shows without next episode coming last
+	MyShowsNextEpisode
+)
+
+// MyShowsOrdered returns the shows of the authenticated member in the given
+// order. The API does not sort them, the order is computed by the client
+// with a stable sort over the member's show listing.
+func (bs *BetaSeries) MyShowsOrdered(ctx context.Context, order MemberShowOrder) ([]Show, error) {
+	member, err := bs.MembersInfos(ctx, 0, false, "shows")
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrNoShowsFound
+	}
+	shows := member.Shows
+	switch order {
+	case MyShowsAlphabetical:
+		SortShows(shows, ShowsByTitle)
+	case MyShowsRemaining:
+		SortShows(shows, ShowsByRemaining)
+	case MyShowsNextEpisode:
+		SortShows(shows, ShowsByNextAirDate)
+	}
+	return shows, nil
 }
@@ -0,0 +1,88 @@
+package bsclient
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+const summaryMember = `{"member":{"id":1,"login":"me","stats":{
+	"shows":12,"episodes_to_watch":34,"time_to_spend":90}},"errors":[]}`
+
+func (s *MySuite) TestAccountSummary(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/members/infos", 200, summaryMember)
+	f.handleJSON("GET", "/members/notifications", 200, `{"notifications":5,"errors":[]}`)
+	bs := f.client(c)
+
+	summary, err := bs.AccountSummary(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(*summary, DeepEquals, Summary{
+		Shows:               12,
+		EpisodesRemaining:   34,
+		TimeRemaining:       90 * time.Minute,
+		UnreadNotifications: 5,
+		NotificationsKnown:  true,
+	})
+	c.Assert(f.callsTo("/members/infos")[0].Query.Get("summary"), Equals, "true")
+	c.Assert(f.callsTo("/members/notifications"), HasLen, 1)
+}
+
+func (s *MySuite) TestAccountSummaryDegraded(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/members/infos", 200, summaryMember)
+	f.handleJSON("GET", "/members/notifications", 500, `{"errors":[{"code":0,"text":"down"}]}`)
+	bs := f.client(c)
+
+	summary, err := bs.AccountSummary(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(summary.Shows, Equals, 12)
+	c.Assert(summary.NotificationsKnown, Equals, false)
+	c.Assert(summary.UnreadNotifications, Equals, 0)
+
+	f.handleJSON("GET", "/members/infos", 400, `{"errors":[{"code":2001,"text":"Token invalide."}]}`)
+	_, err = bs.AccountSummary(ctx)
+	c.Assert(err, ErrorMatches, "Token invalide.\n")
+}
+
+// memberShows is a member account of 8 shows, in the API order.
+const memberShows = `{"member":{"id":1,"login":"me","shows":[
+	{"id":1,"title":"The Wire","user":{"remaining":3,"next":{"date":"2016-05-02"}}},
+	{"id":2,"title":"Ékipe","user":{"remaining":0,"next":{"date":""}}},
+	{"id":3,"title":"breaking bad","user":{"remaining":10,"next":{"date":"2016-04-01"}}},
+	{"id":4,"title":"Le Bureau des légendes","user":{"remaining":3,"next":{"date":"2016-04-01"}}},
+	{"id":5,"title":"Atlanta","user":{"remaining":1,"next":{"date":"0000-00-00"}}},
+	{"id":6,"title":"Dark","user":{"remaining":25,"next":{"date":"2015-12-01"}}},
+	{"id":7,"title":"Carnivàle","user":{"remaining":0}},
+	{"id":8,"title":"Engrenages","user":{"remaining":10,"next":{"date":"2016-06-12"}}}
+]},"errors":[]}`
+
+func (s *MySuite) TestMyShowsOrdered(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/members/infos", 200, memberShows)
+	bs := f.client(c)
+	for _, test := range []struct {
+		order    MemberShowOrder
+		expected []int
+	}{
+		{MyShowsAlphabetical, []int{5, 3, 4, 7, 6, 2, 8, 1}},
+		{MyShowsLastWatched, []int{1, 2, 3, 4, 5, 6, 7, 8}},
+		{MyShowsRemaining, []int{6, 3, 8, 1, 4, 5, 2, 7}},
+		{MyShowsNextEpisode, []int{6, 3, 4, 1, 8, 2, 5, 7}},
+	} {
+		shows, err := bs.MyShowsOrdered(ctx, test.order)
+		c.Assert(err, IsNil)
+		ids := []int{}
+		for _, show := range shows {
+			ids = append(ids, show.ID)
+		}
+		c.Assert(ids, DeepEquals, test.expected, Commentf("order %d", test.order))
+	}
+	calls := f.callsTo("/members/infos")
+	c.Assert(calls, HasLen, 4)
+	c.Assert(calls[0].Query.Get("only"), Equals, "shows")
+	c.Assert(calls[0].Query.Get("id"), Equals, "")
+}
@@ -0,0 +1,111 @@
+package bsclient
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// catalog pages scanned by ShowsByNetwork and Networks
+const (
+	maxNetworkPages = 5
+	networkPageSize = 100
+)
+
+// networkSuffix matches the country suffixes the API appends to some
+// networks, as in "HBO (US)".
+var networkSuffix = regexp.MustCompile(`\s*\([^()]*\)\s*$`)
+
+// networkName returns the network without surrounding spaces nor country
+// suffix.
+func networkName(network string) string {
+	return strings.TrimSpace(networkSuffix.ReplaceAllString(network, ""))
+}
+
+// networkKey returns the key used to compare networks: without country
+// suffix, inner spaces collapsed, ignoring case and accents.
+func networkKey(network string) string {
+	return foldString(strings.Join(strings.Fields(networkName(network)), " "))
+}
+
+// scanCatalog calls 'f' on the shows of the catalog ordered by popularity,
+// up to 'max' shows, until it returns false.
+func (bs *BetaSeries) scanCatalog(ctx context.Context, max int, f func(show *Show) bool) error {
+	for start := 0; start < max; start += networkPageSize {
+		size := networkPageSize
+		if max-start < size {
+			size = max - start
+		}
+		shows, err := bs.showsList(ctx, "", "", "popularity", start, size)
+		if errors.Is(err, ErrNoShowsFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for i := range shows {
+			if bs.excluded(&shows[i]) {
+				continue
+			}
+			if !f(&shows[i]) {
+				return nil
+			}
+		}
+		if len(shows) < size {
+			return nil
+		}
+	}
+	return nil
+}
+
+// ShowsByNetwork returns up to 'limit' shows (no limit if 'limit' is 0 or
+// negative) aired by the given network, most popular first.
+// The API has no network filter: the 500 most popular shows are scanned
+// and compared to 'network' ignoring case, accents and country suffixes
+// such as "(US)".
+func (bs *BetaSeries) ShowsByNetwork(ctx context.Context, network string, limit int) ([]Show, error) {
+	key := networkKey(network)
+	var out []Show
+	err := bs.scanCatalog(ctx, maxNetworkPages*networkPageSize, func(show *Show) bool {
+		if networkKey(show.Network) == key {
+			out = append(out, *show)
+		}
+		return limit <= 0 || len(out) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, ErrNoShowsFound
+	}
+	return out, nil
+}
+
+// Networks returns the distinct networks of the 'sample' most popular shows
+// (500 if 'sample' is 0 or negative), sorted alphabetically. Networks are
+// returned without their country suffix, duplicates being detected as in
+// ShowsByNetwork.
+func (bs *BetaSeries) Networks(ctx context.Context, sample int) ([]string, error) {
+	if sample <= 0 {
+		sample = maxNetworkPages * networkPageSize
+	}
+	seen := map[string]bool{}
+	var networks []string
+	err := bs.scanCatalog(ctx, sample, func(show *Show) bool {
+		key := networkKey(show.Network)
+		if key != "" && !seen[key] {
+			seen[key] = true
+			networks = append(networks, strings.Join(strings.Fields(networkName(show.Network)), " "))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(networks, func(i, j int) bool {
+		return networkKey(networks[i]) < networkKey(networks[j])
+	})
+	return networks, nil
+}
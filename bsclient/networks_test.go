@@ -0,0 +1,98 @@
+package bsclient
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestNetworkKey(c *C) {
+	for _, test := range []struct {
+		network  string
+		expected string
+	}{
+		{"HBO", "hbo"},
+		{"HBO (US)", "hbo"},
+		{"  hbo  ", "hbo"},
+		{"BBC One (UK)", "bbc one"},
+		{"BBC  One", "bbc one"},
+		{"Canal+", "canal+"},
+		{"France Télévisions", "france televisions"},
+		{"Showtime (US) ", "showtime"},
+		{"(US)", ""},
+		{"", ""},
+	} {
+		c.Assert(networkKey(test.network), Equals, test.expected, Commentf(test.network))
+	}
+}
+
+// handleNetworkCatalog serves a catalog of 'total' shows whose networks
+// cycle through the given ones.
+func handleNetworkCatalog(f *fakeServer, total int, networks ...string) {
+	f.handle("GET", "/shows/list", func(w http.ResponseWriter, r *http.Request) {
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var shows []string
+		for i := start; i < start+limit && i < total; i++ {
+			shows = append(shows, fmt.Sprintf(`{"id":%d,"network":%q}`, i+1, networks[i%len(networks)]))
+		}
+		if len(shows) == 0 {
+			writeFakeJSON(w, 200, `{"shows":[],"errors":[]}`)
+			return
+		}
+		writeFakeJSON(w, 200, `{"shows":[`+strings.Join(shows, ",")+`],"errors":[]}`)
+	})
+}
+
+func (s *MySuite) TestShowsByNetwork(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	handleNetworkCatalog(f, 250, "HBO (US)", "Netflix", "hbo", "AMC")
+	bs := f.client(c)
+
+	shows, err := bs.ShowsByNetwork(ctx, "HBO", 3)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 3)
+	c.Assert(shows[0].ID, Equals, 1)
+	c.Assert(shows[1].ID, Equals, 3)
+	c.Assert(shows[2].ID, Equals, 5)
+	c.Assert(f.callsTo("/shows/list"), HasLen, 1)
+
+	shows, err = bs.ShowsByNetwork(ctx, "amc", 0)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 62)
+	c.Assert(f.callsTo("/shows/list"), HasLen, 4)
+
+	_, err = bs.ShowsByNetwork(ctx, "CBS", 0)
+	c.Assert(err, Equals, ErrNoShowsFound)
+}
+
+func (s *MySuite) TestShowsByNetworkBounded(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	handleNetworkCatalog(f, 10000, "HBO")
+	bs := f.client(c)
+
+	shows, err := bs.ShowsByNetwork(ctx, "HBO", 0)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, maxNetworkPages*networkPageSize)
+	c.Assert(f.callsTo("/shows/list"), HasLen, maxNetworkPages)
+}
+
+func (s *MySuite) TestNetworks(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	handleNetworkCatalog(f, 250, "Netflix", "HBO (US)", "hbo", "", "Canal+", "netflix ", "ABC")
+	bs := f.client(c)
+
+	networks, err := bs.Networks(ctx, 150)
+	c.Assert(err, IsNil)
+	c.Assert(networks, DeepEquals, []string{"ABC", "Canal+", "HBO", "Netflix"})
+	calls := f.callsTo("/shows/list")
+	c.Assert(calls, HasLen, 2)
+	c.Assert(calls[1].Query.Get("start"), Equals, "100")
+	c.Assert(calls[1].Query.Get("limit"), Equals, "50")
+}
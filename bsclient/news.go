@@ -1,13 +1,14 @@
 package bsclient
 
 import (
-	"errors"
-	"net/url"
+	"context"
 	"strconv"
 )
 
 var (
-	errNoNewsFound = errors.New("no news found")
+	// ErrNoNewsFound is returned by NewsLast when there is no news.
+	// It is an ErrNotFound.
+	ErrNoNewsFound = newError(ErrNotFound, "no news found")
 )
 
 // News represents a news of a particular tv show
@@ -27,31 +28,27 @@ type news struct {
 // NewsLast returns a slice of news of tv shows
 // If 'number' is strictly negative, it returns a default of 10 news maximum.
 // The 'tailored' parameter returns tv show news of the identified member.
-func (bs *BetaSeries) NewsLast(number int, tailored bool) ([]News, error) {
+// An empty result is reported with ErrNoNewsFound.
+func (bs *BetaSeries) NewsLast(ctx context.Context, number int, tailored bool) ([]News, error) {
 	usedAPI := "/news/last"
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 	q.Set("number", strconv.Itoa(number))
 	q.Set("tailored", strconv.FormatBool(tailored))
 	u.RawQuery = q.Encode()
 
-	resp, err := bs.do("GET", u)
+	body, err := bs.do(ctx, "GET", u)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
 	data := &news{}
-	err = bs.decode(data, resp, usedAPI, u.RawQuery)
+	err = bs.decode(data, body, usedAPI, u.RawQuery)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(data.News) < 1 {
-		return nil, errNoNewsFound
+		return nil, ErrNoNewsFound
 	}
 
 	return data.News, nil
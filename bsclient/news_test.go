@@ -11,7 +11,7 @@ func (s *MySuite) TestNewsLast(c *C) {
 	key := os.Getenv("BS_API_KEY")
 	bs, err := NewBetaseriesClient(key, "", "")
 	c.Assert(err, IsNil)
-	news, err := bs.NewsLast(1, false)
+	news, err := bs.NewsLast(ctx, 1, false)
 	if len(news) > 0 {
 		c.Assert(err, IsNil)
 		c.Assert(len(news), Equals, 1)
@@ -20,6 +20,6 @@ func (s *MySuite) TestNewsLast(c *C) {
 		c.Assert(strings.Contains(news[0].PictureURL, "http"), Equals, true)
 	} else {
 		c.Assert(err, NotNil)
-		c.Assert(err, Equals, errNoNewsFound)
+		c.Assert(err, Equals, ErrNoNewsFound)
 	}
 }
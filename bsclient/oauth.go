@@ -0,0 +1,79 @@
+package bsclient
+
+import (
+	"context"
+	"net/url"
+)
+
+// bsAuthorizeURL is the page where members authorize an application.
+const bsAuthorizeURL = "https://www.betaseries.com/authorize"
+
+var (
+	errNoCode        = newError(ErrInvalidInput, "no authorization code")
+	errNoAccessToken = newError(ErrAuthRequired, "no access token in response")
+)
+
+// errOAuth wraps the API errors of the code exchange, the API rejecting
+// expired codes and wrong secrets with codes unrelated to authentication.
+// It only matches ErrAuthRequired, whatever the codes: errors.As still finds
+// the first APIError.
+type errOAuth struct {
+	*errAPI
+}
+
+func (e *errOAuth) Is(target error) bool {
+	return target == ErrAuthRequired
+}
+
+// oauthError returns the error of the code exchange, so that the API errors
+// match ErrAuthRequired.
+func oauthError(err error) error {
+	if apiErr, ok := err.(*errAPI); ok {
+		return &errOAuth{apiErr}
+	}
+	return err
+}
+
+// AuthorizeURL returns the url of the page where the member authorizes the
+// application of the API key, which then redirects the member to
+// 'redirectURI' with a 'code' to give to ExchangeCode.
+func (bs *BetaSeries) AuthorizeURL(redirectURI string) string {
+	q := url.Values{}
+	q.Set("client_id", bs.key)
+	q.Set("redirect_uri", redirectURI)
+	return bsAuthorizeURL + "?" + q.Encode()
+}
+
+// ExchangeCode authenticates the client with the OAuth 2.0 authorization
+// code received on 'redirectURI', which must be the one given to
+// AuthorizeURL. The resulting token is used by the following requests.
+// If the code is expired or the secret wrong, the API error returned is an
+// ErrAuthRequired.
+func (bs *BetaSeries) ExchangeCode(ctx context.Context, code, clientSecret, redirectURI string) error {
+	usedAPI := "/members/access_token"
+	if code == "" {
+		return errNoCode
+	}
+	u := bs.endpoint(usedAPI)
+	q := u.Query()
+	q.Set("client_id", bs.key)
+	q.Set("client_secret", clientSecret)
+	q.Set("redirect_uri", redirectURI)
+	q.Set("code", code)
+	u.RawQuery = q.Encode()
+
+	body, err := bs.do(ctx, "POST", u)
+	if err != nil {
+		return oauthError(err)
+	}
+	tokenData := &token{}
+	err = bs.decode(tokenData, body, usedAPI, u.RawQuery)
+	if err != nil {
+		return oauthError(err)
+	}
+	if tokenData.Token == "" {
+		return errNoAccessToken
+	}
+	bs.setToken(tokenData)
+	return nil
+}
@@ -0,0 +1,58 @@
+package bsclient
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestAuthorizeURL(c *C) {
+	bs := &BetaSeries{key: "abc"}
+	c.Assert(bs.AuthorizeURL("https://example.com/cb?x=1"), Equals,
+		"https://www.betaseries.com/authorize?client_id=abc&redirect_uri=https%3A%2F%2Fexample.com%2Fcb%3Fx%3D1")
+}
+
+func (s *MySuite) TestExchangeCode(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("POST", "/members/access_token", 200, `{"token":"oauth-token","errors":[]}`)
+	bs := f.client(c)
+	bs.token = nil
+
+	c.Assert(bs.ExchangeCode(ctx, "", "secret", "https://example.com/cb"), Equals, errNoCode)
+	c.Assert(f.calls, HasLen, 0)
+
+	c.Assert(bs.ExchangeCode(ctx, "code", "secret", "https://example.com/cb"), IsNil)
+	calls := f.callsTo("/members/access_token")
+	c.Assert(calls, HasLen, 1)
+	c.Assert(calls[0].Form, DeepEquals, url.Values{
+		"client_id": {"key"}, "client_secret": {"secret"},
+		"redirect_uri": {"https://example.com/cb"}, "code": {"code"}})
+	c.Assert(bs.token.Token, Equals, "oauth-token")
+
+	// the token is sent as with basic authentication
+	f.handleJSON("GET", "/members/infos", 200, `{"member":{"id":1},"errors":[]}`)
+	_, err := bs.MembersInfos(ctx, 0, true, "")
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/members/infos")[0].Header.Get("X-BetaSeries-Token"), Equals, "oauth-token")
+
+	// expired code or wrong secret
+	for _, status := range []int{http.StatusOK, http.StatusBadRequest} {
+		bs.token = nil
+		f.handleJSON("POST", "/members/access_token", status, `{"errors":[{"code":3001,"text":"Code invalide."}]}`)
+		err = bs.ExchangeCode(ctx, "expired", "secret", "https://example.com/cb")
+		c.Assert(errors.Is(err, ErrAuthRequired), Equals, true)
+		c.Assert(errors.Is(err, ErrUnknownAPIError), Equals, false)
+		var apiErr *APIError
+		c.Assert(errors.As(err, &apiErr), Equals, true)
+		c.Assert(apiErr.Code, Equals, 3001)
+		c.Assert(err, ErrorMatches, "Code invalide.\n")
+		c.Assert(bs.token, IsNil)
+	}
+
+	f.handleJSON("POST", "/members/access_token", 200, `{"errors":[]}`)
+	c.Assert(bs.ExchangeCode(ctx, "code", "secret", "https://example.com/cb"), Equals, errNoAccessToken)
+	c.Assert(bs.token, IsNil)
+}
@@ -0,0 +1,68 @@
+package bsclient
+
+import (
+	"strconv"
+	"strings"
+)
+
+// orderParam describes the sort orders accepted by an endpoint.
+type orderParam struct {
+	endpoint string
+	allowed  []string
+	// def is the order used when none is given
+	def string
+}
+
+// Orders of the endpoints
+var (
+	showsSearchOrders = orderParam{"/shows/search", []string{"title", "popularity", "followers"}, "popularity"}
+	showsListOrders   = orderParam{"/shows/list", []string{"alphabetical", "popularity", "followers"}, "popularity"}
+)
+
+// validate returns the order to request for 'order', the default one if it
+// is empty, or an ErrInvalidInput error listing the allowed orders.
+func (p orderParam) validate(order string) (string, error) {
+	if order == "" {
+		return p.def, nil
+	}
+	for _, allowed := range p.allowed {
+		if order == allowed {
+			return order, nil
+		}
+	}
+	return "", newError(ErrInvalidInput, "invalid order "+strconv.Quote(order)+" for "+
+		p.endpoint+": must be one of "+strings.Join(p.allowed, ", "))
+}
+
+// limitParam describes the parameter limiting the number of results of an
+// endpoint.
+type limitParam struct {
+	endpoint string
+	name     string
+	// max is the maximum served by the API, 0 if there is none
+	max int
+}
+
+// Limits of the endpoints
+var (
+	membersSearchLimit = limitParam{"/members/search", "limit", 100}
+	showsListLimit     = limitParam{"/shows/list", "limit", 1000}
+	episodesListLimit  = limitParam{"/episodes/list", "limit", 0}
+	subtitlesLastLimit = limitParam{"/subtitles/last", "number", 100}
+)
+
+// validate returns the limit to request for 'limit', clamped to the
+// maximum along with a warning for LastWarnings if it is above it, or an
+// ErrInvalidInput error if it is negative. A zero limit lets the API use
+// its default.
+func (p limitParam) validate(limit int) (int, *APIError, error) {
+	switch {
+	case limit < 0:
+		return 0, nil, newError(ErrInvalidInput, "invalid "+p.name+" "+strconv.Itoa(limit)+" for "+
+			p.endpoint+": must not be negative")
+	case p.max > 0 && limit > p.max:
+		return p.max, &APIError{Text: p.name + " " + strconv.Itoa(limit) + " for " + p.endpoint +
+			" clamped to its maximum " + strconv.Itoa(p.max)}, nil
+	}
+	return limit, nil, nil
+}
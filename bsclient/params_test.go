@@ -0,0 +1,96 @@
+package bsclient
+
+import (
+	"errors"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestOrderParam(c *C) {
+	order, err := showsSearchOrders.validate("")
+	c.Assert(err, IsNil)
+	c.Assert(order, Equals, "popularity")
+	order, err = showsListOrders.validate("alphabetical")
+	c.Assert(err, IsNil)
+	c.Assert(order, Equals, "alphabetical")
+}
+
+// TestParamErrors pins the messages of the parameter errors, which tell the
+// caller the accepted values.
+func (s *MySuite) TestParamErrors(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	bs := f.client(c)
+
+	for _, test := range []struct {
+		call     func() error
+		expected string
+	}{
+		{func() error { _, err := bs.ShowsSearch(ctx, "lost", "alphabetical", false); return err },
+			`invalid order "alphabetical" for /shows/search: must be one of title, popularity, followers`},
+		{func() error { _, err := bs.ShowsList(ctx, "", "", "title", 0, 0); return err },
+			`invalid order "title" for /shows/list: must be one of alphabetical, popularity, followers`},
+		{func() error { _, err := bs.ShowsList(ctx, "", "", "", 0, -1); return err },
+			`invalid limit -1 for /shows/list: must not be negative`},
+		{func() error { _, err := bs.EpisodesList(ctx, 0, 0, "", 0, -5, -1, false, false); return err },
+			`invalid limit -5 for /episodes/list: must not be negative`},
+		{func() error { _, err := bs.MembersSearch(ctx, "dev%", -1); return err },
+			`invalid limit -1 for /members/search: must not be negative`},
+		{func() error { _, err := bs.SubtitlesLast(ctx, -1, "all"); return err },
+			`invalid number -1 for /subtitles/last: must not be negative`},
+	} {
+		err := test.call()
+		c.Assert(err.Error(), Equals, test.expected)
+		c.Assert(errors.Is(err, ErrInvalidInput), Equals, true)
+	}
+	c.Assert(f.calls, HasLen, 0)
+}
+
+func (s *MySuite) TestLimitClamped(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/list", 200, `{"shows":[{"id":1}],"errors":[{"code":4001,"text":"Série introuvable."}]}`)
+	f.handleJSON("GET", "/members/search", 200, `{"users":[{"id":1,"login":"dev"}],"errors":[]}`)
+	f.handleJSON("GET", "/subtitles/last", 200, `{"subtitles":[{"id":1}],"errors":[]}`)
+	bs := f.client(c)
+
+	_, err := bs.ShowsList(ctx, "", "", "", 0, 100000)
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/shows/list")[0].Query.Get("limit"), Equals, "1000")
+	c.Assert(bs.LastWarnings(), DeepEquals, []APIError{
+		{Code: 4001, Text: "Série introuvable."},
+		{Text: "limit 100000 for /shows/list clamped to its maximum 1000"},
+	})
+	_, err = bs.ShowsList(ctx, "", "", "", 0, 1000)
+	c.Assert(err, IsNil)
+	c.Assert(bs.LastWarnings(), HasLen, 1)
+
+	_, err = bs.MembersSearch(ctx, "dev%", 500)
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/members/search")[0].Query.Get("limit"), Equals, "100")
+	c.Assert(bs.LastWarnings(), DeepEquals, []APIError{
+		{Text: "limit 500 for /members/search clamped to its maximum 100"},
+	})
+	_, err = bs.SubtitlesLast(ctx, 100000, "all")
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/subtitles/last")[0].Query.Get("number"), Equals, "100")
+	c.Assert(bs.LastWarnings(), DeepEquals, []APIError{
+		{Text: "number 100000 for /subtitles/last clamped to its maximum 100"},
+	})
+	_, err = bs.SubtitlesLast(ctx, 10, "all")
+	c.Assert(err, IsNil)
+	c.Assert(bs.LastWarnings(), IsNil)
+
+	// the warnings of a failed listing are its own
+	_, err = bs.SubtitlesLast(ctx, 100000, "all")
+	c.Assert(err, IsNil)
+	f.handleJSON("GET", "/shows/list", 503, ``)
+	_, err = bs.ShowsList(ctx, "", "", "", 0, 100000)
+	c.Assert(errors.Is(err, ErrServiceUnavailable), Equals, true)
+	c.Assert(bs.LastWarnings(), DeepEquals, []APIError{
+		{Text: "limit 100000 for /shows/list clamped to its maximum 1000"},
+	})
+	_, err = bs.ShowsList(ctx, "", "", "", 0, 10)
+	c.Assert(errors.Is(err, ErrServiceUnavailable), Equals, true)
+	c.Assert(bs.LastWarnings(), IsNil)
+}
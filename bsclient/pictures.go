@@ -1,30 +1,35 @@
 package bsclient
 
 import (
-	"bytes"
-	"errors"
-	"io"
-	"net/url"
+	"context"
 	"strconv"
 )
 
 var (
-	errIDMustBeStrictlyPositive = errors.New("id must be strictly positive")
+	// ErrIDMustBeStrictlyPositive is returned by PicturesShows and
+	// PicturesEpisodes for ids which are not strictly positive. It is an ErrInvalidInput.
+	ErrIDMustBeStrictlyPositive = newError(ErrInvalidInput, "id must be strictly positive")
 )
 
 // PicturesShows returns a picture of the tv show identified by 'id'.
 // If 'id' is negative, a default betaseries picture will be returned with an error code.
 // The optional 'width' and 'height' parameters must be both strictly
 // positive in order to be used.
-func (bs *BetaSeries) PicturesShows(id, width, height int) (string, error) {
-	usedAPI := "/pictures/shows"
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return "", errURLParsing
-	}
+func (bs *BetaSeries) PicturesShows(ctx context.Context, id, width, height int) (string, error) {
+	return bs.picture(ctx, "/pictures/shows", id, width, height)
+}
+
+// PicturesEpisodes returns the still of the episode identified by 'id', as
+// PicturesShows does for shows.
+func (bs *BetaSeries) PicturesEpisodes(ctx context.Context, id, width, height int) (string, error) {
+	return bs.picture(ctx, "/pictures/episodes", id, width, height)
+}
+
+func (bs *BetaSeries) picture(ctx context.Context, usedAPI string, id, width, height int) (string, error) {
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 	if id <= 0 {
-		return "", errIDMustBeStrictlyPositive
+		return "", ErrIDMustBeStrictlyPositive
 	}
 	q.Set("id", strconv.Itoa(id))
 	if width > 0 && height > 0 {
@@ -32,16 +37,10 @@ func (bs *BetaSeries) PicturesShows(id, width, height int) (string, error) {
 		q.Set("height", strconv.Itoa(height))
 	}
 	u.RawQuery = q.Encode()
-	resp, err := bs.do("GET", u)
+	body, err := bs.do(ctx, "GET", u)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
-
-	buf := new(bytes.Buffer)
-	if _, err := io.Copy(buf, resp.Body); err != nil {
-		return "", err
-	}
 
-	return buf.String(), nil
+	return string(body), nil
 }
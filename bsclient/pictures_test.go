@@ -1,6 +1,8 @@
 package bsclient
 
 import (
+	"net/http"
+	"net/url"
 	"os"
 
 	. "gopkg.in/check.v1"
@@ -10,17 +12,41 @@ func (s *MySuite) TestPicturesShows(c *C) {
 	key := os.Getenv("BS_API_KEY")
 	bs, err := NewBetaseriesClient(key, "Dev050", "developer")
 	c.Assert(err, IsNil)
-	picture, err := bs.PicturesShows(1, -1, -1)
+	picture, err := bs.PicturesShows(ctx, 1, -1, -1)
 	c.Assert(err, IsNil)
 	// can't equals to a specific value since
 	// a different image can be retrieve somethimes
 	c.Assert(len(picture) > 0, Equals, true)
 
-	picture, err = bs.PicturesShows(1, 100, 100)
+	picture, err = bs.PicturesShows(ctx, 1, 100, 100)
 	c.Assert(err, IsNil)
 	c.Assert(len(picture) > 0, Equals, true)
 
-	picture, err = bs.PicturesShows(0, 100, 100)
+	picture, err = bs.PicturesShows(ctx, 0, 100, 100)
 	c.Assert(err, NotNil)
-	c.Assert(err, Equals, errIDMustBeStrictlyPositive)
+	c.Assert(err, Equals, ErrIDMustBeStrictlyPositive)
+}
+
+func (s *MySuite) TestPicturesEpisodes(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handle("GET", "/pictures/episodes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write([]byte("\xff\xd8\xff\xe0still"))
+	})
+	bs := f.client(c)
+	picture, err := bs.PicturesEpisodes(ctx, 225403, 320, 180)
+	c.Assert(err, IsNil)
+	c.Assert(picture, Equals, "\xff\xd8\xff\xe0still")
+	c.Assert(f.callsTo("/pictures/episodes")[0].Query, DeepEquals,
+		url.Values{"id": {"225403"}, "width": {"320"}, "height": {"180"}})
+
+	// the size is only sent when both dimensions are set
+	_, err = bs.PicturesEpisodes(ctx, 225403, 320, 0)
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/pictures/episodes")[1].Query, DeepEquals, url.Values{"id": {"225403"}})
+
+	_, err = bs.PicturesEpisodes(ctx, 0, 0, 0)
+	c.Assert(err, Equals, ErrIDMustBeStrictlyPositive)
+	c.Assert(f.callsTo("/pictures/episodes"), HasLen, 2)
 }
@@ -0,0 +1,238 @@
+package bsclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// PinStore keeps the ids of the episodes pinned to watch later. The API
+// has no such list, so the pins are kept on the client side.
+// Implementations must be safe for concurrent use.
+type PinStore interface {
+	// Get returns the pinned episode ids, in pinning order.
+	Get() ([]int, error)
+	// Add pins the episode 'id'; pinning it again does nothing.
+	Add(id int) error
+	// Remove unpins the episode 'id'; unpinning it again does nothing.
+	Remove(id int) error
+}
+
+// memoryPinStore is the default PinStore, losing the pins with the client.
+type memoryPinStore struct {
+	mu  sync.Mutex
+	ids []int
+}
+
+func (s *memoryPinStore) Get() ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.ids...), nil
+}
+
+func (s *memoryPinStore) Add(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ids = addPin(s.ids, id)
+	return nil
+}
+
+func (s *memoryPinStore) Remove(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ids = removePin(s.ids, id)
+	return nil
+}
+
+func addPin(ids []int, id int) []int {
+	for _, pinned := range ids {
+		if pinned == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
+
+func removePin(ids []int, id int) []int {
+	out := ids[:0]
+	for _, pinned := range ids {
+		if pinned != id {
+			out = append(out, pinned)
+		}
+	}
+	return out
+}
+
+// FilePinStore is a PinStore keeping the pins in a JSON file.
+type FilePinStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFilePinStore returns a PinStore keeping the pins in the file at 'path',
+// created on the first pin.
+func NewFilePinStore(path string) *FilePinStore {
+	return &FilePinStore{path: path}
+}
+
+func (s *FilePinStore) read() ([]int, error) {
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	err = json.Unmarshal(data, &ids)
+	return ids, err
+}
+
+// write replaces the file, through a temporary file so that it is never
+// left half written.
+func (s *FilePinStore) write(ids []int) error {
+	if ids == nil {
+		ids = []int{}
+	}
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// Get returns the pinned episode ids.
+func (s *FilePinStore) Get() ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+// Add pins the episode 'id'.
+func (s *FilePinStore) Add(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids, err := s.read()
+	if err != nil {
+		return err
+	}
+	return s.write(addPin(ids, id))
+}
+
+// Remove unpins the episode 'id'.
+func (s *FilePinStore) Remove(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids, err := s.read()
+	if err != nil {
+		return err
+	}
+	return s.write(removePin(ids, id))
+}
+
+// SetPinStore sets the store of the pinned episodes. By default, the pins
+// are kept in memory and lost with the client.
+func (bs *BetaSeries) SetPinStore(store PinStore) {
+	bs.pins = store
+}
+
+// PinEpisode pins the episode 'id' to watch later.
+func (bs *BetaSeries) PinEpisode(id int) error {
+	if id <= 0 {
+		return ErrIDMustBeStrictlyPositive
+	}
+	return bs.pins.Add(id)
+}
+
+// UnpinEpisode unpins the episode 'id'.
+func (bs *BetaSeries) UnpinEpisode(id int) error {
+	return bs.pins.Remove(id)
+}
+
+// PinnedEpisodes returns the pinned episodes, in pinning order, along with
+// the ids of the pinned episodes which no longer exist. Those are unpinned.
+// The episodes are requested by chunks of 50 through the multiple display of
+// episodes/display.
+func (bs *BetaSeries) PinnedEpisodes(ctx context.Context) ([]EpisodeWithShow, []int, error) {
+	store := bs.pins
+	ids, err := store.Get()
+	if err != nil {
+		return nil, nil, err
+	}
+	found := map[int]Episode{}
+	for _, chunk := range chunkIDs(ids, displayChunkSize) {
+		episodes, err := bs.episodesDisplay(ctx, chunk)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, nil, err
+		}
+		for _, episode := range episodes {
+			found[episode.ID] = episode
+		}
+	}
+
+	var pinned []EpisodeWithShow
+	var stale []int
+	for _, id := range ids {
+		episode, ok := found[id]
+		if !ok {
+			stale = append(stale, id)
+			continue
+		}
+		pinned = append(pinned, EpisodeWithShow{episode, episodeShow(&episode)})
+	}
+	for _, id := range stale {
+		if err := store.Remove(id); err != nil {
+			return pinned, stale, err
+		}
+	}
+	return pinned, stale, nil
+}
+
+// episodesDisplay returns the episodes 'ids' which exist, along with the
+// API errors of the others, if any.
+func (bs *BetaSeries) episodesDisplay(ctx context.Context, ids []int) ([]Episode, error) {
+	usedAPI := "/episodes/display"
+	u := bs.endpoint(usedAPI)
+	q := u.Query()
+	q.Set("id", joinIDs(ids))
+	u.RawQuery = q.Encode()
+
+	body, err := bs.do(ctx, "GET", u)
+	if err != nil {
+		return nil, err
+	}
+	// a single episode is returned alone rather than in a list
+	data := &struct {
+		Episode  *Episode  `json:"episode"`
+		Episodes []Episode `json:"episodes"`
+	}{}
+	apiErr, err := bs.decodePartial(data, body)
+	if err != nil {
+		return nil, err
+	}
+	if data.Episode != nil {
+		data.Episodes = append(data.Episodes, *data.Episode)
+	}
+	if apiErr != nil {
+		return data.Episodes, apiErr
+	}
+	return data.Episodes, nil
+}
@@ -0,0 +1,116 @@
+package bsclient
+
+import (
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestFilePinStore(c *C) {
+	path := filepath.Join(c.MkDir(), "pins.json")
+	store := NewFilePinStore(path)
+	ids, err := store.Get()
+	c.Assert(err, IsNil)
+	c.Assert(ids, HasLen, 0)
+
+	for _, id := range []int{3, 1, 3, 2} {
+		c.Assert(store.Add(id), IsNil)
+	}
+	c.Assert(store.Remove(1), IsNil)
+	c.Assert(store.Remove(42), IsNil)
+	data, err := ioutil.ReadFile(path)
+	c.Assert(err, IsNil)
+	c.Assert(string(data), Equals, "[3,2]")
+
+	// the pins survive the store
+	ids, err = NewFilePinStore(path).Get()
+	c.Assert(err, IsNil)
+	c.Assert(ids, DeepEquals, []int{3, 2})
+	files, err := ioutil.ReadDir(filepath.Dir(path))
+	c.Assert(err, IsNil)
+	c.Assert(files, HasLen, 1)
+
+	c.Assert(ioutil.WriteFile(path, []byte("{"), 0644), IsNil)
+	_, err = store.Get()
+	c.Assert(err, NotNil)
+	c.Assert(store.Add(1), NotNil)
+}
+
+func (s *MySuite) TestPinnedEpisodes(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handle("GET", "/episodes/display", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("id") {
+		case "7":
+			writeFakeJSON(w, 200, `{"episode":{"id":7,"code":"S01E07","show":{"id":70,"title":"Lost"}},"errors":[]}`)
+		case "9":
+			writeFakeJSON(w, 400, `{"errors":[{"code":4002,"text":"Episode introuvable."}]}`)
+		default:
+			writeFakeJSON(w, 200, `{"episodes":[
+				{"id":3,"code":"S01E03","show":{"id":30,"title":"Breaking Bad"}},
+				{"id":1,"code":"S01E01","show":{"id":10,"title":"Dexter"}}],"errors":[]}`)
+		}
+	})
+	bs := f.client(c)
+
+	pinned, stale, err := bs.PinnedEpisodes(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(pinned, HasLen, 0)
+	c.Assert(stale, HasLen, 0)
+	c.Assert(f.callsTo("/episodes/display"), HasLen, 0)
+
+	for _, id := range []int{1, 2, 3} {
+		c.Assert(bs.PinEpisode(id), IsNil)
+	}
+	c.Assert(bs.PinEpisode(0), Equals, ErrIDMustBeStrictlyPositive)
+	pinned, stale, err = bs.PinnedEpisodes(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(airingIDs(pinned), DeepEquals, []int{1, 3})
+	c.Assert(pinned[1].Show.Title, Equals, "Breaking Bad")
+	c.Assert(stale, DeepEquals, []int{2})
+	c.Assert(f.callsTo("/episodes/display")[0].Query.Get("id"), Equals, "1,2,3")
+	ids, err := bs.pins.Get()
+	c.Assert(err, IsNil)
+	c.Assert(ids, DeepEquals, []int{1, 3})
+
+	// single episodes and missing single episodes
+	store := NewFilePinStore(filepath.Join(c.MkDir(), "pins.json"))
+	bs.SetPinStore(store)
+	c.Assert(bs.PinEpisode(7), IsNil)
+	pinned, stale, err = bs.PinnedEpisodes(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(airingIDs(pinned), DeepEquals, []int{7})
+	c.Assert(stale, HasLen, 0)
+	c.Assert(bs.UnpinEpisode(7), IsNil)
+	c.Assert(bs.PinEpisode(9), IsNil)
+	pinned, stale, err = bs.PinnedEpisodes(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(pinned, HasLen, 0)
+	c.Assert(stale, DeepEquals, []int{9})
+	ids, err = store.Get()
+	c.Assert(err, IsNil)
+	c.Assert(ids, HasLen, 0)
+
+	// chunks
+	for id := 100; id < 160; id++ {
+		c.Assert(bs.PinEpisode(id), IsNil)
+	}
+	_, stale, err = bs.PinnedEpisodes(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(stale, HasLen, 60)
+	calls := f.callsTo("/episodes/display")
+	c.Assert(calls, HasLen, 5)
+	c.Assert(strings.Split(calls[3].Query.Get("id"), ","), HasLen, 50)
+	c.Assert(strings.Split(calls[4].Query.Get("id"), ","), HasLen, 10)
+
+	f.handleJSON("GET", "/episodes/display", 503, ``)
+	c.Assert(bs.PinEpisode(1), IsNil)
+	_, _, err = bs.PinnedEpisodes(ctx)
+	c.Assert(err, NotNil)
+	ids, err = store.Get()
+	c.Assert(err, IsNil)
+	c.Assert(ids, DeepEquals, []int{1})
+}
@@ -1,19 +1,32 @@
 package bsclient
 
 import (
-	"net/url"
+	"context"
+	"errors"
+	"sort"
 	"strconv"
 )
 
+// EpisodeWithShow is an episode along with its show.
+type EpisodeWithShow struct {
+	Episode Episode
+	// Show does not list its unseen episodes
+	Show Show
+}
+
+// episodeShow returns the show of the episode, as far as the episode
+// describes it.
+func episodeShow(e *Episode) Show {
+	return Show{ID: e.Show.ID, ThetvdbID: e.Show.ThetvdbID, Title: e.Show.Title}
+}
+
 // PlanningGeneral returns a slice of episodes found in [date-before, date+after] timeline.
 // Note: the 'date' input must be in YYYY-MM-JJ format or 'now'
 // 'eType', the episode type, can be 'premiere' or 'all', or empty.
-func (bs *BetaSeries) PlanningGeneral(date, eType string, before, after int) ([]Episode, error) {
+// An empty result is reported with ErrNoEpisodesFound.
+func (bs *BetaSeries) PlanningGeneral(ctx context.Context, date, eType string, before, after int) ([]Episode, error) {
 	usedAPI := "/planning/general"
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 	q.Set("date", date)
 	q.Set("before", strconv.Itoa(before))
@@ -22,18 +35,16 @@ func (bs *BetaSeries) PlanningGeneral(date, eType string, before, after int) ([]
 		q.Set("type", eType)
 	}
 	u.RawQuery = q.Encode()
-	return bs.doGetEpisodes(u, usedAPI)
+	return bs.doGetEpisodes(ctx, u, usedAPI)
 }
 
 // PlanningIncoming returns a slice of the first episodes of each tv show
 // that are about to be broacasted.
-func (bs *BetaSeries) PlanningIncoming() ([]Episode, error) {
+// An empty result is reported with ErrNoEpisodesFound.
+func (bs *BetaSeries) PlanningIncoming(ctx context.Context) ([]Episode, error) {
 	usedAPI := "/planning/incoming"
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
-	return bs.doGetEpisodes(u, usedAPI)
+	u := bs.endpoint(usedAPI)
+	return bs.doGetEpisodes(ctx, u, usedAPI)
 }
 
 // PlanningMember returns a slice of episodes of the member 'id'.
@@ -42,12 +53,11 @@ func (bs *BetaSeries) PlanningIncoming() ([]Episode, error) {
 // The parameter 'unseen' filters not seen episodes.
 // The parameter 'month' filters episodes of the given month with the format YYYY-MM.
 // Note: the 'month' value can be the string "now".
-func (bs *BetaSeries) PlanningMember(id int, unseen bool, month string) ([]Episode, error) {
+// The error matches ErrMemberNotFound if the member 'id' does not exist and
+// ErrProfilePrivate if their profile is private.
+func (bs *BetaSeries) PlanningMember(ctx context.Context, id int, unseen bool, month string) ([]Episode, error) {
 	usedAPI := "/planning/member"
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 	if id > 0 {
 		q.Set("id", strconv.Itoa(id))
@@ -59,5 +69,40 @@ func (bs *BetaSeries) PlanningMember(id int, unseen bool, month string) ([]Episo
 		q.Set("month", month)
 	}
 	u.RawQuery = q.Encode()
-	return bs.doGetEpisodes(u, usedAPI)
+	episodes, err := bs.doGetEpisodes(ctx, u, usedAPI)
+	return episodes, memberError(id, err)
+}
+
+// PlanningReadyToWatch returns the episodes of the authenticated member ready
+// to be watched: aired, not seen yet, not specials, and having subtitles in
+// the language 'lang' (any language for LanguageAll). Episodes are sorted by
+// air date.
+// The episodes are listed with released=1, which only returns aired
+// episodes, and subtitles=true, which embeds their subtitles without
+// filtering them: the language is matched by the client.
+func (bs *BetaSeries) PlanningReadyToWatch(ctx context.Context, lang Language) ([]EpisodeWithShow, error) {
+	shows, err := bs.EpisodesList(ctx, 0, 0, "", 0, 0, 1, true, false)
+	if err != nil && !errors.Is(err, ErrNoShowsFound) {
+		return nil, err
+	}
+	out := []EpisodeWithShow{}
+	for _, show := range shows {
+		unseen := show.Unseen
+		show.Unseen = nil
+		for _, episode := range unseen {
+			if episode.User.Seen || !counted(&episode) || !isAired(&episode) {
+				continue
+			}
+			for _, subtitle := range episode.Subtitles {
+				if lang.matches(subtitle.Language) {
+					out = append(out, EpisodeWithShow{episode, show})
+					break
+				}
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return lessDate(out[i].Episode.Date, out[j].Episode.Date)
+	})
+	return out, nil
 }
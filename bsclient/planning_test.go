@@ -7,16 +7,9 @@ import (
 	. "gopkg.in/check.v1"
 )
 
-var (
-	err0 = errorsAPI{
-		Code: 0,
-		Text: "Aucun utilisateur sélectionné.",
-	}
-)
-
 func checkEpisode(c *C, err error, episode *Episode) {
 	c.Assert(err, IsNil)
-	c.Assert(len(episode.Date), Equals, 10)
+	c.Assert(len(episode.Date.String()), Equals, 10)
 	c.Assert(strings.Contains(episode.Code, "S"), Equals, true)
 	c.Assert(strings.Contains(episode.Code, "E"), Equals, true)
 }
@@ -25,28 +18,28 @@ func (s *MySuite) TestPlanningGeneral(c *C) {
 	key := os.Getenv("BS_API_KEY")
 	bs, err := NewBetaseriesClient(key, "", "")
 	c.Assert(err, IsNil)
-	episodes, err := bs.PlanningGeneral("now", 1, 1)
+	episodes, err := bs.PlanningGeneral(ctx, "now", "", 1, 1)
 	if len(episodes) > 0 {
 		checkEpisode(c, err, &episodes[0])
 	} else {
 		c.Assert(err, NotNil)
 	}
 
-	episodes, err = bs.PlanningGeneral("1000-01-01", 1, 1)
+	episodes, err = bs.PlanningGeneral(ctx, "1000-01-01", "", 1, 1)
 	c.Assert(err, NotNil)
-	c.Assert(err, Equals, errNoEpisodesFound)
+	c.Assert(err, Equals, ErrNoEpisodesFound)
 }
 
 func (s *MySuite) TestPlanningIncoming(c *C) {
 	key := os.Getenv("BS_API_KEY")
 	bs, err := NewBetaseriesClient(key, "", "")
 	c.Assert(err, IsNil)
-	episodes, err := bs.PlanningIncoming()
+	episodes, err := bs.PlanningIncoming(ctx)
 	if len(episodes) > 0 {
 		checkEpisode(c, err, &episodes[0])
 	} else {
 		c.Assert(err, NotNil)
-		c.Assert(err, Equals, errNoEpisodesFound)
+		c.Assert(err, Equals, ErrNoEpisodesFound)
 	}
 }
 
@@ -54,68 +47,108 @@ func (s *MySuite) TestPlanningMember(c *C) {
 	key := os.Getenv("BS_API_KEY")
 	bs, err := NewBetaseriesClient(key, "", "")
 	c.Assert(err, IsNil)
-	episodes, err := bs.PlanningMember(0, false, "")
+	episodes, err := bs.PlanningMember(ctx, 0, false, "")
 	if len(episodes) > 0 {
 		checkEpisode(c, err, &episodes[0])
 	} else {
 		c.Assert(err, NotNil)
-		c.Assert(err, DeepEquals, &errAPI{
-			[]errorsAPI{err0},
-		})
+		c.Assert(err, Equals, ErrNotAuthenticated)
 	}
 
-	episodes, err = bs.PlanningMember(-1, false, "")
+	episodes, err = bs.PlanningMember(ctx, -1, false, "")
 	if len(episodes) > 0 {
 		checkEpisode(c, err, &episodes[0])
 	} else {
 		c.Assert(err, NotNil)
-		c.Assert(err, DeepEquals, &errAPI{
-			[]errorsAPI{err0},
-		})
+		c.Assert(err, Equals, ErrNotAuthenticated)
 	}
 
-	episodes, err = bs.PlanningMember(-1, false, "1000-01")
+	episodes, err = bs.PlanningMember(ctx, -1, false, "1000-01")
 	c.Assert(err, NotNil)
-	c.Assert(err, DeepEquals, &errAPI{
-		[]errorsAPI{err0},
-	})
+	c.Assert(err, Equals, ErrNotAuthenticated)
 
-	episodes, err = bs.PlanningMember(-1, false, "Wrong format")
+	episodes, err = bs.PlanningMember(ctx, -1, false, "Wrong format")
 	c.Assert(err, NotNil)
-	c.Assert(err, DeepEquals, &errAPI{
-		[]errorsAPI{err0},
-	})
+	c.Assert(err, Equals, ErrNotAuthenticated)
 }
 
 func (s *MySuite) TestPlanningMemberWithCredentials(c *C) {
 	key := os.Getenv("BS_API_KEY")
 	bs, err := NewBetaseriesClient(key, "Dev050", "developer")
 	c.Assert(err, IsNil)
-	episodes, err := bs.PlanningMember(0, false, "")
+	episodes, err := bs.PlanningMember(ctx, 0, false, "")
 	if len(episodes) > 0 {
 		checkEpisode(c, err, &episodes[0])
 	} else {
 		c.Assert(err, NotNil)
-		c.Assert(err, Equals, errNoEpisodesFound)
+		c.Assert(err, Equals, ErrNoEpisodesFound)
 	}
 
-	episodes, err = bs.PlanningMember(-1, false, "")
+	episodes, err = bs.PlanningMember(ctx, -1, false, "")
 	if len(episodes) > 0 {
 		checkEpisode(c, err, &episodes[0])
 	} else {
 		c.Assert(err, NotNil)
-		c.Assert(err, Equals, errNoEpisodesFound)
+		c.Assert(err, Equals, ErrNoEpisodesFound)
 	}
 
-	episodes, err = bs.PlanningMember(-1, false, "1000-01")
+	episodes, err = bs.PlanningMember(ctx, -1, false, "1000-01")
 	c.Assert(err, NotNil)
-	c.Assert(err, Equals, errNoEpisodesFound)
+	c.Assert(err, Equals, ErrNoEpisodesFound)
 
-	episodes, err = bs.PlanningMember(-1, false, "Wrong format")
+	episodes, err = bs.PlanningMember(ctx, -1, false, "Wrong format")
 	c.Assert(err, NotNil)
 	c.Assert(err.Error(), Equals, "DateTime::__construct(): Failed to parse time string (Wrong format) at position 0 (W): The timezone could not be found in the database\n")
 
-	episodes, err = bs.PlanningMember(-1, false, "now")
+	episodes, err = bs.PlanningMember(ctx, -1, false, "now")
 	c.Assert(err, NotNil)
-	c.Assert(err, Equals, errNoEpisodesFound)
+	c.Assert(err, Equals, ErrNoEpisodesFound)
+}
+
+func (s *MySuite) TestPlanningReadyToWatch(c *C) {
+	defer fixedNow("2016-06-01")()
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/episodes/list", 200, `{"shows":[
+		{"id":1,"title":"Breaking Bad","unseen":[
+			{"id":11,"season":1,"episode":1,"date":"2016-05-10","subtitles":[{"language":"VO"},{"language":"VF"}]},
+			{"id":12,"season":1,"episode":2,"date":"2016-05-17","subtitles":[{"language":"VO"}]},
+			{"id":13,"season":0,"episode":1,"date":"2016-05-01","subtitles":[{"language":"VF"}]},
+			{"id":14,"season":1,"episode":3,"date":"2016-07-01","subtitles":[{"language":"VF"}]}
+		]},
+		{"id":2,"title":"Gotham","unseen":[
+			{"id":21,"season":2,"episode":5,"date":"2016-05-12","subtitles":[{"language":"VF"}]},
+			{"id":22,"season":2,"episode":6,"date":"2016-05-13","subtitles":[]},
+			{"id":23,"season":2,"episode":7,"date":"2016-05-14","user":{"seen":true},"subtitles":[{"language":"VF"}]}
+		]}
+	],"errors":[]}`)
+	bs := f.client(c)
+
+	episodes, err := bs.PlanningReadyToWatch(ctx, LanguageVF)
+	c.Assert(err, IsNil)
+	c.Assert(episodes, HasLen, 2)
+	c.Assert(episodes[0].Episode.ID, Equals, 11)
+	c.Assert(episodes[0].Show.Title, Equals, "Breaking Bad")
+	c.Assert(episodes[0].Show.Unseen, IsNil)
+	c.Assert(episodes[1].Episode.ID, Equals, 21)
+	c.Assert(episodes[1].Show.ID, Equals, 2)
+
+	calls := f.callsTo("/episodes/list")
+	c.Assert(calls, HasLen, 1)
+	c.Assert(calls[0].Query.Get("released"), Equals, "1")
+	c.Assert(calls[0].Query.Get("subtitles"), Equals, "true")
+	c.Assert(calls[0].Query.Get("specials"), Equals, "")
+
+	episodes, err = bs.PlanningReadyToWatch(ctx, LanguageAll)
+	c.Assert(err, IsNil)
+	ids := []int{}
+	for _, episode := range episodes {
+		ids = append(ids, episode.Episode.ID)
+	}
+	c.Assert(ids, DeepEquals, []int{11, 21, 12})
+
+	f.handleJSON("GET", "/episodes/list", 200, `{"shows":[],"errors":[]}`)
+	episodes, err = bs.PlanningReadyToWatch(ctx, LanguageVO)
+	c.Assert(err, IsNil)
+	c.Assert(episodes, HasLen, 0)
 }
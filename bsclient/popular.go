@@ -0,0 +1,189 @@
+package bsclient
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+const (
+	// popularFriendsConcurrency is the number of friends whose shows are
+	// requested at once by FriendsPopularShows
+	popularFriendsConcurrency = 4
+	// maxPopularFriends is the number of friends read by FriendsPopularShows
+	maxPopularFriends = 100
+	// defaultPopularLimit is the number of shows returned by
+	// FriendsPopularShows when the limit is not set
+	defaultPopularLimit = 10
+)
+
+// ShowWithFriendCount is a show followed by friends of the member, see
+// FriendsPopularShows.
+type ShowWithFriendCount struct {
+	Show Show
+	// Friends is the number of friends following the show
+	Friends int
+}
+
+// FriendsPopularMeta tells how FriendsPopularShows built its result.
+type FriendsPopularMeta struct {
+	// Friends is the number of friends whose shows were read
+	Friends int
+	// Skipped is the number of friends whose shows could not be read, their
+	// profile being private or deleted
+	Skipped int
+	// Truncated reports whether friends were left out, only the first 100
+	// being read
+	Truncated bool
+	// Requests is the number of requests sent
+	Requests int
+}
+
+// FriendsPopular is the result of FriendsPopularShows.
+type FriendsPopular struct {
+	Shows []ShowWithFriendCount
+	Meta  FriendsPopularMeta
+}
+
+// FriendsPopularShows returns the shows followed by at least 'minFriends'
+// friends of the authenticated member (1 if not positive) which the member
+// does not follow, the most followed by friends first, then the most
+// followed overall. At most 'limit' shows are returned, 10 if not positive
+// and 50 at most, with their details from the multiple display: only the
+// 50 shows followed by the most friends (the lowest ids first on a tie)
+// are displayed, whatever the number of candidates.
+// The shows of the first 100 friends are read, 4 friends at once: the
+// friends whose profile is private or deleted are skipped and counted in
+// the Meta of the result, other errors fail the call. The call costs at
+// most 103 requests.
+func (bs *BetaSeries) FriendsPopularShows(ctx context.Context, minFriends, limit int) (*FriendsPopular, error) {
+	if minFriends < 1 {
+		minFriends = 1
+	}
+	if limit < 1 {
+		limit = defaultPopularLimit
+	}
+	if limit > displayChunkSize {
+		limit = displayChunkSize
+	}
+	result := &FriendsPopular{}
+	friends, err := bs.FriendsList(ctx, 0, false)
+	result.Meta.Requests++
+	if errors.Is(err, ErrNoMembersFound) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(friends) > maxPopularFriends {
+		friends = friends[:maxPopularFriends]
+		result.Meta.Truncated = true
+	}
+	me, err := bs.MembersInfos(ctx, 0, false, "shows")
+	result.Meta.Requests++
+	if err != nil {
+		return nil, err
+	}
+	followed := map[int]bool{}
+	if me != nil {
+		for _, show := range me.Shows {
+			followed[show.ID] = true
+		}
+	}
+
+	counts, err := bs.countFriendsShows(ctx, friends, &result.Meta)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	for id, count := range counts {
+		if count >= minFriends && !followed[id] {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return result, nil
+	}
+	// display a single chunk of the shows followed by the most friends
+	sort.Slice(ids, func(i, j int) bool {
+		if counts[ids[i]] != counts[ids[j]] {
+			return counts[ids[i]] > counts[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	if len(ids) > displayChunkSize {
+		ids = ids[:displayChunkSize]
+	}
+	shows, err := bs.ShowsDisplay(ctx, ids)
+	result.Meta.Requests++
+	var batchErr BatchError
+	if err != nil && !errors.As(err, &batchErr) {
+		return nil, err
+	}
+	for _, show := range shows {
+		result.Shows = append(result.Shows, ShowWithFriendCount{Show: show, Friends: counts[show.ID]})
+	}
+	sort.SliceStable(result.Shows, func(i, j int) bool {
+		a, b := &result.Shows[i], &result.Shows[j]
+		if a.Friends != b.Friends {
+			return a.Friends > b.Friends
+		}
+		if a.Show.Followers != b.Show.Followers {
+			return a.Show.Followers > b.Show.Followers
+		}
+		return a.Show.ID < b.Show.ID
+	})
+	if len(result.Shows) > limit {
+		result.Shows = result.Shows[:limit]
+	}
+	return result, nil
+}
+
+// countFriendsShows returns the number of 'friends' following each show, by
+// show id, updating the counters of 'meta'.
+func (bs *BetaSeries) countFriendsShows(ctx context.Context, friends []Member, meta *FriendsPopularMeta) (map[int]int, error) {
+	var mu sync.Mutex
+	counts := map[int]int{}
+	var firstErr error
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, popularFriendsConcurrency)
+	for _, friend := range friends {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			member, err := bs.MembersInfos(ctx, id, false, "shows")
+			mu.Lock()
+			defer mu.Unlock()
+			meta.Requests++
+			switch {
+			case err == nil:
+				meta.Friends++
+				if member == nil {
+					break
+				}
+				for _, show := range member.Shows {
+					counts[show.ID]++
+				}
+			case errors.Is(err, ErrProfilePrivate), errors.Is(err, ErrMemberNotFound):
+				meta.Skipped++
+			case firstErr == nil:
+				firstErr = err
+			}
+		}(friend.ID)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return counts, err
+	}
+	return counts, firstErr
+}
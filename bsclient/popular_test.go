@@ -0,0 +1,129 @@
+package bsclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+// popularShows are the shows of the member (id 0) and of their friends, the
+// friend 4 having a private profile.
+var popularShows = map[string]string{
+	"":  `{"member":{"id":9,"shows":[{"id":1}]},"errors":[]}`,
+	"1": `{"member":{"id":1,"shows":[{"id":1},{"id":2},{"id":3}]},"errors":[]}`,
+	"2": `{"member":{"id":2,"shows":[{"id":2},{"id":3},{"id":5}]},"errors":[]}`,
+	"3": `{"member":{"id":3,"shows":[{"id":2},{"id":5},{"id":6}]},"errors":[]}`,
+	"5": `{"member":{"id":5,"shows":[{"id":6}]},"errors":[]}`,
+}
+
+func (s *MySuite) TestFriendsPopularShows(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/friends/list", 200,
+		`{"users":[{"id":1},{"id":2},{"id":3},{"id":4},{"id":5}],"errors":[]}`)
+	f.handle("GET", "/members/infos", func(w http.ResponseWriter, r *http.Request) {
+		body, ok := popularShows[r.URL.Query().Get("id")]
+		if !ok {
+			writeFakeJSON(w, 400, `{"errors":[{"code":2003,"text":"Profil privé."}]}`)
+			return
+		}
+		writeFakeJSON(w, 200, body)
+	})
+	// the show 6 is missing
+	f.handleJSON("GET", "/shows/display", 200, `{"shows":[`+
+		`{"id":2,"title":"Two","followers":"10"},{"id":3,"title":"Three","followers":"100"},`+
+		`{"id":5,"title":"Five","followers":"300"}],"errors":[{"code":4001,"text":"Série introuvable."}]}`)
+	bs := f.client(c)
+
+	popular, err := bs.FriendsPopularShows(ctx, 2, 2)
+	c.Assert(err, IsNil)
+	c.Assert(popular.Shows, HasLen, 2)
+	c.Assert(popular.Shows[0].Show.ID, Equals, 2)
+	c.Assert(popular.Shows[0].Friends, Equals, 3)
+	c.Assert(popular.Shows[1].Show.ID, Equals, 5)
+	c.Assert(popular.Shows[1].Friends, Equals, 2)
+	c.Assert(popular.Meta, Equals, FriendsPopularMeta{Friends: 4, Skipped: 1, Requests: 8})
+	c.Assert(f.calls, HasLen, 8)
+	displayed := strings.Split(f.callsTo("/shows/display")[0].Query.Get("id"), ",")
+	sort.Strings(displayed)
+	c.Assert(displayed, DeepEquals, []string{"2", "3", "5", "6"})
+	for _, call := range f.callsTo("/members/infos") {
+		c.Assert(call.Query.Get("only"), Equals, "shows")
+	}
+
+	popular, err = bs.FriendsPopularShows(ctx, 0, 0)
+	c.Assert(err, IsNil)
+	ids := []int{}
+	for _, show := range popular.Shows {
+		ids = append(ids, show.Show.ID)
+	}
+	c.Assert(ids, DeepEquals, []int{2, 5, 3})
+
+	// no friends
+	f.handleJSON("GET", "/friends/list", 200, `{"users":[],"errors":[]}`)
+	popular, err = bs.FriendsPopularShows(ctx, 2, 10)
+	c.Assert(err, IsNil)
+	c.Assert(popular.Shows, HasLen, 0)
+	c.Assert(popular.Meta, Equals, FriendsPopularMeta{Requests: 1})
+
+	// errors other than private profiles fail the call
+	f.handleJSON("GET", "/friends/list", 200, `{"users":[{"id":1},{"id":2}],"errors":[]}`)
+	f.handleJSON("GET", "/members/infos", 400, `{"errors":[{"code":2001,"text":"Token invalide."}]}`)
+	_, err = bs.FriendsPopularShows(ctx, 2, 10)
+	c.Assert(errors.Is(err, ErrTokenInvalid), Equals, true)
+}
+
+func (s *MySuite) TestFriendsPopularShowsBounded(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	var friends []string
+	for id := 1; id <= 120; id++ {
+		friends = append(friends, fmt.Sprintf(`{"id":%d}`, id))
+	}
+	f.handleJSON("GET", "/friends/list", 200, `{"users":[`+strings.Join(friends, ",")+`],"errors":[]}`)
+	// each friend follows 5 shows of their own and the shows 1 to 3, the
+	// first friend follows the show 2 only; the member has no shows field
+	f.handle("GET", "/members/infos", func(w http.ResponseWriter, r *http.Request) {
+		id, _ := strconv.Atoi(r.URL.Query().Get("id"))
+		switch id {
+		case 0:
+			writeFakeJSON(w, 200, `{"errors":[]}`)
+		case 1:
+			writeFakeJSON(w, 200, `{"member":{"id":1,"shows":[{"id":2}]},"errors":[]}`)
+		default:
+			shows := []string{`{"id":1}`, `{"id":2}`, `{"id":3}`}
+			for i := 0; i < 5; i++ {
+				shows = append(shows, fmt.Sprintf(`{"id":%d}`, 1000+5*id+i))
+			}
+			writeFakeJSON(w, 200, fmt.Sprintf(`{"member":{"id":%d,"shows":[%s]},"errors":[]}`,
+				id, strings.Join(shows, ",")))
+		}
+	})
+	f.handleJSON("GET", "/shows/display", 200, `{"shows":[{"id":1},{"id":2},{"id":3}],"errors":[]}`)
+	bs := f.client(c)
+
+	popular, err := bs.FriendsPopularShows(ctx, 1, 50)
+	c.Assert(err, IsNil)
+	c.Assert(popular.Meta, Equals, FriendsPopularMeta{Friends: 100, Truncated: true, Requests: 103})
+	c.Assert(f.calls, HasLen, 103)
+	displays := f.callsTo("/shows/display")
+	c.Assert(displays, HasLen, 1)
+	displayed := strings.Split(displays[0].Query.Get("id"), ",")
+	c.Assert(displayed, HasLen, 50)
+	c.Assert(displayed[:4], DeepEquals, []string{"2", "1", "3", "1010"})
+	c.Assert(popular.Shows[0].Show.ID, Equals, 2)
+	c.Assert(popular.Shows[0].Friends, Equals, 100)
+	c.Assert(popular.Shows[1].Friends, Equals, 99)
+
+	// a friend without a member in the response counts without shows
+	f.handleJSON("GET", "/members/infos", 200, `{"errors":[]}`)
+	popular, err = bs.FriendsPopularShows(ctx, 1, 50)
+	c.Assert(err, IsNil)
+	c.Assert(popular.Shows, HasLen, 0)
+	c.Assert(popular.Meta.Friends, Equals, 100)
+}
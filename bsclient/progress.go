@@ -0,0 +1,129 @@
+package bsclient
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// timeNow is the clock used to tell aired episodes, replaced in tests.
+var timeNow = time.Now
+
+// isAired reports whether the episode air date is known and not after today,
+// in the time zone of the API.
+func isAired(e *Episode) bool {
+	today := timeNow().In(apiLocation).Format(apiDateLayout)
+	return !e.Date.IsZero() && e.Date.Time().Format(apiDateLayout) <= today
+}
+
+// counted reports whether the episode is taken into account in the
+// progression of the member: specials (and season 0) are left out, as the
+// official applications do by default.
+func counted(e *Episode) bool {
+	return e.Special == 0 && e.Season > 0
+}
+
+// SeasonRemaining returns the number of episodes left to watch per season
+// of the show 'ref', seasons having nothing left being omitted.
+// An episode is left to watch when it is aired, not seen and not a special.
+// Note: the episodes payload does not tell which seasons the member hid,
+// so hidden seasons are counted like the others.
+func (bs *BetaSeries) SeasonRemaining(ctx context.Context, ref ShowRef) (map[int]int, error) {
+	episodes, err := bs.showEpisodes(ctx, ref, 0, 0, false)
+	if err != nil && !errors.Is(err, ErrNoEpisodesFound) {
+		return nil, err
+	}
+	remaining := map[int]int{}
+	for i := range episodes {
+		episode := &episodes[i]
+		if counted(episode) && isAired(episode) && !episode.User.Seen {
+			remaining[episode.Season]++
+		}
+	}
+	return remaining, nil
+}
+
+// ShowRatingsMatrix returns the mean note of every episode of the show 'ref',
+// arranged by season: row i holds season i and column j episode j+1. Rows
+// are as long as their season, and unaired or unrated episodes are set to -1.
+// Specials (season 0) are left out unless 'specials' is true, in which case
+// they fill row 0. The mean of the rated episodes is returned as well, for
+// normalization.
+func (bs *BetaSeries) ShowRatingsMatrix(ctx context.Context, ref ShowRef, specials bool) ([][]float32, float32, error) {
+	episodes, err := bs.showEpisodes(ctx, ref, 0, 0, false)
+	if err != nil && !errors.Is(err, ErrNoEpisodesFound) {
+		return nil, 0, err
+	}
+	matrix := [][]float32{}
+	var total float32
+	rated := 0
+	for i := range episodes {
+		episode := &episodes[i]
+		if !specials && !counted(episode) {
+			continue
+		}
+		if episode.Season < 0 || episode.Episode < 1 {
+			continue
+		}
+		for len(matrix) <= episode.Season {
+			matrix = append(matrix, []float32{})
+		}
+		row := matrix[episode.Season]
+		for len(row) < episode.Episode {
+			row = append(row, -1)
+		}
+		matrix[episode.Season] = row
+		if isAired(episode) && episode.Note.Mean > 0 {
+			row[episode.Episode-1] = episode.Note.Mean
+			total += episode.Note.Mean
+			rated++
+		}
+	}
+	if rated == 0 {
+		return matrix, 0, nil
+	}
+	return matrix, total / float32(rated), nil
+}
+
+// ShowBacklog holds the aired episodes of a show the member has not seen
+// yet, split on whether they are downloaded.
+type ShowBacklog struct {
+	Show          Show
+	Downloaded    []Episode
+	NotDownloaded []Episode
+}
+
+// DownloadBacklog returns, for every show of the authenticated member with
+// aired episodes left to watch, the unseen episodes already downloaded and
+// those not downloaded yet, in the order of the member's episode list.
+// It sends a single episodes/list request, which carries the downloaded
+// flag of every unseen episode. Specials are left out.
+func (bs *BetaSeries) DownloadBacklog(ctx context.Context) ([]ShowBacklog, error) {
+	shows, err := bs.EpisodesList(ctx, 0, 0, "", 0, 0, 1, false, false)
+	if err != nil {
+		if errors.Is(err, ErrNoShowsFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var backlog []ShowBacklog
+	for _, show := range shows {
+		b := ShowBacklog{Show: show}
+		for _, e := range show.Unseen {
+			if e.User.Seen || !counted(&e) || !isAired(&e) {
+				continue
+			}
+			if e.User.Downloaded {
+				b.Downloaded = append(b.Downloaded, e)
+			} else {
+				b.NotDownloaded = append(b.NotDownloaded, e)
+			}
+		}
+		if len(b.Downloaded)+len(b.NotDownloaded) == 0 {
+			continue
+		}
+		b.Show.Unseen = nil
+		backlog = append(backlog, b)
+	}
+	return backlog, nil
+}
@@ -0,0 +1,212 @@
+package bsclient
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// fixedNow sets the clock used by the progress helpers for the duration of
+// a test.
+func fixedNow(date string) func() {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		panic(err)
+	}
+	timeNow = func() time.Time { return t }
+	return func() { timeNow = time.Now }
+}
+
+// progressEpisodes has two seasons of uneven length, a special, an unseen
+// unaired episode and an episode without date.
+const progressEpisodes = `{"episodes":[
+	{"id":1,"season":1,"episode":1,"date":"2016-01-01","special":0,"user":{"seen":true},"note":{"mean":4.5}},
+	{"id":2,"season":1,"episode":2,"date":"2016-01-08","special":0,"user":{"seen":false},"note":{"mean":3}},
+	{"id":3,"season":1,"episode":3,"date":"2016-01-15","special":0,"user":{"seen":false},"note":{"mean":0}},
+	{"id":4,"season":0,"episode":1,"date":"2016-01-20","special":1,"user":{"seen":false},"note":{"mean":2}},
+	{"id":5,"season":2,"episode":1,"date":"2016-02-01","special":0,"user":{"seen":false},"note":{"mean":4}},
+	{"id":6,"season":2,"episode":2,"date":"2016-12-01","special":0,"user":{"seen":false},"note":{"mean":0}},
+	{"id":7,"season":2,"episode":3,"date":"","special":0,"user":{"seen":false},"note":{"mean":0}}
+],"errors":[]}`
+
+func (s *MySuite) TestSeasonRemaining(c *C) {
+	defer fixedNow("2016-06-01")()
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/episodes", 200, progressEpisodes)
+	bs := f.client(c)
+
+	remaining, err := bs.SeasonRemaining(ctx, ShowRef{ID: 1})
+	c.Assert(err, IsNil)
+	c.Assert(remaining, DeepEquals, map[int]int{1: 2, 2: 1})
+	c.Assert(f.callsTo("/shows/episodes"), HasLen, 1)
+
+	// everything watched
+	f.handleJSON("GET", "/shows/episodes", 200,
+		`{"episodes":[{"id":1,"season":1,"episode":1,"date":"2016-01-01","user":{"seen":true}}],"errors":[]}`)
+	remaining, err = bs.SeasonRemaining(ctx, ShowRef{ID: 1})
+	c.Assert(err, IsNil)
+	c.Assert(remaining, HasLen, 0)
+
+	_, err = bs.SeasonRemaining(ctx, ShowRef{})
+	c.Assert(err, Equals, ErrIDNotProperlySet)
+}
+
+func (s *MySuite) TestShowRatingsMatrix(c *C) {
+	defer fixedNow("2016-06-01")()
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/episodes", 200, progressEpisodes)
+	bs := f.client(c)
+
+	matrix, mean, err := bs.ShowRatingsMatrix(ctx, ShowRef{ID: 1}, false)
+	c.Assert(err, IsNil)
+	c.Assert(matrix, DeepEquals, [][]float32{
+		{},
+		{4.5, 3, -1},
+		{4, -1, -1},
+	})
+	c.Assert(mean, Equals, float32(11.5)/3)
+
+	matrix, mean, err = bs.ShowRatingsMatrix(ctx, ShowRef{ID: 1}, true)
+	c.Assert(err, IsNil)
+	c.Assert(matrix[0], DeepEquals, []float32{2})
+	c.Assert(mean, Equals, float32(13.5)/4)
+	c.Assert(f.callsTo("/shows/episodes"), HasLen, 2)
+}
+
+func (s *MySuite) TestShowRatingsMatrixRagged(c *C) {
+	defer fixedNow("2016-06-01")()
+	f := newFakeServer()
+	defer f.Close()
+	// season 1 is missing and season 3 has a gap
+	f.handleJSON("GET", "/shows/episodes", 200, `{"episodes":[
+		{"season":2,"episode":1,"date":"2016-01-01","note":{"mean":3}},
+		{"season":3,"episode":3,"date":"2016-01-01","note":{"mean":5}}
+	],"errors":[]}`)
+	bs := f.client(c)
+	matrix, mean, err := bs.ShowRatingsMatrix(ctx, ShowRef{ID: 1}, false)
+	c.Assert(err, IsNil)
+	c.Assert(matrix, DeepEquals, [][]float32{{}, {}, {3}, {-1, -1, 5}})
+	c.Assert(mean, Equals, float32(4))
+}
+
+// announcedShow has been announced but has no episode yet.
+const announcedShow = `{"id":9,"title":"Announced","seasons":"0","seasons_details":[],
+	"episodes":"0","creation":"","status":"Continuing","genres":[],"notes":null}`
+
+func (s *MySuite) TestAnnouncedShow(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/display", 200, `{"show":`+announcedShow+`,"errors":[]}`)
+	f.handleJSON("GET", "/shows/episodes", 200, `{"episodes":[],"errors":[]}`)
+	f.handleJSON("GET", "/episodes/list", 200, `{"shows":[`+announcedShow+`],"errors":[]}`)
+	f.handleJSON("GET", "/shows/list", 200, `{"shows":[`+announcedShow+`],"errors":[]}`)
+	bs := f.client(c)
+	ref := ShowRef{ID: 9}
+
+	show, err := bs.ShowDisplay(ctx, 9, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.IsAnnouncedOnly(), Equals, true)
+	c.Assert(FingerprintOf(*show).Episodes, Equals, FlexInt(0))
+
+	_, err = bs.ShowsEpisodes(ctx, 9, 0, 0, 0, false)
+	c.Assert(errors.Is(err, ErrNotFound), Equals, true)
+
+	remaining, err := bs.SeasonRemaining(ctx, ref)
+	c.Assert(err, IsNil)
+	c.Assert(remaining, HasLen, 0)
+
+	matrix, mean, err := bs.ShowRatingsMatrix(ctx, ref, true)
+	c.Assert(err, IsNil)
+	c.Assert(matrix, HasLen, 0)
+	c.Assert(mean, Equals, float32(0))
+
+	c.Assert(AbsoluteNumbers(nil), HasLen, 0)
+	_, err = bs.EpisodeByAbsoluteNumber(ctx, ref, 1)
+	c.Assert(errors.Is(err, ErrNotFound), Equals, true)
+	_, err = bs.MarkWatchedUpTo(ctx, ref, 1, 1)
+	c.Assert(errors.Is(err, ErrNotFound), Equals, true)
+	c.Assert(f.callsTo("/episodes/watched"), HasLen, 0)
+
+	// listings keep the show
+	ready, err := bs.PlanningReadyToWatch(ctx, LanguageAll)
+	c.Assert(err, IsNil)
+	c.Assert(ready, HasLen, 0)
+	shows, err := bs.ShowsList(ctx, "", "", "", 0, 0)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 1)
+	SortShows(shows, ShowsByNextAirDate)
+	c.Assert(shows[0].ID, Equals, 9)
+}
+
+func (s *MySuite) TestIsAnnouncedOnly(c *C) {
+	for _, test := range []struct {
+		show     string
+		expected bool
+	}{
+		{announcedShow, true},
+		{`{"episodes":"0","status":"continuing"}`, true},
+		{`{"episodes":"","status":""}`, false},
+		{`{"episodes":"0"}`, false},
+		{`{"id":9,"title":"Announced"}`, false},
+		{`{"episodes":"0","status":"Ended"}`, false},
+		{`{"episodes":"62","status":"Continuing"}`, false},
+		{`{"episodes":"0","status":"Continuing","seasons_details":[{"number":1,"episodes":10}]}`, false},
+	} {
+		show := &Show{}
+		c.Assert(json.Unmarshal([]byte(test.show), show), IsNil)
+		c.Assert(show.IsAnnouncedOnly(), Equals, test.expected, Commentf(test.show))
+	}
+}
+
+func (s *MySuite) TestDownloadBacklog(c *C) {
+	defer fixedNow("2017-06-01")()
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/episodes/list", 200, `{"shows":[
+		{"id":1,"title":"Breaking Bad","unseen":[
+			{"id":11,"season":1,"episode":2,"date":"2017-01-01","user":{"downloaded":true}},
+			{"id":12,"season":1,"episode":3,"date":"2017-01-08","user":{"downloaded":false}},
+			{"id":13,"season":1,"episode":4,"date":"2017-01-15","user":{"downloaded":true}},
+			{"id":14,"season":0,"episode":1,"date":"2017-01-15","user":{"downloaded":true}},
+			{"id":15,"season":1,"episode":5,"date":"2017-09-01","user":{"downloaded":false}}
+		]},
+		{"id":2,"title":"Lost","unseen":[
+			{"id":21,"season":1,"episode":1,"date":"2017-07-01"}
+		]},
+		{"id":3,"title":"Dexter","unseen":[
+			{"id":31,"season":2,"episode":1,"date":"2017-02-01"}
+		]}
+	],"errors":[]}`)
+	bs := f.client(c)
+
+	backlog, err := bs.DownloadBacklog(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(backlog, HasLen, 2)
+	c.Assert(backlog[0].Show.ID, Equals, 1)
+	c.Assert(backlog[0].Show.Unseen, IsNil)
+	c.Assert(episodeIDs(backlog[0].Downloaded), DeepEquals, []int{11, 13})
+	c.Assert(episodeIDs(backlog[0].NotDownloaded), DeepEquals, []int{12})
+	c.Assert(backlog[1].Show.ID, Equals, 3)
+	c.Assert(backlog[1].Downloaded, HasLen, 0)
+	c.Assert(episodeIDs(backlog[1].NotDownloaded), DeepEquals, []int{31})
+	calls := f.callsTo("/episodes/list")
+	c.Assert(calls, HasLen, 1)
+	c.Assert(calls[0].Query.Get("released"), Equals, "1")
+
+	f.handleJSON("GET", "/episodes/list", 200, `{"shows":[],"errors":[]}`)
+	backlog, err = bs.DownloadBacklog(ctx)
+	c.Assert(err, IsNil)
+	c.Assert(backlog, HasLen, 0)
+}
+
+func episodeIDs(episodes []Episode) []int {
+	ids := make([]int, len(episodes))
+	for i, e := range episodes {
+		ids[i] = e.ID
+	}
+	return ids
+}
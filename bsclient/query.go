@@ -0,0 +1,99 @@
+package bsclient
+
+import (
+	"sync"
+)
+
+// querySetter is implemented by url.Values and queryBuilder.
+type querySetter interface {
+	Set(key, value string)
+}
+
+// queryParam is a parameter of a queryBuilder.
+type queryParam struct {
+	key, value string
+}
+
+// queryBuilder builds the queries of the most requested endpoints without
+// the map of url.Values, reusing its buffers through queryBuilders. Encode
+// returns the same query as url.Values.Encode would.
+type queryBuilder struct {
+	params []queryParam
+	buf    []byte
+}
+
+var queryBuilders = sync.Pool{
+	New: func() interface{} {
+		return &queryBuilder{
+			params: make([]queryParam, 0, 8),
+			buf:    make([]byte, 0, 128),
+		}
+	},
+}
+
+// newQueryBuilder returns an empty builder, to release once encoded.
+func newQueryBuilder() *queryBuilder {
+	return queryBuilders.Get().(*queryBuilder)
+}
+
+// release returns the builder to the pool.
+func (b *queryBuilder) release() {
+	b.params = b.params[:0]
+	b.buf = b.buf[:0]
+	queryBuilders.Put(b)
+}
+
+// Set sets the parameter 'key' to 'value', replacing its values.
+func (b *queryBuilder) Set(key, value string) {
+	params := b.params[:0]
+	for _, p := range b.params {
+		if p.key != key {
+			params = append(params, p)
+		}
+	}
+	b.params = append(params, queryParam{key, value})
+}
+
+// Add adds 'value' to the values of the parameter 'key'.
+func (b *queryBuilder) Add(key, value string) {
+	b.params = append(b.params, queryParam{key, value})
+}
+
+// Encode returns the query, sorted by key, the values of a key keeping their
+// order.
+func (b *queryBuilder) Encode() string {
+	// insertion sort: stable, without allocation, and the queries are short
+	for i := 1; i < len(b.params); i++ {
+		for j := i; j > 0 && b.params[j].key < b.params[j-1].key; j-- {
+			b.params[j], b.params[j-1] = b.params[j-1], b.params[j]
+		}
+	}
+	b.buf = b.buf[:0]
+	for i, p := range b.params {
+		if i > 0 {
+			b.buf = append(b.buf, '&')
+		}
+		b.buf = appendQueryEscape(b.buf, p.key)
+		b.buf = append(b.buf, '=')
+		b.buf = appendQueryEscape(b.buf, p.value)
+	}
+	return string(b.buf)
+}
+
+// appendQueryEscape appends 's' escaped like url.QueryEscape does.
+func appendQueryEscape(buf []byte, s string) []byte {
+	const hex = "0123456789ABCDEF"
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			buf = append(buf, c)
+		case c == ' ':
+			buf = append(buf, '+')
+		default:
+			buf = append(buf, '%', hex[c>>4], hex[c&15])
+		}
+	}
+	return buf
+}
@@ -0,0 +1,80 @@
+package bsclient
+
+import (
+	"math/rand"
+	"net/url"
+	"testing/quick"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestQueryBuilder(c *C) {
+	q := newQueryBuilder()
+	defer q.release()
+	q.Set("summary", "true")
+	q.Set("id", "1")
+	q.Add("id", "2")
+	q.Set("title", "Les Revenants & co/é")
+	q.Set("summary", "false")
+	c.Assert(q.Encode(), Equals, "id=1&id=2&summary=false&title=Les+Revenants+%26+co%2F%C3%A9")
+}
+
+// TestQueryBuilderEncode checks the builder against url.Values with random
+// parameters, keys being drawn from a small set so that they repeat.
+func (s *MySuite) TestQueryBuilderEncode(c *C) {
+	keys := []string{"id", "thetvdb_id", "imdb_id", "limit", "a b", "é", ""}
+	same := func(values []string, picks []uint8, sets []bool) bool {
+		q := newQueryBuilder()
+		defer q.release()
+		expected := url.Values{}
+		for i, value := range values {
+			var key string
+			if i < len(picks) {
+				key = keys[int(picks[i])%len(keys)]
+			}
+			if i < len(sets) && sets[i] {
+				q.Set(key, value)
+				expected.Set(key, value)
+			} else {
+				q.Add(key, value)
+				expected.Add(key, value)
+			}
+		}
+		return q.Encode() == expected.Encode()
+	}
+	config := &quick.Config{MaxCount: 2000, Rand: rand.New(rand.NewSource(1))}
+	c.Assert(quick.Check(same, config), IsNil)
+
+	// every byte
+	q := newQueryBuilder()
+	defer q.release()
+	raw := make([]byte, 256)
+	for i := range raw {
+		raw[i] = byte(i)
+	}
+	q.Set("k", string(raw))
+	c.Assert(q.Encode(), Equals, url.Values{"k": {string(raw)}}.Encode())
+}
+
+func (s *MySuite) BenchmarkQueryValues(c *C) {
+	for i := 0; i < c.N; i++ {
+		q := url.Values{}
+		q.Set("released", "1")
+		q.Set("id", "1275")
+		q.Set("limit", "10")
+		q.Set("userId", "42")
+		_ = q.Encode()
+	}
+}
+
+func (s *MySuite) BenchmarkQueryBuilder(c *C) {
+	for i := 0; i < c.N; i++ {
+		q := newQueryBuilder()
+		q.Set("released", "1")
+		q.Set("id", "1275")
+		q.Set("limit", "10")
+		q.Set("userId", "42")
+		_ = q.Encode()
+		q.release()
+	}
+}
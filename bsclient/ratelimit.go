@@ -0,0 +1,96 @@
+package bsclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultRetryDelay is the wait before retrying a rate limited request
+	// when the API tells nothing
+	defaultRetryDelay = time.Second
+	// maxRetryDelay is the longest wait before retrying a rate limited
+	// request, the error is returned beyond
+	maxRetryDelay = time.Minute
+)
+
+// rateLimit is the quota of requests reported by the API headers.
+type rateLimit struct {
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+// RateLimit returns the quota of requests reported by the last response of
+// the API: the number of requests allowed, the number left and the time the
+// quota is reset. They are zero until a response holds the X-RateLimit-Limit,
+// X-RateLimit-Remaining and X-RateLimit-Reset headers.
+func (bs *BetaSeries) RateLimit() (limit, remaining int, reset time.Time) {
+	bs.rateMu.Lock()
+	defer bs.rateMu.Unlock()
+	return bs.rateLimit.limit, bs.rateLimit.remaining, bs.rateLimit.reset
+}
+
+// WithRateLimitRetry makes the client retry the rate limited requests, see
+// SetRateLimitRetry.
+func WithRateLimitRetry() Option {
+	return func(o *clientOptions) {
+		o.rateLimitRetry = true
+	}
+}
+
+// SetRateLimitRetry makes the requests rejected with the 429 status wait
+// for the delay given by the Retry-After header, one second without it, and
+// be sent once more. Delays over a minute are not waited for. The wait ends
+// with the context of the request. Disabled by default, the requests then
+// fail with an ErrRateLimited error.
+func (bs *BetaSeries) SetRateLimitRetry(enabled bool) {
+	bs.rateLimitRetry = enabled
+}
+
+// updateRateLimit keeps the quota reported by the headers of 'resp', if any.
+func (bs *BetaSeries) updateRateLimit(resp *http.Response) {
+	limit, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	current := rateLimit{limit: limit}
+	current.remaining, _ = strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		current.reset = time.Unix(reset, 0)
+	}
+	bs.rateMu.Lock()
+	bs.rateLimit = current
+	bs.rateMu.Unlock()
+}
+
+// retryDelay returns the delay before retrying the request answered by the
+// rate limited response 'resp', and false if it should not be retried.
+func retryDelay(resp *http.Response) (time.Duration, bool) {
+	delay := defaultRetryDelay
+	if value := resp.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			delay = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(value); err == nil {
+			delay = date.Sub(timeNow())
+		}
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, delay <= maxRetryDelay
+}
+
+// sleep waits for 'd', or returns the error of 'ctx' if it is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
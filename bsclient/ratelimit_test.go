@@ -0,0 +1,146 @@
+package bsclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// rateLimitedOnce answers 429 with the 'retryAfter' header to the first
+// request, then 200 with a show.
+func rateLimitedOnce(retryAfter string) http.HandlerFunc {
+	calls := 0
+	return func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Reset", "1496311200")
+		if calls == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			writeFakeJSON(w, http.StatusTooManyRequests, ``)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "59")
+		writeFakeJSON(w, http.StatusOK, `{"show":{"id":1161,"title":"Breaking Bad"},"errors":[]}`)
+	}
+}
+
+func (s *MySuite) TestRateLimit(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handle("GET", "/shows/display", rateLimitedOnce("0"))
+	bs := f.client(c)
+
+	limit, remaining, reset := bs.RateLimit()
+	c.Assert(limit, Equals, 0)
+	c.Assert(remaining, Equals, 0)
+	c.Assert(reset.IsZero(), Equals, true)
+
+	// disabled by default
+	_, err := bs.ShowDisplay(ctx, 1161, 0, "")
+	c.Assert(errors.Is(err, ErrRateLimited), Equals, true)
+	c.Assert(f.callsTo("/shows/display"), HasLen, 1)
+	limit, remaining, reset = bs.RateLimit()
+	c.Assert(limit, Equals, 60)
+	c.Assert(remaining, Equals, 0)
+	c.Assert(reset.Equal(time.Unix(1496311200, 0)), Equals, true)
+
+	show, err := bs.ShowDisplay(ctx, 1161, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.ID, Equals, 1161)
+	_, remaining, _ = bs.RateLimit()
+	c.Assert(remaining, Equals, 59)
+}
+
+func (s *MySuite) TestRateLimitRetry(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handle("GET", "/shows/display", rateLimitedOnce("0"))
+	bs, err := NewClient("key", WithBaseURL(f.URL), WithRateLimitRetry())
+	c.Assert(err, IsNil)
+
+	show, err := bs.ShowDisplay(ctx, 1161, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.ID, Equals, 1161)
+	c.Assert(f.callsTo("/shows/display"), HasLen, 2)
+
+	// retried once only
+	f.handle("GET", "/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "0")
+		writeFakeJSON(w, http.StatusTooManyRequests, ``)
+	})
+	_, err = bs.ShowDisplay(ctx, 1161, 0, "")
+	c.Assert(errors.Is(err, ErrRateLimited), Equals, true)
+	c.Assert(f.callsTo("/shows/display"), HasLen, 4)
+
+	// too long delays are not waited for
+	f.handle("GET", "/shows/display", rateLimitedOnce("3600"))
+	_, err = bs.ShowDisplay(ctx, 1161, 0, "")
+	c.Assert(errors.Is(err, ErrRateLimited), Equals, true)
+	c.Assert(f.callsTo("/shows/display"), HasLen, 5)
+
+	// the wait ends with the context
+	f.handle("GET", "/shows/display", rateLimitedOnce("30"))
+	timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = bs.ShowDisplay(timeout, 1161, 0, "")
+	c.Assert(err, Equals, context.DeadlineExceeded)
+	c.Assert(time.Since(start) < 10*time.Second, Equals, true)
+	c.Assert(f.callsTo("/shows/display"), HasLen, 6)
+
+	bs.SetRateLimitRetry(false)
+	f.handle("GET", "/shows/display", rateLimitedOnce("0"))
+	_, err = bs.ShowDisplay(ctx, 1161, 0, "")
+	c.Assert(errors.Is(err, ErrRateLimited), Equals, true)
+}
+
+func (s *MySuite) TestRetryDelay(c *C) {
+	defer fixedNow("2017-06-01")()
+	for _, test := range []struct {
+		retryAfter string
+		delay      time.Duration
+		retry      bool
+	}{
+		{"", time.Second, true},
+		{"5", 5 * time.Second, true},
+		{"-5", 0, true},
+		{"61", 61 * time.Second, false},
+		{"Thu, 01 Jun 2017 00:00:30 GMT", 30 * time.Second, true},
+		{"Wed, 31 May 2017 23:00:00 GMT", 0, true},
+		{"soon", time.Second, true},
+	} {
+		resp := &http.Response{Header: http.Header{}}
+		resp.Header.Set("Retry-After", test.retryAfter)
+		delay, retry := retryDelay(resp)
+		c.Assert(delay, Equals, test.delay, Commentf(test.retryAfter))
+		c.Assert(retry, Equals, test.retry, Commentf(test.retryAfter))
+	}
+}
+
+func (s *MySuite) TestRateLimitRetryCanceled(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handle("GET", "/shows/display", rateLimitedOnce("30"))
+	bs, err := NewClient("key", WithBaseURL(f.URL), WithRateLimitRetry())
+	c.Assert(err, IsNil)
+
+	// canceled during the wait before the retry
+	cancelDuring(c, 50*time.Millisecond, func(ctx context.Context) error {
+		_, err := bs.ShowDisplay(ctx, 1161, 0, "")
+		return err
+	})
+	c.Assert(f.callsTo("/shows/display"), HasLen, 1)
+
+	// canceled while waiting for the answer
+	f.setLatency(time.Minute)
+	cancelDuring(c, 50*time.Millisecond, func(ctx context.Context) error {
+		_, err := bs.ShowDisplay(ctx, 1161, 0, "")
+		return err
+	})
+}
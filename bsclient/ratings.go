@@ -0,0 +1,334 @@
+package bsclient
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	errUnsupportedRatingsFormat = newError(ErrInvalidInput, "unsupported ratings format")
+)
+
+// RatingsFormat is a ratings file format of another service.
+type RatingsFormat int
+
+// Formats supported by ImportRatings and ExportRatings
+const (
+	// RatingsIMDbCSV is the CSV export of the IMDb ratings, whose columns are
+	// identified by the header line ("Const", "Your Rating", "Title",
+	// "Title Type" and "Year" are used)
+	RatingsIMDbCSV RatingsFormat = iota + 1
+	// RatingsTraktJSON is the JSON export of the Trakt ratings, an array of
+	// items with a type, a rating and the rated show
+	RatingsTraktJSON
+)
+
+// RatingRow is a rating read from a ratings file.
+type RatingRow struct {
+	// Line is the line of the row in a CSV file, or the position of the item
+	// in a JSON file, starting at 1
+	Line      int
+	Title     string
+	Year      int
+	ImdbID    string
+	TheTvdbID int
+	// Rating is the rating of the file, out of 10
+	Rating int
+	// ShowID and Note are the betaseries show and note (out of 5), set once
+	// the row is resolved
+	ShowID int
+	Note   int
+	// Reason tells why the row was skipped or unresolved
+	Reason string
+}
+
+// ImportReport lists the outcome of the rows of an imported ratings file.
+type ImportReport struct {
+	DryRun bool
+	// Applied holds the rows whose note was set, or would have been set for
+	// a dry run
+	Applied []RatingRow
+	// Skipped holds the rows which are not show ratings, have an invalid
+	// rating or whose note is already set
+	Skipped []RatingRow
+	// Unresolved holds the rows whose show was not found on betaseries, or
+	// whose title matches several shows
+	Unresolved []RatingRow
+}
+
+// NoteFromRating maps a rating out of 10 to a note out of 5: the rating is
+// halved and rounded up, so that 1 and 2 give 1, 7 and 8 give 4, and 9 and
+// 10 give 5. It returns 0 for ratings outside 1 to 10.
+func NoteFromRating(rating int) int {
+	if rating < 1 || rating > 10 {
+		return 0
+	}
+	return (rating + 1) / 2
+}
+
+// RatingFromNote maps a note out of 5 to a rating out of 10 by doubling it.
+// Half points, which the API may return, are kept. It returns 0 for notes
+// outside 1 to 5.
+func RatingFromNote(note float32) int {
+	if note < 1 || note > 5 {
+		return 0
+	}
+	return int(math.Round(float64(note) * 2))
+}
+
+// ImportRatings reads the show ratings of a file exported from another
+// service and sets them as notes on betaseries.
+// Shows are found by imdb id first, then by thetvdb id, and at last by
+// title and year as done by the fallback of ResolveShow. Shows rated
+// several times in the file are only noted once, with the first rating.
+// Ratings are mapped with NoteFromRating. If 'dryRun' is true, the shows
+// are resolved but no note is set.
+// A malformed file gives an ErrInvalidInput error before any note is set.
+// If another error interrupts the import, it is returned with the report
+// of the rows handled so far.
+func (bs *BetaSeries) ImportRatings(ctx context.Context, r io.Reader, format RatingsFormat, dryRun bool) (*ImportReport, error) {
+	var rows []RatingRow
+	var err error
+	switch format {
+	case RatingsIMDbCSV:
+		rows, err = parseIMDbRatings(r)
+	case RatingsTraktJSON:
+		rows, err = parseTraktRatings(r)
+	default:
+		return nil, errUnsupportedRatingsFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{DryRun: dryRun}
+	noted := map[int]bool{}
+	for _, row := range rows {
+		if row.Reason != "" {
+			report.Skipped = append(report.Skipped, row)
+			continue
+		}
+		show, err := bs.resolveRating(ctx, &row)
+		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrAmbiguousTitle) {
+			row.Reason = err.Error()
+			report.Unresolved = append(report.Unresolved, row)
+			continue
+		}
+		if err != nil {
+			return report, err
+		}
+		row.ShowID = show.ID
+		row.Note = NoteFromRating(row.Rating)
+		switch {
+		case noted[show.ID]:
+			row.Reason = "show already rated in the file"
+		case int(math.Round(float64(show.Notes.User))) == row.Note:
+			row.Reason = "note already set"
+		}
+		if row.Reason != "" {
+			report.Skipped = append(report.Skipped, row)
+			continue
+		}
+		noted[show.ID] = true
+		if !dryRun {
+			if _, err := bs.ShowNote(ctx, show.ID, 0, row.Note); err != nil {
+				return report, err
+			}
+		}
+		report.Applied = append(report.Applied, row)
+	}
+	return report, nil
+}
+
+// resolveRating returns the show of the row.
+func (bs *BetaSeries) resolveRating(ctx context.Context, row *RatingRow) (*Show, error) {
+	var refs []ShowRef
+	if row.ImdbID != "" {
+		refs = append(refs, ShowRef{ImdbID: row.ImdbID})
+	}
+	if row.TheTvdbID > 0 {
+		refs = append(refs, ShowRef{TheTvdbID: row.TheTvdbID})
+	}
+	for _, ref := range refs {
+		show, err := bs.showUpdate(ctx, "GET", "display", ref, nil)
+		if err == nil && show != nil {
+			return show, nil
+		}
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+	return bs.searchShow(ctx, ExternalRef{Title: row.Title, Year: row.Year})
+}
+
+// ratingsError reports a malformed ratings file.
+func ratingsError(err error) error {
+	return wrapError(ErrInvalidInput, "invalid ratings file", err)
+}
+
+// isShowType reports whether an IMDb title type is a show type, as written by
+// the current ("tvSeries") and former ("TV Mini-Series") exports.
+func isShowType(titleType string) bool {
+	key := strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(titleType))
+	return key == "tvseries" || key == "tvminiseries"
+}
+
+// parseIMDbRatings reads an IMDb CSV ratings export.
+func parseIMDbRatings(r io.Reader) ([]RatingRow, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, ratingsError(err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"Const", "Your Rating"} {
+		if _, ok := columns[name]; !ok {
+			return nil, ratingsError(errors.New("missing column " + strconv.Quote(name)))
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	rows := []RatingRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, ratingsError(err)
+		}
+		line, _ := reader.FieldPos(0)
+		row := RatingRow{
+			Line:   line,
+			Title:  field(record, "Title"),
+			ImdbID: field(record, "Const"),
+		}
+		row.Year, _ = strconv.Atoi(field(record, "Year"))
+		row.Rating, _ = strconv.Atoi(field(record, "Your Rating"))
+		if titleType := field(record, "Title Type"); titleType != "" && !isShowType(titleType) {
+			row.Reason = "not a show: " + titleType
+		} else if NoteFromRating(row.Rating) == 0 {
+			row.Reason = "invalid rating"
+		}
+		rows = append(rows, row)
+	}
+}
+
+// traktRating is an item of a Trakt JSON ratings export.
+type traktRating struct {
+	RatedAt string     `json:"rated_at,omitempty"`
+	Rating  int        `json:"rating"`
+	Type    string     `json:"type"`
+	Show    *traktShow `json:"show,omitempty"`
+}
+
+// traktShow is a show of a Trakt export.
+type traktShow struct {
+	Title string `json:"title"`
+	Year  int    `json:"year"`
+	IDs   struct {
+		Tvdb int    `json:"tvdb,omitempty"`
+		Imdb string `json:"imdb,omitempty"`
+	} `json:"ids"`
+}
+
+// parseTraktRatings reads a Trakt JSON ratings export.
+func parseTraktRatings(r io.Reader) ([]RatingRow, error) {
+	var items []traktRating
+	if err := json.NewDecoder(r).Decode(&items); err != nil && err != io.EOF {
+		return nil, ratingsError(err)
+	}
+	rows := []RatingRow{}
+	for i, item := range items {
+		row := RatingRow{Line: i + 1, Rating: item.Rating}
+		if item.Show != nil {
+			row.Title = item.Show.Title
+			row.Year = item.Show.Year
+			row.ImdbID = item.Show.IDs.Imdb
+			row.TheTvdbID = item.Show.IDs.Tvdb
+		}
+		switch {
+		case item.Type != "show" || item.Show == nil:
+			row.Reason = "not a show: " + item.Type
+		case NoteFromRating(row.Rating) == 0:
+			row.Reason = "invalid rating"
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ExportRatings writes the notes of the shows of the authenticated member in
+// the given format. Notes are mapped to ratings out of 10 with
+// RatingFromNote; shows without note are left out.
+func (bs *BetaSeries) ExportRatings(ctx context.Context, w io.Writer, format RatingsFormat) error {
+	if format != RatingsIMDbCSV && format != RatingsTraktJSON {
+		return errUnsupportedRatingsFormat
+	}
+	member, err := bs.MembersInfos(ctx, 0, false, "shows")
+	if err != nil {
+		return err
+	}
+	var shows []Show
+	if member != nil {
+		for _, show := range member.Shows {
+			if RatingFromNote(show.Notes.User) > 0 {
+				shows = append(shows, show)
+			}
+		}
+	}
+	if format == RatingsIMDbCSV {
+		return writeIMDbRatings(w, shows)
+	}
+	return writeTraktRatings(w, shows)
+}
+
+func writeIMDbRatings(w io.Writer, shows []Show) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"Const", "Your Rating", "Title", "Title Type", "Year"})
+	for _, show := range shows {
+		writer.Write([]string{
+			show.ImdbID,
+			strconv.Itoa(RatingFromNote(show.Notes.User)),
+			show.Title,
+			"tvSeries",
+			show.Creation,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func writeTraktRatings(w io.Writer, shows []Show) error {
+	items := make([]traktRating, len(shows))
+	for i, show := range shows {
+		items[i].Rating = RatingFromNote(show.Notes.User)
+		items[i].Type = "show"
+		items[i].Show = &traktShow{Title: show.Title, Year: atoi(show.Creation)}
+		items[i].Show.IDs.Tvdb = show.ThetvdbID
+		items[i].Show.IDs.Imdb = show.ImdbID
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(items)
+}
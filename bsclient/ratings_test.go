@@ -0,0 +1,248 @@
+package bsclient
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestRatingScale(c *C) {
+	notes := []int{0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5, 0}
+	for rating, note := range notes {
+		c.Assert(NoteFromRating(rating), Equals, note, Commentf("rating %d", rating))
+	}
+	c.Assert(NoteFromRating(-1), Equals, 0)
+	for _, test := range []struct {
+		note   float32
+		rating int
+	}{
+		{0, 0}, {1, 2}, {2.5, 5}, {3, 6}, {4.5, 9}, {5, 10}, {5.5, 0},
+	} {
+		c.Assert(RatingFromNote(test.note), Equals, test.rating, Commentf("note %v", test.note))
+	}
+	// exported notes are imported back unchanged
+	for note := 1; note <= 5; note++ {
+		c.Assert(NoteFromRating(RatingFromNote(float32(note))), Equals, note)
+	}
+}
+
+func (s *MySuite) TestParseIMDbRatings(c *C) {
+	file, err := os.Open("testdata/ratings_imdb.csv")
+	c.Assert(err, IsNil)
+	defer file.Close()
+	rows, err := parseIMDbRatings(file)
+	c.Assert(err, IsNil)
+	c.Assert(rows, DeepEquals, []RatingRow{
+		{Line: 2, Title: "Breaking Bad", Year: 2008, ImdbID: "tt0903747", Rating: 10},
+		{Line: 3, Title: "The Office", Year: 2005, ImdbID: "tt0386676", Rating: 7},
+		{Line: 4, Title: "The Shawshank Redemption", Year: 1994, ImdbID: "tt0111161", Rating: 9,
+			Reason: "not a show: movie"},
+		{Line: 5, Title: "Chernobyl, Miniseries", Year: 2019, ImdbID: "tt9999991", Rating: 4},
+		{Line: 6, Title: "Out Of Scale", Year: 2010, ImdbID: "tt9999992", Rating: 11,
+			Reason: "invalid rating"},
+		{Line: 7, Title: "Unknown Show", Year: 2012, ImdbID: "tt9999993", Rating: 3},
+	})
+
+	// minimal file, columns in any order
+	rows, err = parseIMDbRatings(strings.NewReader("Your Rating,Const\n8,tt1\n"))
+	c.Assert(err, IsNil)
+	c.Assert(rows, DeepEquals, []RatingRow{{Line: 2, ImdbID: "tt1", Rating: 8}})
+	rows, err = parseIMDbRatings(strings.NewReader(""))
+	c.Assert(err, IsNil)
+	c.Assert(rows, HasLen, 0)
+
+	for _, bad := range []string{
+		"Const,Title\ntt1,Lost\n",
+		"Const,Your Rating\ntt1,8,extra\n",
+		"Const,Your Rating\n\"tt1,8\n",
+	} {
+		_, err = parseIMDbRatings(strings.NewReader(bad))
+		c.Assert(errors.Is(err, ErrInvalidInput), Equals, true, Commentf(bad))
+		c.Assert(err, ErrorMatches, "invalid ratings file: .*")
+	}
+}
+
+func (s *MySuite) TestParseTraktRatings(c *C) {
+	file, err := os.Open("testdata/ratings_trakt.json")
+	c.Assert(err, IsNil)
+	defer file.Close()
+	rows, err := parseTraktRatings(file)
+	c.Assert(err, IsNil)
+	c.Assert(rows, DeepEquals, []RatingRow{
+		{Line: 1, Title: "Breaking Bad", Year: 2008, ImdbID: "tt0903747", TheTvdbID: 81189, Rating: 8},
+		{Line: 2, Title: "Lost", Year: 2004, TheTvdbID: 73739, Rating: 6},
+		{Line: 3, Title: "The Office", Year: 2005, Rating: 1},
+		{Line: 4, Title: "Breaking Bad", Year: 2008, ImdbID: "tt0903747", TheTvdbID: 81189, Rating: 9,
+			Reason: "not a show: episode"},
+		{Line: 5, Rating: 10, Reason: "not a show: movie"},
+	})
+
+	rows, err = parseTraktRatings(strings.NewReader(`[{"rating":0,"type":"show","show":{"title":"Lost"}}]`))
+	c.Assert(err, IsNil)
+	c.Assert(rows[0].Reason, Equals, "invalid rating")
+
+	_, err = parseTraktRatings(strings.NewReader(`{"rating":8}`))
+	c.Assert(errors.Is(err, ErrInvalidInput), Equals, true)
+}
+
+// ratingsServer knows Breaking Bad by imdb id, Lost (already noted 3) by
+// thetvdb id and The Office by title only.
+func ratingsServer() *fakeServer {
+	f := newFakeServer()
+	f.handle("GET", "/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("imdb_id") == "tt0903747":
+			writeFakeJSON(w, 200, `{"show":{"id":1,"title":"Breaking Bad"},"errors":[]}`)
+		case q.Get("thetvdb_id") == "73739":
+			writeFakeJSON(w, 200, `{"show":{"id":2,"title":"Lost","notes":{"user":3}},"errors":[]}`)
+		default:
+			writeFakeJSON(w, 400, `{"errors":[{"code":4001,"text":"Aucune série trouvée."}]}`)
+		}
+	})
+	f.handle("GET", "/shows/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("title") == "the office" {
+			writeFakeJSON(w, 200, `{"shows":[{"id":3,"title":"The Office","creation":"2005"},
+				{"id":4,"title":"The Office","creation":"2001"}],"errors":[]}`)
+			return
+		}
+		writeFakeJSON(w, 200, `{"shows":[],"errors":[]}`)
+	})
+	f.handleJSON("POST", "/shows/note", 200, `{"show":{"id":1},"errors":[]}`)
+	return f
+}
+
+// rowIDs returns the show or, if unresolved, the line of the rows.
+func rowIDs(rows []RatingRow) []int {
+	ids := make([]int, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ShowID
+		if ids[i] == 0 {
+			ids[i] = -row.Line
+		}
+	}
+	return ids
+}
+
+func (s *MySuite) TestImportRatingsTrakt(c *C) {
+	f := ratingsServer()
+	defer f.Close()
+	bs := f.client(c)
+	file, err := os.Open("testdata/ratings_trakt.json")
+	c.Assert(err, IsNil)
+	defer file.Close()
+
+	report, err := bs.ImportRatings(ctx, file, RatingsTraktJSON, false)
+	c.Assert(err, IsNil)
+	c.Assert(report.DryRun, Equals, false)
+	c.Assert(rowIDs(report.Applied), DeepEquals, []int{1, 3})
+	c.Assert(report.Applied[0].Note, Equals, 4)
+	c.Assert(report.Applied[1].Note, Equals, 1)
+	// Lost is already noted 3, the episode and the movie are not shows
+	c.Assert(rowIDs(report.Skipped), DeepEquals, []int{2, -4, -5})
+	c.Assert(report.Skipped[0].Reason, Equals, "note already set")
+	c.Assert(report.Unresolved, HasLen, 0)
+
+	// the imdb id is preferred, the thetvdb id tried next
+	display := f.callsTo("/shows/display")
+	c.Assert(display, HasLen, 2)
+	c.Assert(display[0].RawQuery, Equals, "imdb_id=tt0903747")
+	c.Assert(display[1].RawQuery, Equals, "thetvdb_id=73739")
+	notes := f.callsTo("/shows/note")
+	c.Assert(notes, HasLen, 2)
+	c.Assert(notes[0].Body, Equals, "id=1&note=4")
+	c.Assert(notes[1].Body, Equals, "id=3&note=1")
+}
+
+func (s *MySuite) TestImportRatingsIMDb(c *C) {
+	f := ratingsServer()
+	defer f.Close()
+	bs := f.client(c)
+	file, err := os.Open("testdata/ratings_imdb.csv")
+	c.Assert(err, IsNil)
+	defer file.Close()
+
+	report, err := bs.ImportRatings(ctx, file, RatingsIMDbCSV, true)
+	c.Assert(err, IsNil)
+	c.Assert(report.DryRun, Equals, true)
+	// The Office has an unknown imdb id and is found by title and year
+	c.Assert(rowIDs(report.Applied), DeepEquals, []int{1, 3})
+	c.Assert(report.Applied[0].Note, Equals, 5)
+	c.Assert(report.Applied[1].Note, Equals, 4)
+	c.Assert(rowIDs(report.Skipped), DeepEquals, []int{-4, -6})
+	c.Assert(rowIDs(report.Unresolved), DeepEquals, []int{-5, -7})
+	c.Assert(report.Unresolved[0].Reason, Equals, ErrNoShowsFound.Error())
+	c.Assert(f.callsTo("/shows/display"), HasLen, 4)
+	c.Assert(f.callsTo("/shows/search"), HasLen, 3)
+	c.Assert(f.callsTo("/shows/note"), HasLen, 0)
+}
+
+func (s *MySuite) TestImportRatingsErrors(c *C) {
+	f := ratingsServer()
+	defer f.Close()
+	bs := f.client(c)
+
+	_, err := bs.ImportRatings(ctx, strings.NewReader(""), RatingsFormat(0), false)
+	c.Assert(err, Equals, errUnsupportedRatingsFormat)
+
+	// a show rated twice is noted once, ambiguous titles are unresolved
+	report, err := bs.ImportRatings(ctx, strings.NewReader("Const,Your Rating,Title\n"+
+		"tt0903747,8,Breaking Bad\ntt0903747,2,Breaking Bad\n,6,The Office\n"), RatingsIMDbCSV, false)
+	c.Assert(err, IsNil)
+	c.Assert(rowIDs(report.Applied), DeepEquals, []int{1})
+	c.Assert(rowIDs(report.Skipped), DeepEquals, []int{1})
+	c.Assert(report.Skipped[0].Reason, Equals, "show already rated in the file")
+	c.Assert(rowIDs(report.Unresolved), DeepEquals, []int{-4})
+	c.Assert(report.Unresolved[0].Reason, Matches, "several shows match: .*")
+
+	// other errors stop the import
+	f.handleJSON("POST", "/shows/note", 400, `{"errors":[{"code":2001,"text":"Invalid token"}]}`)
+	report, err = bs.ImportRatings(ctx, strings.NewReader("Const,Your Rating\ntt0903747,8\ntt0903747,6\n"),
+		RatingsIMDbCSV, false)
+	c.Assert(errors.Is(err, ErrTokenInvalid), Equals, true)
+	c.Assert(report.Applied, HasLen, 0)
+	c.Assert(f.callsTo("/shows/note"), HasLen, 2)
+}
+
+const ratedShows = `{"member":{"id":1,"login":"me","shows":[
+	{"id":1,"thetvdb_id":81189,"imdb_id":"tt0903747","title":"Breaking Bad","creation":"2008","notes":{"user":5}},
+	{"id":2,"thetvdb_id":73739,"imdb_id":"","title":"Lost","creation":"2004","notes":{"user":3}},
+	{"id":3,"thetvdb_id":73244,"imdb_id":"tt0386676","title":"The Office","creation":"2005","notes":{"user":0}}
+]},"errors":[]}`
+
+func (s *MySuite) TestExportRatings(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/members/infos", 200, ratedShows)
+	bs := f.client(c)
+
+	var out bytes.Buffer
+	c.Assert(bs.ExportRatings(ctx, &out, RatingsIMDbCSV), IsNil)
+	c.Assert(out.String(), Equals, "Const,Your Rating,Title,Title Type,Year\n"+
+		"tt0903747,10,Breaking Bad,tvSeries,2008\n"+
+		",6,Lost,tvSeries,2004\n")
+	rows, err := parseIMDbRatings(&out)
+	c.Assert(err, IsNil)
+	c.Assert(rows, DeepEquals, []RatingRow{
+		{Line: 2, Title: "Breaking Bad", Year: 2008, ImdbID: "tt0903747", Rating: 10},
+		{Line: 3, Title: "Lost", Year: 2004, Rating: 6},
+	})
+
+	out.Reset()
+	c.Assert(bs.ExportRatings(ctx, &out, RatingsTraktJSON), IsNil)
+	rows, err = parseTraktRatings(&out)
+	c.Assert(err, IsNil)
+	c.Assert(rows, DeepEquals, []RatingRow{
+		{Line: 1, Title: "Breaking Bad", Year: 2008, ImdbID: "tt0903747", TheTvdbID: 81189, Rating: 10},
+		{Line: 2, Title: "Lost", Year: 2004, TheTvdbID: 73739, Rating: 6},
+	})
+	calls := f.callsTo("/members/infos")
+	c.Assert(calls, HasLen, 2)
+	c.Assert(calls[0].Query.Get("only"), Equals, "shows")
+
+	c.Assert(bs.ExportRatings(ctx, &out, RatingsFormat(9)), Equals, errUnsupportedRatingsFormat)
+}
@@ -0,0 +1,118 @@
+package bsclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// SetRawCapture makes the client keep the original JSON of the shows,
+// episodes and members it decodes, available through their RawJSON method.
+// It is disabled by default as it keeps the payloads in memory.
+func (bs *BetaSeries) SetRawCapture(enabled bool) {
+	bs.rawCapture = enabled
+}
+
+// RawJSON returns the JSON the show was decoded from, if raw capture was
+// enabled on the client (see SetRawCapture).
+func (s *Show) RawJSON() []byte {
+	return s.raw
+}
+
+// RawJSON returns the JSON the episode was decoded from, if raw capture was
+// enabled on the client (see SetRawCapture).
+func (e *Episode) RawJSON() []byte {
+	return e.raw
+}
+
+// RawJSON returns the JSON the member was decoded from, if raw capture was
+// enabled on the client (see SetRawCapture).
+func (m *Member) RawJSON() []byte {
+	return m.raw
+}
+
+// rawHolder is implemented by the entities keeping their JSON.
+type rawHolder interface {
+	setRaw(raw json.RawMessage)
+}
+
+func (s *Show) setRaw(raw json.RawMessage)    { s.raw = raw }
+func (e *Episode) setRaw(raw json.RawMessage) { e.raw = raw }
+func (m *Member) setRaw(raw json.RawMessage)  { m.raw = raw }
+
+// captureRaw walks the decoded value 'v' along its JSON 'raw' and stores the
+// JSON of every entity met in its unexported raw field.
+func captureRaw(v reflect.Value, raw json.RawMessage) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			captureRaw(v.Elem(), raw)
+		}
+	case reflect.Slice:
+		var items []json.RawMessage
+		if json.Unmarshal(raw, &items) != nil {
+			return
+		}
+		for i := 0; i < v.Len() && i < len(items); i++ {
+			captureRaw(v.Index(i), items[i])
+		}
+	case reflect.Struct:
+		if v.CanAddr() {
+			if holder, ok := v.Addr().Interface().(rawHolder); ok {
+				// copy, raw may be the response body itself
+				holder.setRaw(append(json.RawMessage(nil), raw...))
+			}
+		}
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(raw, &fields) != nil {
+			return
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath != "" {
+				continue
+			}
+			name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			if fieldRaw, ok := fields[name]; ok {
+				captureRaw(v.Field(i), fieldRaw)
+			}
+		}
+	}
+}
+
+// rawKey is the key of the sink of the response bodies of DoRaw in the
+// context of its request
+type rawKey struct{}
+
+// rawSink receives the body of the last response read for DoRaw, error
+// responses included.
+type rawSink struct {
+	body []byte
+}
+
+// DoRaw sends a request to the API 'endpoint' (for instance
+// "/shows/display") with the parameters 'params' and returns the body of
+// the response as is, for the fields and endpoints the client does not
+// model. The parameters are sent in the query for GET requests and in a
+// form body otherwise, the headers, the authentication and the retries
+// being those of the other calls. The body is bounded to 64 MiB, a larger
+// one failing with an error matching ErrServiceUnavailable.
+// When the API answers with an error, the error is returned along with the
+// body of the error response. DoRaw does not record the mutations in the
+// journal (see WithMutationJournal).
+func (bs *BetaSeries) DoRaw(ctx context.Context, method, endpoint string, params url.Values) (json.RawMessage, error) {
+	u := bs.endpoint(endpoint)
+	u.RawQuery = params.Encode()
+	sink := &rawSink{}
+	body, err := bs.do(context.WithValue(ctx, rawKey{}, sink), method, u)
+	if err != nil {
+		return json.RawMessage(sink.body), err
+	}
+	// copy, the body may be the one kept by the cache
+	return append(json.RawMessage(nil), body...), nil
+}
@@ -0,0 +1,91 @@
+package bsclient
+
+import (
+	"errors"
+	"net/url"
+
+	. "gopkg.in/check.v1"
+)
+
+const (
+	rawShow    = `{"id":1,"title":"Breaking Bad","future_field":{"a":[1,2]}}`
+	rawEpisode = `{"id":10,"title":"Pilot","future_field":true}`
+	rawMember  = `{"id":3,"login":"me","shows":[` + rawShow + `],"future_field":"x"}`
+)
+
+func (s *MySuite) TestRawCapture(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/display", 200, `{"show":`+rawShow+`,"errors":[]}`)
+	f.handleJSON("GET", "/shows/episodes", 200, `{"episodes":[`+rawEpisode+`, {"id":11}],"errors":[]}`)
+	f.handleJSON("GET", "/members/infos", 200, `{"member":`+rawMember+`,"errors":[]}`)
+	bs := f.client(c)
+	bs.SetRawCapture(true)
+
+	show, err := bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.Title, Equals, "Breaking Bad")
+	c.Assert(string(show.RawJSON()), Equals, rawShow)
+
+	episodes, err := bs.ShowsEpisodes(ctx, 1, 0, 0, 0, false)
+	c.Assert(err, IsNil)
+	c.Assert(episodes, HasLen, 2)
+	c.Assert(string(episodes[0].RawJSON()), Equals, rawEpisode)
+	c.Assert(string(episodes[1].RawJSON()), Equals, `{"id":11}`)
+
+	member, err := bs.MembersInfos(ctx, 3, false, "shows")
+	c.Assert(err, IsNil)
+	c.Assert(string(member.RawJSON()), Equals, rawMember)
+	c.Assert(member.Shows, HasLen, 1)
+	c.Assert(string(member.Shows[0].RawJSON()), Equals, rawShow)
+}
+
+func (s *MySuite) TestRawCaptureDisabled(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/display", 200, `{"show":`+rawShow+`,"errors":[]}`)
+	f.handleJSON("GET", "/members/infos", 200, `{"member":`+rawMember+`,"errors":[]}`)
+	bs := f.client(c)
+
+	show, err := bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.RawJSON(), IsNil)
+	member, err := bs.MembersInfos(ctx, 3, false, "shows")
+	c.Assert(err, IsNil)
+	c.Assert(member.RawJSON(), IsNil)
+	c.Assert(member.Shows[0].RawJSON(), IsNil)
+}
+
+func (s *MySuite) TestDoRaw(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	display := `{"show":` + rawShow + `,"errors":[]}`
+	failed := `{"errors":[{"code":4001,"text":"Série introuvable."}],"future_field":1}`
+	f.handleJSON("GET", "/shows/display", 200, display)
+	f.handleJSON("POST", "/shows/future", 200, `{"done":true}`)
+	f.handleJSON("GET", "/shows/missing", 404, failed)
+	bs := f.client(c)
+
+	raw, err := bs.DoRaw(ctx, "GET", "/shows/display", url.Values{"id": {"1"}})
+	c.Assert(err, IsNil)
+	c.Assert(string(raw), Equals, display)
+	call := f.callsTo("/shows/display")[0]
+	c.Assert(call.Query.Get("id"), Equals, "1")
+	c.Assert(call.Header.Get("X-BetaSeries-Token"), Equals, "token")
+
+	raw, err = bs.DoRaw(ctx, "POST", "/shows/future", url.Values{"id": {"2"}})
+	c.Assert(err, IsNil)
+	c.Assert(string(raw), Equals, `{"done":true}`)
+	c.Assert(f.callsTo("/shows/future")[0].Form.Get("id"), Equals, "2")
+
+	// the body of an error response comes with the error
+	raw, err = bs.DoRaw(ctx, "GET", "/shows/missing", nil)
+	c.Assert(errors.Is(err, ErrNotFound), Equals, true)
+	c.Assert(string(raw), Equals, failed)
+
+	// the usual decoding is unchanged
+	show, err := bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.Title, Equals, "Breaking Bad")
+	c.Assert(show.RawJSON(), IsNil)
+}
@@ -0,0 +1,178 @@
+package bsclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	. "gopkg.in/check.v1"
+)
+
+// recorder is a transport saving the sanitized JSON responses it relays.
+type recorder struct {
+	dir       string
+	transport http.RoundTripper
+	mu        sync.Mutex
+	err       error
+}
+
+func (r *recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil || req.Method != "GET" {
+		return resp, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.save(fixtureName(req), body)
+	return resp, nil
+}
+
+func (r *recorder) save(name string, body []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var data interface{}
+	if decodeJSON(body, &data) != nil {
+		return
+	}
+	// maps are marshalled with sorted keys, making the fixtures deterministic
+	out, err := json.MarshalIndent(sanitize(data), "", "  ")
+	if err == nil {
+		err = os.MkdirAll(r.dir, 0755)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(filepath.Join(r.dir, name), append(out, '\n'), 0644)
+	}
+	if err != nil && r.err == nil {
+		r.err = err
+	}
+}
+
+// fixtureName names the fixture of a request after its endpoint and query.
+func fixtureName(req *http.Request) string {
+	name := strings.Trim(req.URL.Path, "/")
+	if query := req.URL.Query().Encode(); query != "" {
+		name += "?" + query
+	}
+	return strings.NewReplacer("/", "_", "?", "__", "&", "_", "=", "-", "%", "", "+", "-").Replace(name) + ".json"
+}
+
+// secret fields, redacted
+var secretFields = map[string]bool{
+	"token": true,
+	"hash":  true,
+}
+
+// volatile fields, zeroed so that fixtures only change with the API schema
+var volatileFields = map[string]bool{
+	"followers":  true,
+	"comments":   true,
+	"xp":         true,
+	"cached":     true,
+	"total":      true,
+	"mean":       true,
+	"popularity": true,
+}
+
+func sanitize(data interface{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			switch {
+			case secretFields[key]:
+				v[key] = "REDACTED"
+			case volatileFields[key]:
+				v[key] = zeroOf(value)
+			default:
+				v[key] = sanitize(value)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = sanitize(v[i])
+		}
+	}
+	return data
+}
+
+func zeroOf(value interface{}) interface{} {
+	switch value.(type) {
+	case float64, json.Number:
+		return 0
+	case string:
+		return "0"
+	case bool:
+		return false
+	case nil:
+		return nil
+	}
+	return sanitize(value)
+}
+
+func (s *MySuite) TestRecorder(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/display", 200, `{"show":{"id":1,"title":"Breaking Bad",
+		"followers":"1234","notes":{"total":56,"mean":4.5,"user":3},"in_account":true},"errors":[]}`)
+	f.handleJSON("POST", "/members/auth", 200, `{"user":{"id":1,"login":"me"},"token":"secret","hash":"h","errors":[]}`)
+	dir := c.MkDir()
+	bs := f.client(c)
+	rec := &recorder{dir: dir, transport: http.DefaultTransport}
+	bs.httpClient.Transport = rec
+
+	show, err := bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	c.Assert(show.Followers, Equals, FlexInt(1234))
+	c.Assert(bs.retrieveToken(ctx, "me", "password"), IsNil)
+	c.Assert(bs.token.Token, Equals, "secret")
+	c.Assert(rec.err, IsNil)
+
+	files, err := ioutil.ReadDir(dir)
+	c.Assert(err, IsNil)
+	c.Assert(files, HasLen, 1)
+	c.Assert(files[0].Name(), Equals, "shows_display__id-1.json")
+	first, err := ioutil.ReadFile(filepath.Join(dir, files[0].Name()))
+	c.Assert(err, IsNil)
+	c.Assert(string(first), Equals, `{
+  "errors": [],
+  "show": {
+    "followers": "0",
+    "id": 1,
+    "in_account": true,
+    "notes": {
+      "mean": 0,
+      "total": 0,
+      "user": 3
+    },
+    "title": "Breaking Bad"
+  }
+}
+`)
+
+	// recording again gives the same fixture
+	f.handleJSON("GET", "/shows/display", 200, `{"show":{"id":1,"title":"Breaking Bad",
+		"followers":"1300","notes":{"total":60,"mean":4.4,"user":3},"in_account":true},"errors":[]}`)
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, IsNil)
+	second, err := ioutil.ReadFile(filepath.Join(dir, files[0].Name()))
+	c.Assert(err, IsNil)
+	c.Assert(string(second), Equals, string(first))
+}
+
+func (s *MySuite) TestSanitize(c *C) {
+	var data interface{}
+	c.Assert(json.Unmarshal([]byte(`{"token":"t","user":{"hash":"h","xp":12},
+		"shows":[{"comments":"3"}]}`), &data), IsNil)
+	out, err := json.Marshal(sanitize(data))
+	c.Assert(err, IsNil)
+	c.Assert(string(out), Equals,
+		`{"shows":[{"comments":"0"}],"token":"REDACTED","user":{"hash":"REDACTED","xp":0}}`)
+}
@@ -0,0 +1,94 @@
+package bsclient
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrAmbiguousRef is returned, when strict references are enabled, if
+	// several identifiers are given for the same show or episode.
+	// It is an ErrInvalidInput.
+	ErrAmbiguousRef = newError(ErrInvalidInput, "several identifiers given for a single reference")
+	// ErrInvalidImdbID is returned when an imdb id, once trimmed, does not
+	// look like "tt" followed by 7 or 8 digits. It is an ErrInvalidInput.
+	ErrInvalidImdbID = newError(ErrInvalidInput, "invalid imdb id")
+)
+
+var imdbIDPattern = regexp.MustCompile(`^tt[0-9]{7,8}$`)
+
+// ShowRef identifies a show by its betaseries id, its thetvdb id or its imdb id.
+// When several identifiers are set, the betaseries id wins over the thetvdb id
+// which wins over the imdb id, unless strict references are enabled (see
+// SetStrictRefs).
+type ShowRef struct {
+	ID        int
+	TheTvdbID int
+	ImdbID    string
+}
+
+// IsZero reports whether no identifier is set.
+func (ref ShowRef) IsZero() bool {
+	return ref.ID <= 0 && ref.TheTvdbID <= 0 && ref.ImdbID == ""
+}
+
+// refParams holds the names of the query parameters used by an endpoint
+// for each kind of identifier. An empty name means the endpoint does not
+// support that kind.
+type refParams struct {
+	id        string
+	theTvdbID string
+	imdbID    string
+}
+
+var (
+	showRefParams         = refParams{"id", "thetvdb_id", "imdb_id"}
+	episodeRefParams      = refParams{"id", "thetvdb_id", ""}
+	episodesListRefParams = refParams{"showId", "showTheTVDBId", "showIMDBId"}
+)
+
+// SetStrictRefs makes requests given conflicting identifiers (for instance
+// both a betaseries id and a thetvdb id) fail with ErrAmbiguousRef instead
+// of silently using the one with the highest precedence.
+func (bs *BetaSeries) SetStrictRefs(strict bool) {
+	bs.strictRefs = strict
+}
+
+// setRef sets exactly one identifier parameter of the reference in q.
+// It returns ErrIDNotProperlySet when no usable identifier is given, and
+// ErrInvalidImdbID when the imdb id to use is malformed.
+func (bs *BetaSeries) setRef(q querySetter, params refParams, ref ShowRef) error {
+	ref.ImdbID = strings.TrimSpace(ref.ImdbID)
+	if params.imdbID == "" {
+		ref.ImdbID = ""
+	}
+	if bs.strictRefs {
+		n := 0
+		if ref.ID > 0 {
+			n++
+		}
+		if ref.TheTvdbID > 0 {
+			n++
+		}
+		if ref.ImdbID != "" {
+			n++
+		}
+		if n > 1 {
+			return ErrAmbiguousRef
+		}
+	}
+	if ref.ID > 0 {
+		q.Set(params.id, strconv.Itoa(ref.ID))
+	} else if ref.TheTvdbID > 0 {
+		q.Set(params.theTvdbID, strconv.Itoa(ref.TheTvdbID))
+	} else if ref.ImdbID != "" {
+		if !imdbIDPattern.MatchString(ref.ImdbID) {
+			return ErrInvalidImdbID
+		}
+		q.Set(params.imdbID, ref.ImdbID)
+	} else {
+		return ErrIDNotProperlySet
+	}
+	return nil
+}
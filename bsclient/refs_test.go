@@ -0,0 +1,139 @@
+package bsclient
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+var refParamNames = []string{
+	"id", "thetvdb_id", "imdb_id",
+	"showId", "showTheTVDBId", "showIMDBId",
+}
+
+type refCall struct {
+	name  string
+	path  string
+	call  func(bs *BetaSeries) error
+	param string
+	value string
+}
+
+// refCalls lists every method accepting several identifier forms, called
+// with all of them set.
+var refCalls = []refCall{
+	{"ShowsSimilars", "/shows/similars", func(bs *BetaSeries) error {
+		_, err := bs.ShowsSimilars(ctx, 1, 2, false)
+		return err
+	}, "id", "1"},
+	{"ShowsCharacters", "/shows/characters", func(bs *BetaSeries) error {
+		_, err := bs.ShowsCharacters(ctx, 1, 2)
+		return err
+	}, "id", "1"},
+	{"ShowsVideos", "/shows/videos", func(bs *BetaSeries) error {
+		_, err := bs.ShowsVideos(ctx, 1, 2)
+		return err
+	}, "id", "1"},
+	{"ShowsEpisodes", "/shows/episodes", func(bs *BetaSeries) error {
+		_, err := bs.ShowsEpisodes(ctx, 1, 2, 0, 0, false)
+		return err
+	}, "id", "1"},
+	{"ShowDisplay", "/shows/display", func(bs *BetaSeries) error {
+		_, err := bs.ShowDisplay(ctx, 1, 2, "tt0903747")
+		return err
+	}, "id", "1"},
+	{"ShowAdd", "/shows/show", func(bs *BetaSeries) error {
+		_, err := bs.ShowAdd(ctx, 0, 2, "tt0903747", 0)
+		return err
+	}, "thetvdb_id", "2"},
+	{"ShowRemove", "/shows/show", func(bs *BetaSeries) error {
+		_, err := bs.ShowRemove(ctx, 1, 0, "tt0903747")
+		return err
+	}, "id", "1"},
+	{"ShowArchive", "/shows/archive", func(bs *BetaSeries) error {
+		_, err := bs.ShowArchive(ctx, 1, 2)
+		return err
+	}, "id", "1"},
+	{"ShowNote", "/shows/note", func(bs *BetaSeries) error {
+		_, err := bs.ShowNote(ctx, 1, 2, 3)
+		return err
+	}, "id", "1"},
+	{"EpisodesList", "/episodes/list", func(bs *BetaSeries) error {
+		_, err := bs.EpisodesList(ctx, 0, 2, "tt0903747", 0, 0, -1, false, false)
+		return err
+	}, "showTheTVDBId", "2"},
+	{"EpisodeDisplay", "/episodes/display", func(bs *BetaSeries) error {
+		_, err := bs.EpisodeDisplay(ctx, 1, 2, false)
+		return err
+	}, "id", "1"},
+	{"EpisodeDownloaded", "/episodes/downloaded", func(bs *BetaSeries) error {
+		_, err := bs.EpisodeDownloaded(ctx, 1, 2)
+		return err
+	}, "id", "1"},
+	{"EpisodeWatched", "/episodes/watched", func(bs *BetaSeries) error {
+		_, err := bs.EpisodeWatched(ctx, 1, 2, 0, false, false)
+		return err
+	}, "id", "1"},
+	{"EpisodeNote", "/episodes/note", func(bs *BetaSeries) error {
+		_, err := bs.EpisodeNote(ctx, 1, 2, 3)
+		return err
+	}, "id", "1"},
+}
+
+func (s *MySuite) TestRefsPrecedence(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	bs := f.client(c)
+	for _, test := range refCalls {
+		before := len(f.callsTo(test.path))
+		test.call(bs)
+		calls := f.callsTo(test.path)
+		c.Assert(calls, HasLen, before+1, Commentf(test.name))
+		query := calls[len(calls)-1].params()
+		found := 0
+		for _, name := range refParamNames {
+			if _, ok := query[name]; ok {
+				found++
+			}
+		}
+		c.Assert(found, Equals, 1, Commentf("%s: %v", test.name, query))
+		c.Assert(query.Get(test.param), Equals, test.value, Commentf(test.name))
+	}
+}
+
+func (s *MySuite) TestRefsStrict(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	bs := f.client(c)
+	bs.SetStrictRefs(true)
+	for _, test := range refCalls {
+		err := test.call(bs)
+		c.Assert(err, Equals, ErrAmbiguousRef, Commentf(test.name))
+		c.Assert(f.callsTo(test.path), HasLen, 0, Commentf(test.name))
+	}
+
+	// a single identifier is still accepted
+	_, err := bs.ShowDisplay(ctx, 0, 0, "tt0903747")
+	c.Assert(err, Not(Equals), ErrAmbiguousRef)
+	calls := f.callsTo("/shows/display")
+	c.Assert(calls, HasLen, 1)
+	c.Assert(calls[0].Query.Get("imdb_id"), Equals, "tt0903747")
+}
+
+func (s *MySuite) TestRefsImdbID(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	bs := f.client(c)
+
+	// pasted ids are trimmed
+	bs.ShowDisplay(ctx, 0, 0, " tt0903747\n")
+	bs.ShowDisplay(ctx, 0, 0, "tt10048342")
+	calls := f.callsTo("/shows/display")
+	c.Assert(calls, HasLen, 2)
+	c.Assert(calls[0].RawQuery, Equals, "imdb_id=tt0903747")
+	c.Assert(calls[1].RawQuery, Equals, "imdb_id=tt10048342")
+
+	for _, id := range []string{"0903747", "tt123", "tt0903747&id=1", "tt 0903747", "https://www.imdb.com/title/tt0903747/"} {
+		_, err := bs.ShowDisplay(ctx, 0, 0, id)
+		c.Assert(err, Equals, ErrInvalidImdbID, Commentf(id))
+	}
+	c.Assert(f.callsTo("/shows/display"), HasLen, 2)
+}
@@ -0,0 +1,161 @@
+package bsclient
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	errUnsupportedSource = newError(ErrInvalidInput, "unsupported identifier source")
+)
+
+// ExternalSource tells which database an ExternalRef identifier comes from.
+type ExternalSource int
+
+// Sources of external identifiers
+const (
+	// SourceTheTVDB identifies shows by their thetvdb id
+	SourceTheTVDB ExternalSource = iota + 1
+	// SourceIMDb identifies shows by their imdb id ("tt0903747")
+	SourceIMDb
+	// SourceTMDb identifies movies by their tmdb id, it is not supported for
+	// shows
+	SourceTMDb
+)
+
+// ExternalRef identifies a show by the identifier of another database.
+// Title and Year are only used by the title search fallback (see
+// SetResolveFallback): Year, when set, is matched against the creation year.
+type ExternalRef struct {
+	Source ExternalSource
+	ID     string
+	Title  string
+	Year   int
+}
+
+// TheTvdbRef returns the reference of the show with the given thetvdb id.
+func TheTvdbRef(id int) ExternalRef {
+	return ExternalRef{Source: SourceTheTVDB, ID: strconv.Itoa(id)}
+}
+
+// ImdbRef returns the reference of the show with the given imdb id.
+func ImdbRef(id string) ExternalRef {
+	return ExternalRef{Source: SourceIMDb, ID: id}
+}
+
+// ResolvePath tells how ResolveShow found the show.
+type ResolvePath int
+
+// Paths followed by ResolveShow
+const (
+	// ResolvedDirectly means the show was found by its identifier
+	ResolvedDirectly ResolvePath = iota + 1
+	// ResolvedBySearch means the show was found by the title search fallback
+	ResolvedBySearch
+)
+
+// ErrAmbiguousTitle is matched by the *AmbiguousShowError returned when
+// several shows match a title. It is an ErrInvalidInput: the title, and the
+// year if any, do not identify a single show.
+var ErrAmbiguousTitle = newError(ErrInvalidInput, "several shows match the title")
+
+// AmbiguousShowError is returned by ResolveShow and ShowByExactTitle when
+// several shows match the title. It is an ErrAmbiguousTitle.
+type AmbiguousShowError struct {
+	Candidates []Show
+}
+
+func (e *AmbiguousShowError) Error() string {
+	titles := make([]string, len(e.Candidates))
+	for i, show := range e.Candidates {
+		titles[i] = show.Title + " (" + strconv.Itoa(show.ID) + ")"
+	}
+	return "several shows match: " + strings.Join(titles, ", ")
+}
+
+// Is makes AmbiguousShowError match ErrAmbiguousTitle.
+func (e *AmbiguousShowError) Is(target error) bool {
+	return target == ErrAmbiguousTitle
+}
+
+// Unwrap returns ErrAmbiguousTitle, so that the error matches its class.
+func (e *AmbiguousShowError) Unwrap() error {
+	return ErrAmbiguousTitle
+}
+
+// SetResolveFallback makes ResolveShow search the title of the reference
+// when its identifier is unknown to betaseries. The fallback costs an extra
+// request and is disabled by default.
+func (bs *BetaSeries) SetResolveFallback(enabled bool) {
+	bs.resolveFallback = enabled
+}
+
+// ResolveShow returns the show identified by the external reference 'ext',
+// along with the way it was found.
+// When the identifier is unknown and the fallback is enabled, the shows
+// found by a search on the reference title are kept if their title matches
+// (ignoring case, accents and leading articles) and, if the reference has
+// a year, if they were created that year. Several remaining shows give an
+// *AmbiguousShowError listing them.
+func (bs *BetaSeries) ResolveShow(ctx context.Context, ext ExternalRef) (*Show, ResolvePath, error) {
+	ref := ShowRef{}
+	switch ext.Source {
+	case SourceTheTVDB:
+		id, err := strconv.Atoi(strings.TrimSpace(ext.ID))
+		if err != nil || id <= 0 {
+			return nil, 0, ErrIDNotProperlySet
+		}
+		ref.TheTvdbID = id
+	case SourceIMDb:
+		ref.ImdbID = ext.ID
+	default:
+		return nil, 0, errUnsupportedSource
+	}
+	show, err := bs.showUpdate(ctx, "GET", "display", ref, nil)
+	if err == nil && show != nil {
+		return show, ResolvedDirectly, nil
+	}
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, 0, err
+	}
+	if !bs.resolveFallback {
+		return nil, 0, ErrNoShowsFound
+	}
+	show, err = bs.searchShow(ctx, ext)
+	if err != nil {
+		return nil, 0, err
+	}
+	return show, ResolvedBySearch, nil
+}
+
+// searchShow returns the show found by a search on the title of 'ext', as
+// done by the fallback of ResolveShow.
+func (bs *BetaSeries) searchShow(ctx context.Context, ext ExternalRef) (*Show, error) {
+	if strings.TrimSpace(ext.Title) == "" {
+		return nil, ErrNoShowsFound
+	}
+	shows, err := bs.ShowsSearch(ctx, ext.Title, "", false)
+	if err != nil {
+		return nil, err
+	}
+	key := titleKey(ext.Title)
+	var candidates []Show
+	for _, show := range shows {
+		if titleKey(show.Title) != key {
+			continue
+		}
+		if ext.Year > 0 && show.Creation != strconv.Itoa(ext.Year) {
+			continue
+		}
+		candidates = append(candidates, show)
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, ErrNoShowsFound
+	case 1:
+		return &candidates[0], nil
+	}
+	return nil, &AmbiguousShowError{candidates}
+}
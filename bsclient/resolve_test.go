@@ -0,0 +1,92 @@
+package bsclient
+
+import (
+	"errors"
+
+	. "gopkg.in/check.v1"
+)
+
+const resolveSearch = `{"shows":[
+	{"id":1,"title":"The Office","creation":"2005"},
+	{"id":2,"title":"The Office","creation":"2001"},
+	{"id":3,"title":"The Office Retreat","creation":"2005"}
+],"errors":[]}`
+
+func (s *MySuite) TestResolveShowDirect(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/display", 200, `{"show":{"id":481,"title":"Breaking Bad"},"errors":[]}`)
+	bs := f.client(c)
+	bs.SetResolveFallback(true)
+
+	show, path, err := bs.ResolveShow(ctx, TheTvdbRef(81189))
+	c.Assert(err, IsNil)
+	c.Assert(show.ID, Equals, 481)
+	c.Assert(path, Equals, ResolvedDirectly)
+	show, path, err = bs.ResolveShow(ctx, ImdbRef("tt0903747"))
+	c.Assert(err, IsNil)
+	c.Assert(path, Equals, ResolvedDirectly)
+
+	calls := f.callsTo("/shows/display")
+	c.Assert(calls, HasLen, 2)
+	c.Assert(calls[0].RawQuery, Equals, "thetvdb_id=81189")
+	c.Assert(calls[1].RawQuery, Equals, "imdb_id=tt0903747")
+	c.Assert(f.callsTo("/shows/search"), HasLen, 0)
+
+	_, _, err = bs.ResolveShow(ctx, ExternalRef{Source: SourceTMDb, ID: "1396"})
+	c.Assert(err, Equals, errUnsupportedSource)
+	_, _, err = bs.ResolveShow(ctx, ExternalRef{Source: SourceTheTVDB, ID: "abc"})
+	c.Assert(err, Equals, ErrIDNotProperlySet)
+}
+
+func (s *MySuite) TestResolveShowFallback(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/display", 400, `{"errors":[{"code":4001,"text":"Aucune série trouvée."}]}`)
+	f.handleJSON("GET", "/shows/search", 200, resolveSearch)
+	bs := f.client(c)
+
+	// disabled by default
+	ref := ExternalRef{Source: SourceTheTVDB, ID: "73244", Title: "The Office", Year: 2005}
+	_, _, err := bs.ResolveShow(ctx, ref)
+	c.Assert(err, Equals, ErrNoShowsFound)
+	c.Assert(f.callsTo("/shows/search"), HasLen, 0)
+
+	bs.SetResolveFallback(true)
+	show, path, err := bs.ResolveShow(ctx, ref)
+	c.Assert(err, IsNil)
+	c.Assert(show.ID, Equals, 1)
+	c.Assert(path, Equals, ResolvedBySearch)
+	c.Assert(f.callsTo("/shows/search")[0].Query.Get("title"), Equals, "the office")
+
+	// without year, both "The Office" match
+	ref.Year = 0
+	_, _, err = bs.ResolveShow(ctx, ref)
+	ambiguous, ok := err.(*AmbiguousShowError)
+	c.Assert(ok, Equals, true)
+	c.Assert(ambiguous.Candidates, HasLen, 2)
+	c.Assert(ambiguous.Candidates[0].ID, Equals, 1)
+	c.Assert(ambiguous.Candidates[1].ID, Equals, 2)
+	c.Assert(err, ErrorMatches, `several shows match: The Office \(1\), The Office \(2\)`)
+	c.Assert(errors.Is(err, ErrAmbiguousTitle), Equals, true)
+	c.Assert(errors.Is(err, ErrNotFound), Equals, false)
+
+	// total miss
+	ref.Year = 1999
+	_, _, err = bs.ResolveShow(ctx, ref)
+	c.Assert(err, Equals, ErrNoShowsFound)
+	c.Assert(f.callsTo("/shows/search"), HasLen, 3)
+}
+
+func (s *MySuite) TestResolveShowErrors(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/display", 400, `{"errors":[{"code":2001,"text":"Token invalide."}]}`)
+	bs := f.client(c)
+	bs.SetResolveFallback(true)
+
+	// only unknown identifiers fall back to the search
+	_, _, err := bs.ResolveShow(ctx, ExternalRef{Source: SourceIMDb, ID: "tt0386676", Title: "The Office"})
+	c.Assert(errors.Is(err, ErrTokenInvalid), Equals, true)
+	c.Assert(f.callsTo("/shows/search"), HasLen, 0)
+}
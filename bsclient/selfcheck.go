@@ -0,0 +1,175 @@
+package bsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Checks run by SelfCheck
+const (
+	// CheckAPI fails when the API cannot be reached
+	CheckAPI = "api"
+	// CheckAPIKey fails when the API key is rejected
+	CheckAPIKey = "key"
+	// CheckToken fails when the member token is rejected
+	CheckToken = "token"
+	// CheckClock fails when the local clock is off by more than the tolerated
+	// skew (see SetMaxClockSkew)
+	CheckClock = "clock"
+	// CheckVersion fails when the API flags the requested version as deprecated
+	CheckVersion = "version"
+)
+
+// defaultMaxClockSkew is the difference tolerated by default between the
+// local clock and the API clock.
+const defaultMaxClockSkew = 5 * time.Minute
+
+// SetMaxClockSkew sets the difference tolerated between the local clock and
+// the API clock, 5 minutes by default (or if 'skew' is not positive), before
+// SelfCheck reports it and auth failures get a ClockSkewHint.
+func (bs *BetaSeries) SetMaxClockSkew(skew time.Duration) {
+	bs.maxClockSkew = skew
+}
+
+// clockSkew returns the difference between the local clock and the Date of
+// the response headers, if it exceeds the tolerated skew.
+func (bs *BetaSeries) clockSkew(header http.Header) (time.Duration, bool) {
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		return 0, false
+	}
+	skew := timeNow().Sub(date)
+	if skew < 0 {
+		skew = -skew
+	}
+	max := bs.maxClockSkew
+	if max <= 0 {
+		max = defaultMaxClockSkew
+	}
+	return skew, skew > max
+}
+
+// ClockSkewHint wraps the authentication failures of requests answered with
+// a Date far from the local clock, which may be their cause. It can be
+// extracted with errors.As and unwraps to the error of the API.
+type ClockSkewHint struct {
+	// Skew is the difference between the local clock and the API one.
+	Skew time.Duration
+	Err  error
+}
+
+func (e *ClockSkewHint) Error() string {
+	return strings.TrimSpace(e.Err.Error()) + " (local clock is off by " +
+		e.Skew.Round(time.Second).String() + ", check the system clock)"
+}
+
+// Unwrap returns the error of the API.
+func (e *ClockSkewHint) Unwrap() error {
+	return e.Err
+}
+
+// withSkewHint wraps 'err', the error of a non 200 OK response, with a
+// ClockSkewHint if it is an authentication failure and the response is
+// dated too far from the local clock.
+func (bs *BetaSeries) withSkewHint(resp *http.Response, err error) error {
+	auth := resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden ||
+		errors.Is(err, ErrAuthRequired) || errors.Is(err, ErrTokenInvalid) || errors.Is(err, ErrInvalidAPIKey)
+	if !auth {
+		return err
+	}
+	if skew, ok := bs.clockSkew(resp.Header); ok {
+		return &ClockSkewHint{skew, err}
+	}
+	return err
+}
+
+// Problem is a failed check of SelfCheck. Fatal problems prevent the client
+// from working, the others are warnings.
+type Problem struct {
+	Check string
+	Fatal bool
+	Err   error
+}
+
+func (p Problem) String() string {
+	return p.Check + ": " + strings.TrimSpace(p.Err.Error())
+}
+
+// SelfCheck checks that the client can work with the API: the API key is
+// accepted, the member token (if any) is valid, the local clock agrees with
+// the API one and the API version is not deprecated. It returns the failed
+// checks, none if everything is fine.
+// It sends two requests at most.
+func (bs *BetaSeries) SelfCheck(ctx context.Context) []Problem {
+	var problems []Problem
+	resp, err := bs.selfCheckRequest(ctx)
+	if err != nil {
+		if errors.Is(err, ErrInvalidAPIKey) {
+			return append(problems, Problem{CheckAPIKey, true, err})
+		}
+		return append(problems, Problem{CheckAPI, true, err})
+	}
+
+	if skew, ok := bs.clockSkew(resp.Header); ok {
+		problems = append(problems, Problem{CheckClock, false,
+			fmt.Errorf("local clock is off by %s", skew.Round(time.Second))})
+	}
+	if deprecated(resp.Header) {
+		problems = append(problems, Problem{CheckVersion, false,
+			fmt.Errorf("api version %s is deprecated", bs.version)})
+	}
+
+	if _, err := bs.getToken(); err == nil {
+		_, err := bs.MembersInfos(ctx, 0, true, "")
+		if err != nil {
+			problems = append(problems, Problem{CheckToken, errors.Is(err, ErrTokenInvalid), err})
+		}
+	}
+	return problems
+}
+
+// selfCheckRequest sends a cheap request and returns its response, or the
+// API error.
+func (bs *BetaSeries) selfCheckRequest(ctx context.Context) (*http.Response, error) {
+	u := bs.endpoint("/news/last")
+	u.RawQuery = url.Values{"number": {"1"}}.Encode()
+	if err := bs.checkKey(u); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := bs.doRequest(req)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	defer resp.Body.Close()
+	body, err := readResponse(resp)
+	if err != nil {
+		return nil, contextError(ctx, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, bs.withSkewHint(resp, decodeErr(resp.StatusCode, body))
+	}
+	return resp, nil
+}
+
+// deprecated reports whether the response headers flag the requested API
+// version as deprecated, with a Deprecation header or a Warning mentioning it.
+func deprecated(header http.Header) bool {
+	if header.Get("Deprecation") != "" {
+		return true
+	}
+	for _, warning := range header["Warning"] {
+		if strings.Contains(strings.ToLower(warning), "deprecated") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package bsclient
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+const selfCheckDate = "Mon, 02 Jan 2017 15:04:05 GMT"
+
+func (s *MySuite) TestSelfCheck(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	headers := http.Header{}
+	f.handle("GET", "/news/last", func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range headers {
+			w.Header()[k] = v
+		}
+		writeFakeJSON(w, 200, `{"news":[],"errors":[]}`)
+	})
+	f.handleJSON("GET", "/members/infos", 200, `{"member":{"id":1},"errors":[]}`)
+	bs := f.client(c)
+	now, err := http.ParseTime(selfCheckDate)
+	c.Assert(err, IsNil)
+	timeNow = func() time.Time { return now.Add(time.Minute) }
+	defer func() { timeNow = time.Now }()
+
+	headers.Set("Date", selfCheckDate)
+	c.Assert(bs.SelfCheck(ctx), HasLen, 0)
+	c.Assert(f.callsTo("/news/last")[0].Header.Get("User-Agent"), Equals, "bsclient/"+Version)
+	c.Assert(f.callsTo("/members/infos"), HasLen, 1)
+
+	// clock skew and deprecation are warnings
+	timeNow = func() time.Time { return now.Add(-time.Hour) }
+	headers.Set("Warning", `299 - "API version 2.4 is deprecated"`)
+	problems := bs.SelfCheck(ctx)
+	c.Assert(problems, HasLen, 2)
+	c.Assert(problems[0].Check, Equals, CheckClock)
+	c.Assert(problems[0].Fatal, Equals, false)
+	c.Assert(problems[0].String(), Equals, "clock: local clock is off by 1h0m0s")
+	c.Assert(problems[1].Check, Equals, CheckVersion)
+	c.Assert(problems[1].Fatal, Equals, false)
+	headers.Del("Warning")
+	headers.Set("Deprecation", "true")
+	problems = bs.SelfCheck(ctx)
+	c.Assert(problems, HasLen, 2)
+	c.Assert(problems[1].Check, Equals, CheckVersion)
+	headers.Del("Deprecation")
+	headers.Del("Date")
+	timeNow = time.Now
+
+	// the token is only checked if present
+	f.handleJSON("GET", "/members/infos", 400, `{"errors":[{"code":2001,"text":"Token invalide."}]}`)
+	problems = bs.SelfCheck(ctx)
+	c.Assert(problems, HasLen, 1)
+	c.Assert(problems[0].Check, Equals, CheckToken)
+	c.Assert(problems[0].Fatal, Equals, true)
+	c.Assert(problems[0].String(), Equals, "token: Token invalide.")
+	bs.token = nil
+	c.Assert(bs.SelfCheck(ctx), HasLen, 0)
+
+	f.handleJSON("GET", "/news/last", 400, `{"errors":[{"code":1001,"text":"Clé API invalide."}]}`)
+	problems = bs.SelfCheck(ctx)
+	c.Assert(problems, HasLen, 1)
+	c.Assert(problems[0].Check, Equals, CheckAPIKey)
+	c.Assert(problems[0].Fatal, Equals, true)
+
+	// auth failures hint at the clock skew
+	f.handle("GET", "/news/last", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", selfCheckDate)
+		writeFakeJSON(w, 400, `{"errors":[{"code":1001,"text":"Clé API invalide."}]}`)
+	})
+	timeNow = func() time.Time { return now.Add(time.Hour) }
+	problems = bs.SelfCheck(ctx)
+	c.Assert(problems, HasLen, 1)
+	c.Assert(problems[0].Check, Equals, CheckAPIKey)
+	c.Assert(problems[0].String(), Equals, "key: Clé API invalide. (local clock is off by 1h0m0s, check the system clock)")
+	timeNow = time.Now
+
+	f.handleJSON("GET", "/news/last", 503, ``)
+	problems = bs.SelfCheck(ctx)
+	c.Assert(problems, HasLen, 1)
+	c.Assert(problems[0].Check, Equals, CheckAPI)
+	c.Assert(problems[0].Fatal, Equals, true)
+}
+
+func (s *MySuite) TestClockSkewHint(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handle("GET", "/members/infos", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", selfCheckDate)
+		writeFakeJSON(w, 400, `{"errors":[{"code":2001,"text":"Token invalide."}]}`)
+	})
+	f.handle("GET", "/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", selfCheckDate)
+		writeFakeJSON(w, 400, `{"errors":[{"code":4001,"text":"Série introuvable."}]}`)
+	})
+	f.handle("GET", "/news/last", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", selfCheckDate)
+		writeFakeJSON(w, 401, `Unauthorized`)
+	})
+	bs := f.client(c)
+	now, err := http.ParseTime(selfCheckDate)
+	c.Assert(err, IsNil)
+	defer func() { timeNow = time.Now }()
+
+	timeNow = func() time.Time { return now.Add(-10 * time.Minute) }
+	_, err = bs.MembersInfos(ctx, 0, false, "")
+	hint := &ClockSkewHint{}
+	c.Assert(errors.As(err, &hint), Equals, true)
+	c.Assert(hint.Skew, Equals, 10*time.Minute)
+	c.Assert(errors.Is(err, ErrTokenInvalid), Equals, true)
+	c.Assert(err, ErrorMatches, `Token invalide. \(local clock is off by 10m0s, check the system clock\)`)
+	_, err = bs.NewsLast(ctx, 1, false)
+	c.Assert(errors.As(err, &hint), Equals, true)
+
+	// only auth failures get the hint
+	_, err = bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(errors.As(err, &hint), Equals, false)
+	c.Assert(errors.Is(err, ErrNotFound), Equals, true)
+
+	// well set clocks
+	timeNow = func() time.Time { return now.Add(4 * time.Minute) }
+	_, err = bs.MembersInfos(ctx, 0, false, "")
+	c.Assert(errors.As(err, &hint), Equals, false)
+	c.Assert(err, ErrorMatches, "Token invalide.\n")
+
+	bs.SetMaxClockSkew(time.Minute)
+	_, err = bs.MembersInfos(ctx, 0, false, "")
+	c.Assert(errors.As(err, &hint), Equals, true)
+	c.Assert(hint.Skew, Equals, 4*time.Minute)
+}
@@ -1,19 +1,45 @@
 package bsclient
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"math/rand"
 	"net/url"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 var (
-	errNoShowsFound      = errors.New("no shows found")
-	errNoCharactersFound = errors.New("no characters found")
-	errNoVideosFound     = errors.New("no videos found")
-	errNoSingleIDUsed    = errors.New("no single id used")
-	errIDNotProperlySet  = errors.New("id not properly set")
-	errInvalidNote       = errors.New("invalid note")
+	// ErrNoShowsFound is returned by the show listings (ShowsSearch,
+	// ShowsList, ShowsRandom, ...) when no show matches: an empty result is
+	// reported as an error so that it is not mistaken for a failure to
+	// decode. It is an ErrNotFound, like the other "no ... found" errors.
+	ErrNoShowsFound = newError(ErrNotFound, "no shows found")
+	// ErrNoCharactersFound is returned by ShowsCharacters when the show has
+	// no characters. It is an ErrNotFound.
+	ErrNoCharactersFound = newError(ErrNotFound, "no characters found")
+	// ErrNoVideosFound is returned by ShowsVideos when the show has no
+	// videos. It is an ErrNotFound.
+	ErrNoVideosFound = newError(ErrNotFound, "no videos found")
+	// ErrIDNotProperlySet is returned when a call is given no valid id.
+	// It is an ErrInvalidInput.
+	ErrIDNotProperlySet = newError(ErrInvalidInput, "id not properly set")
+
+	// ErrInvalidNote is returned by the note setting methods for notes the
+	// API would reject: notes are whole numbers from 1 to 5, the API does
+	// not accept half points. It is an ErrInvalidInput.
+	ErrInvalidNote = newError(ErrInvalidInput, "invalid note: must be a whole number from 1 to 5")
+
+	// ErrEpisodeShowMismatch is returned by ShowAdd, when write validation
+	// is enabled, if the last episode watched belongs to another show.
+	// It is an ErrInvalidInput.
+	ErrEpisodeShowMismatch = newError(ErrInvalidInput, "episode does not belong to the show")
 )
 
 type seasonDetails struct {
@@ -30,16 +56,16 @@ type Show struct {
 	Title     string `json:"title"`
 	// specific to shows/... API endpoints
 	Description    string          `json:"description"`
-	Seasons        string          `json:"seasons"`
+	Seasons        FlexInt         `json:"seasons"`
 	SeasonsDetails []seasonDetails `json:"seasons_details"`
-	Episodes       string          `json:"episodes"`
-	Followers      string          `json:"followers"`
-	Comments       string          `json:"comments"`
-	Similars       string          `json:"similars"`
-	Characters     string          `json:"characters"`
+	Episodes       FlexInt         `json:"episodes"`
+	Followers      FlexInt         `json:"followers"`
+	Comments       FlexInt         `json:"comments"`
+	Similars       FlexInt         `json:"similars"`
+	Characters     FlexInt         `json:"characters"`
 	Creation       string          `json:"creation"`
-	Genres         []string        `json:"genres"`
-	Length         string          `json:"length"`
+	Genres         Genres          `json:"genres"`
+	Length         FlexInt         `json:"length"`
 	Network        string          `json:"network"`
 	Rating         string          `json:"rating"`
 	Status         string          `json:"status"`
@@ -47,7 +73,9 @@ type Show struct {
 	Notes          struct {
 		Total int     `json:"total"`
 		Mean  float32 `json:"mean"`
-		User  int     `json:"user"`
+		// User is a float so that half points can be decoded, even though
+		// notes can only be set to whole numbers.
+		User float32 `json:"user"`
 	} `json:"notes"`
 	InAccount bool `json:"in_account"`
 	Images    struct {
@@ -56,7 +84,7 @@ type Show struct {
 		Box    string `json:"box"`
 		Poster string `json:"poster"`
 	} `json:"images"`
-	Aliases []string `json:"aliases"`
+	Aliases Aliases `json:"aliases"`
 	User    struct {
 		Archived  bool    `json:"archived"`
 		Favorited bool    `json:"favorited"`
@@ -64,23 +92,229 @@ type Show struct {
 		Status    float64 `json:"status"`
 		Last      string  `json:"last"`
 		Tags      string  `json:"tags"`
+		Next      struct {
+			ID    int    `json:"id"`
+			Code  string `json:"code"`
+			Date  Date   `json:"date"`
+			Title string `json:"title"`
+		} `json:"next"`
 	} `json:"user"`
 	ResourceURL string `json:"resource_url"`
 	// specific to episodes/... API endpoints
 	Remaining int       `json:"remaining"`
 	Unseen    []Episode `json:"unseen"`
+
+	raw json.RawMessage
+}
+
+// CommentsCount returns the number of comments on the show, or 0 if the
+// count is unknown.
+func (s *Show) CommentsCount() int {
+	return int(s.Comments)
+}
+
+// IsAnnouncedOnly reports whether the show is announced but has no episode
+// yet. Such shows have no seasons nor creation year either, and the
+// endpoints listing their episodes answer with an error matching ErrNotFound.
+// The show must have the "Continuing" status: summary shows, shows without
+// a status and ended shows are never reported as announced.
+func (s *Show) IsAnnouncedOnly() bool {
+	if s.Episodes > 0 || !strings.EqualFold(s.Status, "Continuing") {
+		return false
+	}
+	for _, season := range s.SeasonsDetails {
+		if season.Episodes > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Genre is a show genre, identified by its API key and its display label.
+type Genre struct {
+	Key   string
+	Label string
+}
+
+// String returns the genre label.
+func (g Genre) String() string {
+	return g.Label
+}
+
+// Genres holds the genres of a show. Depending on the endpoint, the API
+// returns them either as a list of labels or as an object mapping genre
+// keys to labels; both forms are accepted.
+// Note: Show.Genres used to be a []string, use Labels() to get the same view.
+type Genres []Genre
+
+// Keys returns the genre keys. For the list form, keys are the labels.
+func (g Genres) Keys() []string {
+	out := make([]string, 0, len(g))
+	for _, genre := range g {
+		out = append(out, genre.Key)
+	}
+	return out
+}
+
+// Labels returns the genre labels.
+func (g Genres) Labels() []string {
+	out := make([]string, 0, len(g))
+	for _, genre := range g {
+		out = append(out, genre.Label)
+	}
+	return out
+}
+
+// UnmarshalJSON decodes the list, object and null forms of genres.
+// The order of the object form is preserved.
+func (g *Genres) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	*g = nil
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if data[0] == '[' {
+		var labels []string
+		if err := json.Unmarshal(data, &labels); err != nil {
+			return err
+		}
+		*g = make(Genres, 0, len(labels))
+		for _, label := range labels {
+			*g = append(*g, Genre{Key: label, Label: label})
+		}
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	delim, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim != json.Delim('{') {
+		return &json.UnmarshalTypeError{Value: string(data), Type: reflect.TypeOf(g)}
+	}
+	*g = Genres{}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var label string
+		if err := dec.Decode(&label); err != nil {
+			return err
+		}
+		*g = append(*g, Genre{Key: key.(string), Label: label})
+	}
+	return nil
+}
+
+// Aliases holds the alternative titles of a show. Depending on the
+// endpoint, the API sends them as a list or as an object keyed by alias id.
+type Aliases []string
+
+// UnmarshalJSON decodes the list, object and null forms of aliases.
+// The order of the object form is preserved.
+func (a *Aliases) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	*a = nil
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if data[0] == '[' {
+		var aliases []string
+		if err := json.Unmarshal(data, &aliases); err != nil {
+			return err
+		}
+		*a = aliases
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	delim, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim != json.Delim('{') {
+		return &json.UnmarshalTypeError{Value: string(data), Type: reflect.TypeOf(a)}
+	}
+	*a = Aliases{}
+	for dec.More() {
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		var alias string
+		if err := dec.Decode(&alias); err != nil {
+			return err
+		}
+		*a = append(*a, alias)
+	}
+	return nil
+}
+
+// MarshalJSON encodes genres as a list of labels when keys and labels
+// match, and as an object otherwise, so that decoding the output gives
+// back the same genres.
+func (g Genres) MarshalJSON() ([]byte, error) {
+	if g == nil {
+		return []byte("null"), nil
+	}
+	object := false
+	for _, genre := range g {
+		if genre.Key != genre.Label {
+			object = true
+			break
+		}
+	}
+	if !object {
+		return json.Marshal(g.Labels())
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	for i, genre := range g {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(genre.Key)
+		if err != nil {
+			return nil, err
+		}
+		label, err := json.Marshal(genre.Label)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(label)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
 }
 
 type shows struct {
-	Shows  []Show        `json:"shows"`
-	Errors []interface{} `json:"errors"`
+	Shows  []Show     `json:"shows"`
+	Errors []APIError `json:"errors"`
 }
 
+// showItem is the response of the single show endpoints. The show comes
+// alone, or in a list of one show depending on the form of the ids.
 type showItem struct {
 	Show   *Show         `json:"show"`
+	Shows  []Show        `json:"shows"`
 	Errors []interface{} `json:"errors"`
 }
 
+// show returns the show of the response, whichever envelope holds it, or
+// ErrNoShowsFound if both are empty.
+func (item *showItem) show() (*Show, error) {
+	if item.Show != nil && item.Show.ID != 0 {
+		return item.Show, nil
+	}
+	for i := range item.Shows {
+		if item.Shows[i].ID != 0 {
+			return &item.Shows[i], nil
+		}
+	}
+	return nil, ErrNoShowsFound
+}
+
 // Similar represents a data structure returned by the shows/similars BetaSeries API
 type Similar struct {
 	// used in shows/similars
@@ -95,83 +329,229 @@ type Similar struct {
 }
 
 type similars struct {
-	Similars []Similar     `json:"similars"`
-	Errors   []interface{} `json:"errors"`
+	Similars []Similar  `json:"similars"`
+	Errors   []APIError `json:"errors"`
 }
 
-func (bs *BetaSeries) doGetShows(u *url.URL, usedAPI string) ([]Show, error) {
-	resp, err := bs.do("GET", u)
+func (bs *BetaSeries) doGetShows(ctx context.Context, u *url.URL, usedAPI string) ([]Show, error) {
+	body, err := bs.do(ctx, "GET", u)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
 	data := &shows{}
-	err = bs.decode(data, resp, usedAPI, u.RawQuery)
+	err = bs.decodeList(data, body)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(data.Shows) < 1 {
-		return nil, errNoShowsFound
+		return nil, ErrNoShowsFound
 	}
 
 	return data.Shows, nil
 }
 
-func (bs *BetaSeries) doGetSimilars(u *url.URL) ([]Similar, error) {
-	resp, err := bs.do("GET", u)
+func (bs *BetaSeries) doGetSimilars(ctx context.Context, u *url.URL) ([]Similar, error) {
+	body, err := bs.do(ctx, "GET", u)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
 	data := &similars{}
-	err = bs.decode(data, resp, "", u.RawQuery)
+	err = bs.decodeList(data, body)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(data.Similars) < 1 {
-		return nil, errNoShowsFound
+		return nil, ErrNoShowsFound
 	}
 
 	return data.Similars, nil
 }
 
 // ShowsSearch returns a slice of shows found with the given query
+// The query is lowercased unless SetKeepSearchCase is enabled.
 // The slice is of size 100 maximum and the results are ordered by popularity by default.
-func (bs *BetaSeries) ShowsSearch(query, order string, summary bool) ([]Show, error) {
-	usedAPI := "/shows/search"
-	u, err := url.Parse(bs.baseURL + usedAPI)
+// 'order' can be title, popularity or followers, other orders are rejected
+// with an ErrInvalidInput error.
+// An empty result is reported with ErrNoShowsFound.
+func (bs *BetaSeries) ShowsSearch(ctx context.Context, query, order string, summary bool) ([]Show, error) {
+	return bs.filterShows(bs.showsSearch(ctx, query, order, summary, 1, 100))
+}
+
+// showsSearch is ShowsSearch keeping the excluded shows, returning the page
+// 'page' (from 1) of 'nbpp' shows.
+func (bs *BetaSeries) showsSearch(ctx context.Context, query, order string, summary bool, page, nbpp int) ([]Show, error) {
+	order, err := showsSearchOrders.validate(order)
 	if err != nil {
-		return nil, errURLParsing
+		return nil, err
 	}
+	usedAPI := "/shows/search"
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
-	q.Set("title", strings.ToLower(query))
-	q.Set("nbpp", "100")
-	switch order {
-	case "title", "popularity", "followers":
-		q.Set("order", order)
-	default:
-		q.Set("order", "popularity")
+	if !bs.keepSearchCase {
+		query = strings.ToLower(query)
+	}
+	q.Set("title", query)
+	q.Set("nbpp", strconv.Itoa(nbpp))
+	if page > 1 {
+		q.Set("page", strconv.Itoa(page))
 	}
+	q.Set("order", order)
 	if summary {
 		q.Set("summary", "true")
 	}
 	u.RawQuery = q.Encode()
 
-	return bs.doGetShows(u, usedAPI)
+	return bs.doGetShows(ctx, u, usedAPI)
+}
+
+// SetKeepSearchCase makes ShowsSearch send the queries as given rather
+// than lowercased.
+func (bs *BetaSeries) SetKeepSearchCase(enabled bool) {
+	bs.keepSearchCase = enabled
+}
+
+// ShowByExactTitle returns the show whose title or one of its aliases is
+// 'title', ignoring case and accents. If 'year' is not 0, the show must
+// have been created that year, which tells remakes apart.
+// It returns ErrNoShowsFound if no show matches and an *AmbiguousShowError
+// listing the candidates, which is an ErrAmbiguousTitle, if several do.
+func (bs *BetaSeries) ShowByExactTitle(ctx context.Context, title string, year int) (*Show, error) {
+	key := foldString(strings.TrimSpace(title))
+	if key == "" {
+		return nil, ErrNoShowsFound
+	}
+	shows, err := bs.ShowsSearch(ctx, title, "", false)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []Show
+	for _, show := range shows {
+		if year != 0 && show.Creation != strconv.Itoa(year) {
+			continue
+		}
+		if hasTitle(&show, key) {
+			candidates = append(candidates, show)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, ErrNoShowsFound
+	case 1:
+		return &candidates[0], nil
+	}
+	return nil, &AmbiguousShowError{candidates}
+}
+
+// hasTitle reports whether the title or an alias of the show folds to 'key'.
+func hasTitle(show *Show, key string) bool {
+	if foldString(strings.TrimSpace(show.Title)) == key {
+		return true
+	}
+	for _, alias := range show.Aliases {
+		if foldString(strings.TrimSpace(alias)) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Limits of ShowsSearchWithAliases
+const (
+	// aliasSparseResults is the number of results under which aliases are searched
+	aliasSparseResults = 3
+	// aliasScanSize is the number of popular shows whose aliases are scanned
+	aliasScanSize = 500
+)
+
+// ShowsSearchWithAliases searches shows by title or alias, returning at most
+// 'limit' shows (no limit if 'limit' is 0 or negative).
+// The search API only matches primary titles, so when it returns fewer than
+// 3 shows the aliases of the most popular shows are scanned as well: at most
+// two requests are sent whatever the query. The API has no alias aware
+// search parameter.
+// Results are ranked by exact title match, exact alias match, title prefix
+// match, alias prefix match, then search order and popularity order. Titles
+// and aliases are compared ignoring case, accents and leading articles.
+func (bs *BetaSeries) ShowsSearchWithAliases(ctx context.Context, query string, limit int) ([]Show, error) {
+	shows, err := bs.ShowsSearch(ctx, query, "", false)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	found := len(shows)
+	if found < aliasSparseResults {
+		popular, err := bs.ShowsList(ctx, "", "", "popularity", 0, aliasScanSize)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		shows = append(shows, popular...)
+	}
+
+	key := titleKey(query)
+	type match struct {
+		show  Show
+		score int
+	}
+	matches := []match{}
+	seen := map[int]bool{}
+	for i, show := range shows {
+		if seen[show.ID] {
+			continue
+		}
+		score := aliasScore(&show, key)
+		// search results are kept even when they match neither the title
+		// nor the aliases, the API knowing better
+		if score == 0 && i >= found {
+			continue
+		}
+		seen[show.ID] = true
+		matches = append(matches, match{show, score})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	out := []Show{}
+	for _, m := range matches {
+		if limit > 0 && len(out) == limit {
+			break
+		}
+		out = append(out, m.show)
+	}
+	if len(out) == 0 {
+		return nil, ErrNoShowsFound
+	}
+	return out, nil
+}
+
+// aliasScore ranks how well the show matches the collation key of a query.
+func aliasScore(show *Show, key string) int {
+	title := titleKey(show.Title)
+	if title == key {
+		return 4
+	}
+	score := 0
+	if strings.HasPrefix(title, key) {
+		score = 2
+	}
+	for _, alias := range show.Aliases {
+		alias = titleKey(alias)
+		if alias == key {
+			return 3
+		}
+		if score == 0 && strings.HasPrefix(alias, key) {
+			score = 1
+		}
+	}
+	return score
 }
 
 // ShowsRandom returns a slice of random shows. The maximum size of the slice is given
 // by the 'num' parameter. If you want to get only summarized info, use the 'summary parameter.
-func (bs *BetaSeries) ShowsRandom(num int, summary bool) ([]Show, error) {
+// An empty result is reported with ErrNoShowsFound.
+func (bs *BetaSeries) ShowsRandom(ctx context.Context, num int, summary bool) ([]Show, error) {
 	usedAPI := "/shows/random"
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 	if num >= 0 {
 		q.Set("nb", strconv.Itoa(num))
@@ -181,57 +561,134 @@ func (bs *BetaSeries) ShowsRandom(num int, summary bool) ([]Show, error) {
 	}
 	u.RawQuery = q.Encode()
 
-	return bs.doGetShows(u, usedAPI)
+	return bs.filterShows(bs.doGetShows(ctx, u, usedAPI))
+}
+
+// catalog pages fetched by ShowsRandomWeighted
+const (
+	maxWeightedPages = 5
+	weightedPageSize = 100
+)
+
+// ShowsRandomWeighted returns up to 'n' distinct random shows having at least
+// 'minFollowers' followers, each show being picked with a probability
+// proportional to its number of followers.
+// Candidates are taken from the catalog ordered by followers, fetching at
+// most 5 pages of 100 shows. When the client is authenticated, shows
+// already in the member's account are excluded.
+func (bs *BetaSeries) ShowsRandomWeighted(ctx context.Context, n int, minFollowers int) ([]Show, error) {
+	var candidates []Show
+	var weights []float64
+	authenticated := bs.Token() != ""
+	for page := 0; page < maxWeightedPages; page++ {
+		shows, err := bs.showsList(ctx, "", "", "followers", page*weightedPageSize, weightedPageSize)
+		if errors.Is(err, ErrNoShowsFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		last := false
+		for _, show := range shows {
+			if int(show.Followers) < minFollowers {
+				// shows are ordered by followers
+				last = true
+				break
+			}
+			if authenticated && show.InAccount || bs.excluded(&show) {
+				continue
+			}
+			candidates = append(candidates, show)
+			weights = append(weights, float64(show.Followers)+1)
+		}
+		if last || len(shows) < weightedPageSize {
+			break
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, ErrNoShowsFound
+	}
+
+	rnd := bs.random
+	if rnd == nil {
+		rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+	var out []Show
+	for len(out) < n && len(candidates) > 0 {
+		r := rnd.Float64() * total
+		i := 0
+		for ; i < len(candidates)-1; i++ {
+			r -= weights[i]
+			if r < 0 {
+				break
+			}
+		}
+		out = append(out, candidates[i])
+		// remove the picked show so that it cannot be picked again
+		total -= weights[i]
+		candidates = append(candidates[:i], candidates[i+1:]...)
+		weights = append(weights[:i], weights[i+1:]...)
+	}
+	return out, nil
 }
 
 // ShowsFavorites returns a slice of favorite shows.
-// A user ID can be provided.
-func (bs *BetaSeries) ShowsFavorites(userID int) ([]Show, error) {
+// A user ID can be provided. Fetching the favorites of another member fails
+// with an error matching ErrMemberNotFound if the member does not exist and
+// ErrProfilePrivate if their profile is private.
+func (bs *BetaSeries) ShowsFavorites(ctx context.Context, userID int) ([]Show, error) {
 	usedAPI := "/shows/favorites"
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 	if userID > 0 {
 		q.Set("id", strconv.Itoa(userID))
 	}
 	u.RawQuery = q.Encode()
 
-	return bs.doGetShows(u, usedAPI)
+	shows, err := bs.doGetShows(ctx, u, usedAPI)
+	return shows, memberError(userID, err)
 }
 
 // ShowFavorite sets the show 'id' as favorite.
-func (bs *BetaSeries) ShowFavorite(id int) (*Show, error) {
-	return bs.showUpdate("POST", "favorite", id, 0, "", 0)
+func (bs *BetaSeries) ShowFavorite(ctx context.Context, id int) (*Show, error) {
+	return bs.showUpdate(ctx, "POST", "favorite", ShowRef{ID: id}, nil)
 }
 
 // ShowFavoriteRemove remove the show 'id' from the favorites.
-func (bs *BetaSeries) ShowFavoriteRemove(id int) (*Show, error) {
-	return bs.showUpdate("DELETE", "favorite", id, 0, "", 0)
+func (bs *BetaSeries) ShowFavoriteRemove(ctx context.Context, id int) (*Show, error) {
+	return bs.showUpdate(ctx, "DELETE", "favorite", ShowRef{ID: id}, nil)
 }
 
 // ShowsSimilars returns a slice of shows similar to a given show
-func (bs *BetaSeries) ShowsSimilars(id, theTvdbID int, details bool) ([]Similar, error) {
+// An empty result is reported with ErrNoShowsFound.
+func (bs *BetaSeries) ShowsSimilars(ctx context.Context, id, theTvdbID int, details bool) ([]Similar, error) {
 	usedAPI := "/shows/similars"
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
-	if id > 0 {
-		q.Set("id", strconv.Itoa(id))
-	} else if theTvdbID > 0 {
-		q.Set("thetvdb_id", strconv.Itoa(theTvdbID))
-	} else {
-		return nil, errIDNotProperlySet
+	err := bs.setRef(q, showRefParams, ShowRef{ID: id, TheTvdbID: theTvdbID})
+	if err != nil {
+		return nil, err
 	}
 	if details {
 		q.Set("details", "true")
 	}
 	u.RawQuery = q.Encode()
 
-	return bs.doGetSimilars(u)
+	similars, err := bs.doGetSimilars(ctx, u)
+	if err != nil || !bs.excludeAdult {
+		return similars, err
+	}
+	kept := make([]Similar, 0, len(similars))
+	for i := range similars {
+		if !bs.excluded(&similars[i].Show) {
+			kept = append(kept, similars[i])
+		}
+	}
+	return kept, nil
 }
 
 // Character represents the character data returned by the betaserie API.
@@ -250,36 +707,29 @@ type characters struct {
 }
 
 // ShowsCharacters returns a slice of characters found with the given ID.
-func (bs *BetaSeries) ShowsCharacters(id, theTvdbID int) ([]Character, error) {
+// An empty result is reported with ErrNoCharactersFound.
+func (bs *BetaSeries) ShowsCharacters(ctx context.Context, id, theTvdbID int) ([]Character, error) {
 	usedAPI := "/shows/characters"
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
-	if id > 0 {
-		q.Set("id", strconv.Itoa(id))
-	} else if theTvdbID > 0 {
-		q.Set("thetvdb_id", strconv.Itoa(theTvdbID))
-	} else {
-		return nil, errIDNotProperlySet
+	err := bs.setRef(q, showRefParams, ShowRef{ID: id, TheTvdbID: theTvdbID})
+	if err != nil {
+		return nil, err
 	}
 	u.RawQuery = q.Encode()
 
-	resp, err := bs.do("GET", u)
+	body, err := bs.do(ctx, "GET", u)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
 	data := &characters{}
-	err = bs.decode(data, resp, usedAPI, u.RawQuery)
+	err = bs.decode(data, body, usedAPI, u.RawQuery)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(data.Characters) < 1 {
-		return nil, errNoCharactersFound
+		return nil, ErrNoCharactersFound
 	}
 
 	return data.Characters, nil
@@ -288,22 +738,31 @@ func (bs *BetaSeries) ShowsCharacters(id, theTvdbID int) ([]Character, error) {
 // ShowsList returns a slice of shows from an interval. It can return every shows if wanted.
 // 'since' : only displays shows from a specified data (timestamp UNIX - optional)
 // 'starting' : only displays shows beginning with the specified string (optional)
-// 'order': sort order of the result (alphabetical, popularity or followers)
+// 'order': sort order of the result (alphabetical, popularity, the default, or followers)
 // 'start' : show id number to begin the listing with (default 0, optional)
 // 'limit' : maximum size of the returned slice (default to everything, optional)
-func (bs *BetaSeries) ShowsList(since, starting, order string, start, limit int) ([]Show, error) {
-	usedAPI := "/shows/list"
-	u, err := url.Parse(bs.baseURL + usedAPI)
+// Unknown orders and negative limits are rejected with an ErrInvalidInput error.
+// A limit above 1000 is clamped to it, with a warning returned by LastWarnings.
+// An empty result is reported with ErrNoShowsFound.
+func (bs *BetaSeries) ShowsList(ctx context.Context, since, starting, order string, start, limit int) ([]Show, error) {
+	return bs.filterShows(bs.showsList(ctx, since, starting, order, start, limit))
+}
+
+// showsList is ShowsList keeping the excluded shows, for the scans of the
+// catalog which page through it.
+func (bs *BetaSeries) showsList(ctx context.Context, since, starting, order string, start, limit int) ([]Show, error) {
+	order, err := showsListOrders.validate(order)
 	if err != nil {
-		return nil, errURLParsing
+		return nil, err
 	}
-	q := u.Query()
-	switch order {
-	case "alphabetical", "popularity", "followers":
-		q.Set("order", order)
-	default:
-		q.Set("order", "popularity")
+	limit, warning, err := showsListLimit.validate(limit)
+	if err != nil {
+		return nil, err
 	}
+	usedAPI := "/shows/list"
+	u := bs.endpoint(usedAPI)
+	q := u.Query()
+	q.Set("order", order)
 	if len(since) != 0 {
 		q.Set("since", since)
 	}
@@ -318,75 +777,268 @@ func (bs *BetaSeries) ShowsList(since, starting, order string, start, limit int)
 	}
 	u.RawQuery = q.Encode()
 
-	return bs.doGetShows(u, usedAPI)
+	// the warnings of the previous call are dropped even if the request
+	// fails before its listing is decoded
+	bs.setWarning(nil)
+	shows, err := bs.doGetShows(ctx, u, usedAPI)
+	bs.addWarning(warning)
+	return shows, err
 }
 
-func (bs *BetaSeries) showUpdate(method, endPoint string, id, theTvdbID int, imdbID string, option int) (*Show, error) {
+// showUpdate requests the shows/'endPoint' API for the show 'ref', with the
+// optional additional parameters 'params'.
+func (bs *BetaSeries) showUpdate(ctx context.Context, method, endPoint string, ref ShowRef, params url.Values) (*Show, error) {
 	usedAPI := "/shows/" + endPoint
-	u, err := url.Parse(bs.baseURL + usedAPI)
+	u := bs.endpoint(usedAPI)
+	q := newQueryBuilder()
+	defer q.release()
+	err := bs.setRef(q, showRefParams, ref)
 	if err != nil {
-		return nil, errURLParsing
-	}
-	q := u.Query()
-	if id > 0 {
-		q.Set("id", strconv.Itoa(id))
-	} else if theTvdbID > 0 {
-		q.Set("thetvdb_id", strconv.Itoa(theTvdbID))
-	} else if imdbID != "" {
-		q.Set("imdb_id", imdbID)
-	} else {
-		return nil, errIDNotProperlySet
+		return nil, err
 	}
-	if option > 0 {
-		switch endPoint {
-		case "note":
-			q.Set("note", strconv.Itoa(option))
-		case "show":
-			q.Set("episode_id", strconv.Itoa(option))
+	for key, values := range params {
+		q.Set(key, values[0])
+		for _, value := range values[1:] {
+			q.Add(key, value)
 		}
 	}
 	u.RawQuery = q.Encode()
 
-	resp, err := bs.do(method, u)
+	var before interface{}
+	if method != "GET" {
+		before = bs.before(usedAPI, func() (interface{}, error) {
+			return bs.showUpdate(ctx, "GET", "display", ref, nil)
+		})
+	}
+	body, err := bs.do(ctx, method, u)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	show := &showItem{}
-	err = bs.decode(show, resp, usedAPI, u.RawQuery)
+	item := &showItem{}
+	err = bs.decode(item, body, usedAPI, u.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+	show, err := item.show()
 	if err != nil {
 		return nil, err
 	}
+	if method != "GET" {
+		bs.record(method, usedAPI, u, show.ID, before, show)
+	}
 
-	return show.Show, nil
+	return show, nil
 }
 
 // ShowDisplay returns the show information represented by the given 'id' from the user's account.
-func (bs *BetaSeries) ShowDisplay(id, theTvdbID int, imdbID string) (*Show, error) {
-	return bs.showUpdate("GET", "display", id, theTvdbID, imdbID, 0)
+// A response without show is reported with ErrNoShowsFound.
+func (bs *BetaSeries) ShowDisplay(ctx context.Context, id, theTvdbID int, imdbID string) (*Show, error) {
+	return bs.showUpdate(ctx, "GET", "display", ShowRef{ID: id, TheTvdbID: theTvdbID, ImdbID: imdbID}, nil)
+}
+
+// ShowsDisplay returns the shows 'ids', requested by chunks of 50 through the
+// multiple display of shows/display, in the order of the API.
+// Missing shows do not fail the call: the shows found are returned along
+// with a BatchError keyed by the missing ids, whose errors match ErrNotFound.
+// Other errors, such as an invalid token, fail the call.
+func (bs *BetaSeries) ShowsDisplay(ctx context.Context, ids []int) ([]Show, error) {
+	usedAPI := "/shows/display"
+	u := bs.endpoint(usedAPI)
+	var shows []Show
+	failed := BatchError{}
+	for _, chunk := range chunkIDs(ids, displayChunkSize) {
+		u.RawQuery = url.Values{"id": {joinIDs(chunk)}}.Encode()
+		body, err := bs.do(ctx, "GET", u)
+		// a single show is returned alone rather than in a list
+		data := &showItem{}
+		var apiErr *errAPI
+		if err == nil {
+			apiErr, err = bs.decodePartial(data, body)
+		}
+		if errors.Is(err, ErrNotFound) {
+			missingIDs(failed, chunk, nil, nil, err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		// errors other than missing shows fail the whole call
+		if apiErr != nil && !errors.Is(apiErr, ErrNotFound) {
+			return nil, apiErr
+		}
+		if data.Show != nil {
+			data.Shows = append(data.Shows, *data.Show)
+		}
+		found := map[int]bool{}
+		for _, show := range data.Shows {
+			found[show.ID] = true
+		}
+		shows = append(shows, data.Shows...)
+		missingIDs(failed, chunk, found, apiErr, ErrNoShowsFound)
+	}
+	if len(failed) > 0 {
+		return shows, failed
+	}
+	return shows, nil
+}
+
+// ShowsDisplayBatch returns the shows 'ids' like ShowDisplay, sending at
+// most 'concurrency' requests at once, in the order of 'ids'. Unlike
+// ShowsDisplay, each show is requested alone, so that one failure does not
+// void the others: the shows which succeeded are returned along with a
+// BatchError keyed by the failed ids. If 'ctx' is done, the shows not
+// requested yet are skipped and the error of 'ctx' is returned.
+func (bs *BetaSeries) ShowsDisplayBatch(ctx context.Context, ids []int, concurrency int) ([]Show, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var mu sync.Mutex
+	found := make([]*Show, len(ids))
+	failed := BatchError{}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for i, id := range ids {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i, id int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			show, err := bs.showUpdate(ctx, "GET", "display", ShowRef{ID: id}, nil)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[id] = err
+				return
+			}
+			found[i] = show
+		}(i, id)
+	}
+	wg.Wait()
+
+	shows := make([]Show, 0, len(ids))
+	for _, show := range found {
+		if show != nil {
+			shows = append(shows, *show)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return shows, err
+	}
+	if len(failed) > 0 {
+		return shows, failed
+	}
+	return shows, nil
+}
+
+// ShowFingerprint holds cheap counters of a show, used to detect whether
+// the show changed since it was last fetched.
+type ShowFingerprint struct {
+	Episodes  FlexInt
+	Seasons   FlexInt
+	Followers FlexInt
+	Status    string
+}
+
+// FingerprintOf returns the fingerprint of the given show.
+func FingerprintOf(s Show) ShowFingerprint {
+	return ShowFingerprint{
+		Episodes:  s.Episodes,
+		Seasons:   s.Seasons,
+		Followers: s.Followers,
+		Status:    s.Status,
+	}
+}
+
+// ShowRefreshIfChanged fetches the summarized show 'ref' and compares it
+// with the 'known' fingerprint. The full show is only requested when the
+// fingerprint differs, in which case it is returned along with true.
+// If nothing changed, it returns a nil show and false.
+func (bs *BetaSeries) ShowRefreshIfChanged(ctx context.Context, ref ShowRef, known ShowFingerprint) (*Show, bool, error) {
+	summary, err := bs.showUpdate(ctx, "GET", "display", ref, url.Values{"summary": {"true"}})
+	if err != nil {
+		return nil, false, err
+	}
+	if summary == nil {
+		return nil, false, ErrNoShowsFound
+	}
+	if FingerprintOf(*summary) == known {
+		return nil, false, nil
+	}
+	show, err := bs.showUpdate(ctx, "GET", "display", ref, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	return show, true, nil
 }
 
 // ShowAdd adds the show represented by the given id to the user's account.
 // The last episode watched can be provided; if is it, all episodes until this
 // one should be marked as watched.
-func (bs *BetaSeries) ShowAdd(id, theTvdbID int, imdbID string, lastEpisodeID int) (*Show, error) {
-	return bs.showUpdate("POST", "show", id, theTvdbID, imdbID, lastEpisodeID)
+// When write validation is enabled (see SetWriteValidation), the episode is
+// first checked to belong to the show and ErrEpisodeShowMismatch is returned
+// otherwise.
+func (bs *BetaSeries) ShowAdd(ctx context.Context, id, theTvdbID int, imdbID string, lastEpisodeID int) (*Show, error) {
+	if bs.validateWrites && lastEpisodeID > 0 {
+		err := bs.checkEpisodeShow(ctx, lastEpisodeID, id, theTvdbID, imdbID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	params := url.Values{}
+	if lastEpisodeID > 0 {
+		params.Set("episode_id", strconv.Itoa(lastEpisodeID))
+	}
+	return bs.showUpdate(ctx, "POST", "show", ShowRef{ID: id, TheTvdbID: theTvdbID, ImdbID: imdbID}, params)
+}
+
+// checkEpisodeShow makes sure the episode 'episodeID' belongs to the show
+// identified by the given ids, using the same precedence as setRef.
+func (bs *BetaSeries) checkEpisodeShow(ctx context.Context, episodeID, id, theTvdbID int, imdbID string) error {
+	episode, err := bs.EpisodeDisplay(ctx, episodeID, 0, false)
+	if err != nil {
+		return err
+	}
+	match := false
+	if id > 0 {
+		match = episode.Show.ID == id
+	} else if theTvdbID > 0 {
+		match = episode.Show.ThetvdbID == theTvdbID
+	} else if imdbID != "" {
+		// episodes do not carry the imdb id of their show
+		show, err := bs.ShowDisplay(ctx, 0, 0, imdbID)
+		if err != nil {
+			return err
+		}
+		match = show != nil && show.ID == episode.Show.ID
+	} else {
+		return ErrIDNotProperlySet
+	}
+	if !match {
+		return ErrEpisodeShowMismatch
+	}
+	return nil
 }
 
 // ShowRemove removes the show represented by the given id from user's account.
-func (bs *BetaSeries) ShowRemove(id, theTvdbID int, imdbID string) (*Show, error) {
-	return bs.showUpdate("DELETE", "show", id, theTvdbID, imdbID, 0)
+func (bs *BetaSeries) ShowRemove(ctx context.Context, id, theTvdbID int, imdbID string) (*Show, error) {
+	return bs.showUpdate(ctx, "DELETE", "show", ShowRef{ID: id, TheTvdbID: theTvdbID, ImdbID: imdbID}, nil)
 }
 
 // ShowArchive archives the show represented by the given id from user's account
-func (bs *BetaSeries) ShowArchive(id, theTvdbID int) (*Show, error) {
-	return bs.showUpdate("POST", "archive", id, theTvdbID, "", 0)
+func (bs *BetaSeries) ShowArchive(ctx context.Context, id, theTvdbID int) (*Show, error) {
+	return bs.showUpdate(ctx, "POST", "archive", ShowRef{ID: id, TheTvdbID: theTvdbID}, nil)
 }
 
 // ShowNotArchive removes from archives the show represented by the given id from user's account
-func (bs *BetaSeries) ShowNotArchive(id, theTvdbID int) (*Show, error) {
-	return bs.showUpdate("DELETE", "archive", id, theTvdbID, "", 0)
+func (bs *BetaSeries) ShowNotArchive(ctx context.Context, id, theTvdbID int) (*Show, error) {
+	return bs.showUpdate(ctx, "DELETE", "archive", ShowRef{ID: id, TheTvdbID: theTvdbID}, nil)
 }
 
 // Video represents the video data returned by the betaserie API
@@ -403,45 +1055,35 @@ type Video struct {
 }
 
 type videos struct {
-	Videos []Video       `json:"videos"`
-	Errors []interface{} `json:"errors"`
+	Videos []Video    `json:"videos"`
+	Errors []APIError `json:"errors"`
 }
 
 // ShowsVideos returns a slice of videos added by the betaseries members
 // on a specific show using the show 'id' or 'tvdbID' (strictly positive)
-// Note: do not use both ids, it will return an error
-func (bs *BetaSeries) ShowsVideos(id, tvdbID int) ([]Video, error) {
+// An empty result is reported with ErrNoVideosFound.
+func (bs *BetaSeries) ShowsVideos(ctx context.Context, id, tvdbID int) ([]Video, error) {
 	usedAPI := "/shows/videos"
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
-	if id > 0 && tvdbID > 0 {
-		return nil, errNoSingleIDUsed
-	} else if id > 0 {
-		q.Set("id", strconv.Itoa(id))
-	} else if tvdbID > 0 {
-		q.Set("thetvdb_id ", strconv.Itoa(tvdbID))
-	} else {
-		return nil, errIDNotProperlySet
+	err := bs.setRef(q, showRefParams, ShowRef{ID: id, TheTvdbID: tvdbID})
+	if err != nil {
+		return nil, err
 	}
 	u.RawQuery = q.Encode()
 
-	resp, err := bs.do("GET", u)
+	body, err := bs.do(ctx, "GET", u)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
 	data := &videos{}
-	err = bs.decode(data, resp, usedAPI, u.RawQuery)
+	err = bs.decodeList(data, body)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(data.Videos) < 1 {
-		return nil, errNoVideosFound
+		return nil, ErrNoVideosFound
 	}
 
 	return data.Videos, nil
@@ -449,19 +1091,21 @@ func (bs *BetaSeries) ShowsVideos(id, tvdbID int) ([]Video, error) {
 
 // ShowsEpisodes returns a slice of episode for the show represented by the given id.
 // Optional 'season' and 'episode' parameters can be used for precision.
-func (bs *BetaSeries) ShowsEpisodes(id, theTvdbID, season, episode int, subtitles bool) ([]Episode, error) {
+// For a show without episodes (see Show.IsAnnouncedOnly), the error is
+// ErrNoEpisodesFound, while SeasonRemaining, ShowRatingsMatrix and
+// SubtitleCoverage return empty results.
+func (bs *BetaSeries) ShowsEpisodes(ctx context.Context, id, theTvdbID, season, episode int, subtitles bool) ([]Episode, error) {
+	return bs.showEpisodes(ctx, ShowRef{ID: id, TheTvdbID: theTvdbID}, season, episode, subtitles)
+}
+
+func (bs *BetaSeries) showEpisodes(ctx context.Context, ref ShowRef, season, episode int, subtitles bool) ([]Episode, error) {
 	usedAPI := "/shows/episodes"
-	u, err := url.Parse(bs.baseURL + usedAPI)
+	u := bs.endpoint(usedAPI)
+	q := newQueryBuilder()
+	defer q.release()
+	err := bs.setRef(q, showRefParams, ref)
 	if err != nil {
-		return nil, errURLParsing
-	}
-	q := u.Query()
-	if id > 0 {
-		q.Set("id", strconv.Itoa(id))
-	} else if theTvdbID > 0 {
-		q.Set("thetvdb_id", strconv.Itoa(theTvdbID))
-	} else {
-		return nil, errIDNotProperlySet
+		return nil, err
 	}
 	if season > 0 {
 		q.Set("season", strconv.Itoa(season))
@@ -473,19 +1117,23 @@ func (bs *BetaSeries) ShowsEpisodes(id, theTvdbID, season, episode int, subtitle
 		q.Set("subtitles", "true")
 	}
 	u.RawQuery = q.Encode()
-	return bs.doGetEpisodes(u, usedAPI)
+	return bs.doGetEpisodes(ctx, u, usedAPI)
 }
 
 // EpisodesList returns a slice of unseen episodes ordered by shows
-func (bs *BetaSeries) EpisodesList(showID, theTvdbID int, imdbID string,
+// A negative 'limit' is rejected with an ErrInvalidInput error.
+// An empty result is reported with ErrNoShowsFound.
+func (bs *BetaSeries) EpisodesList(ctx context.Context, showID, theTvdbID int, imdbID string,
 	userID, limit, released int, subtitles, specials bool) ([]Show, error) {
 
-	usedAPI := "/episodes/list"
-	u, err := url.Parse(bs.baseURL + usedAPI)
+	limit, _, err := episodesListLimit.validate(limit)
 	if err != nil {
-		return nil, errURLParsing
+		return nil, err
 	}
-	q := u.Query()
+	usedAPI := "/episodes/list"
+	u := bs.endpoint(usedAPI)
+	q := newQueryBuilder()
+	defer q.release()
 	if specials {
 		q.Set("specials", "true")
 	}
@@ -495,14 +1143,12 @@ func (bs *BetaSeries) EpisodesList(showID, theTvdbID int, imdbID string,
 	if released >= 0 {
 		q.Set("released", strconv.Itoa(released))
 	}
-	if showID > 0 {
-		q.Set("showId", strconv.Itoa(showID))
-	}
-	if theTvdbID > 0 {
-		q.Set("showTheTVDBId", strconv.Itoa(theTvdbID))
-	}
-	if imdbID != "" {
-		q.Set("showIMDBId", imdbID)
+	ref := ShowRef{ID: showID, TheTvdbID: theTvdbID, ImdbID: imdbID}
+	if !ref.IsZero() {
+		err := bs.setRef(q, episodesListRefParams, ref)
+		if err != nil {
+			return nil, err
+		}
 	}
 	if limit > 0 {
 		q.Set("limit", strconv.Itoa(limit))
@@ -512,18 +1158,26 @@ func (bs *BetaSeries) EpisodesList(showID, theTvdbID int, imdbID string,
 	}
 	u.RawQuery = q.Encode()
 
-	return bs.doGetShows(u, usedAPI)
+	return bs.doGetShows(ctx, u, usedAPI)
+}
+
+// validNote reports whether the API accepts 'note' as a rating.
+func validNote(note int) bool {
+	return note >= 1 && note <= 5
 }
 
 // ShowNote sets the note (rating) for the given show.
-func (bs *BetaSeries) ShowNote(bsID, theTvdbID, note int) (*Show, error) {
-	if note < 1 || note > 5 {
-		return nil, errInvalidNote
+// It returns ErrInvalidNote without calling the API if 'note' is not
+// within 1 to 5.
+func (bs *BetaSeries) ShowNote(ctx context.Context, bsID, theTvdbID, note int) (*Show, error) {
+	if !validNote(note) {
+		return nil, ErrInvalidNote
 	}
-	return bs.showUpdate("POST", "note", bsID, theTvdbID, "", note)
+	return bs.showUpdate(ctx, "POST", "note", ShowRef{ID: bsID, TheTvdbID: theTvdbID},
+		url.Values{"note": {strconv.Itoa(note)}})
 }
 
 // ShowNoteRemove deletes the current note for the given show.
-func (bs *BetaSeries) ShowNoteRemove(bsID, theTvdbID int) (*Show, error) {
-	return bs.showUpdate("DELETE", "note", bsID, theTvdbID, "", 0)
+func (bs *BetaSeries) ShowNoteRemove(ctx context.Context, bsID, theTvdbID int) (*Show, error) {
+	return bs.showUpdate(ctx, "DELETE", "note", ShowRef{ID: bsID, TheTvdbID: theTvdbID}, nil)
 }
@@ -1,7 +1,12 @@
 package bsclient
 
 import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net/http"
 	"os"
+	"strconv"
 	"strings"
 
 	. "gopkg.in/check.v1"
@@ -12,7 +17,7 @@ const (
 )
 
 var (
-	err4001 = errorsAPI{
+	err4001 = APIError{
 		Code: 4001,
 		Text: "Aucune série trouvée.",
 	}
@@ -22,33 +27,33 @@ func (s *MySuite) TestShowsSearch(c *C) {
 	key := os.Getenv("BS_API_KEY")
 	bs, err := NewBetaseriesClient(key, "", "")
 	c.Assert(err, IsNil)
-	shows, err := bs.ShowsSearch(tvShowTest)
+	shows, err := bs.ShowsSearch(ctx, tvShowTest, "", false)
 	c.Assert(err, IsNil)
 	c.Assert(len(shows), Equals, 1)
 	c.Assert(shows[0].ID, Equals, 481)
 	c.Assert(shows[0].Title, Equals, tvShowTest)
-	c.Assert(shows[0].Seasons, Equals, "5")
-	c.Assert(shows[0].Episodes, Equals, "68")
+	c.Assert(shows[0].Seasons, Equals, FlexInt(5))
+	c.Assert(shows[0].Episodes, Equals, FlexInt(68))
 
-	_, err = bs.ShowsSearch("TV Show doesn't exists")
+	_, err = bs.ShowsSearch(ctx, "TV Show doesn't exists", "", false)
 	c.Assert(err, NotNil)
-	c.Assert(err, Equals, errNoShowsFound)
+	c.Assert(err, Equals, ErrNoShowsFound)
 }
 
 func (s *MySuite) TestShowsRandom(c *C) {
 	key := os.Getenv("BS_API_KEY")
 	bs, err := NewBetaseriesClient(key, "", "")
 	c.Assert(err, IsNil)
-	shows, err := bs.ShowsRandom(1, false)
+	shows, err := bs.ShowsRandom(ctx, 1, false)
 	c.Assert(err, IsNil)
 	c.Assert(len(shows), Equals, 1)
 	c.Assert(len(shows[0].Language) > 0, Equals, true)
 
-	shows, err = bs.ShowsRandom(0, false)
+	shows, err = bs.ShowsRandom(ctx, 0, false)
 	c.Assert(err, NotNil)
-	c.Assert(err, Equals, errNoShowsFound)
+	c.Assert(err, Equals, ErrNoShowsFound)
 
-	shows, err = bs.ShowsRandom(1, true)
+	shows, err = bs.ShowsRandom(ctx, 1, true)
 	c.Assert(err, IsNil)
 	c.Assert(len(shows), Equals, 1)
 	c.Assert(len(shows[0].Language), Equals, 0)
@@ -58,17 +63,17 @@ func (s *MySuite) TestShowsCharacters(c *C) {
 	key := os.Getenv("BS_API_KEY")
 	bs, err := NewBetaseriesClient(key, "", "")
 	c.Assert(err, IsNil)
-	shows, err := bs.ShowsSearch(tvShowTest)
+	shows, err := bs.ShowsSearch(ctx, tvShowTest, "", false)
 	c.Assert(err, IsNil)
 	c.Assert(len(shows), Equals, 1)
-	characters, err := bs.ShowsCharacters(shows[0].ID, 0)
+	characters, err := bs.ShowsCharacters(ctx, shows[0].ID, 0)
 	c.Assert(err, IsNil)
 	c.Assert(len(characters), Equals, 19)
 
-	_, err = bs.ShowsCharacters(123456789, 0)
+	_, err = bs.ShowsCharacters(ctx, 123456789, 0)
 	c.Assert(err, NotNil)
 	c.Assert(err, DeepEquals, &errAPI{
-		[]errorsAPI{err4001},
+		[]APIError{err4001},
 	})
 }
 
@@ -76,31 +81,31 @@ func (s *MySuite) TestShowsList(c *C) {
 	key := os.Getenv("BS_API_KEY")
 	bs, err := NewBetaseriesClient(key, "", "")
 	c.Assert(err, IsNil)
-	shows, err := bs.ShowsList("", "", -1, 100)
+	shows, err := bs.ShowsList(ctx, "", "", "", -1, 100)
 	c.Assert(err, IsNil)
 	c.Assert(len(shows), Equals, 100)
 	c.Assert(shows[0].ID, Equals, 425)
 
-	shows, err = bs.ShowsList("", "", 1, 100)
+	shows, err = bs.ShowsList(ctx, "", "", "", 1, 100)
 	c.Assert(err, IsNil)
 	c.Assert(len(shows), Equals, 100)
 	c.Assert(shows[0].ID, Equals, 481)
 
 	// timestamp to 01-01-3000
-	shows, err = bs.ShowsList("32503680000", "", 1, 100)
+	shows, err = bs.ShowsList(ctx, "32503680000", "", "", 1, 100)
 	c.Assert(err, NotNil)
-	c.Assert(err, Equals, errNoShowsFound)
+	c.Assert(err, Equals, ErrNoShowsFound)
 
 	// timestamp to 01-01-2016
-	shows, err = bs.ShowsList("1451606400", "", 1, 100)
+	shows, err = bs.ShowsList(ctx, "1451606400", "", "", 1, 100)
 	c.Assert(err, IsNil)
 	c.Assert(len(shows), Equals, 100)
 
-	shows, err = bs.ShowsList("-wrong-", "", 1, 100)
+	shows, err = bs.ShowsList(ctx, "-wrong-", "", "", 1, 100)
 	c.Assert(err, IsNil)
 	c.Assert(len(shows), Equals, 100)
 
-	shows, err = bs.ShowsList("1451606400", "test", -1, 10)
+	shows, err = bs.ShowsList(ctx, "1451606400", "test", "", -1, 10)
 	c.Assert(err, IsNil)
 	c.Assert(len(shows), Equals, 1)
 	c.Assert(shows[0].ID, Equals, 13842)
@@ -109,35 +114,35 @@ func (s *MySuite) TestShowsList(c *C) {
 func (s *MySuite) TestShowsUpdate(c *C) {
 	key := os.Getenv("BS_API_KEY")
 	bs, err := NewBetaseriesClient(key, "Dev050", "developer")
-	show, err := bs.ShowAdd(0, 0)
+	show, err := bs.ShowAdd(ctx, 0, 0, "", 0)
 	c.Assert(err, NotNil)
-	c.Assert(err, Equals, errIDNotProperlySet)
+	c.Assert(err, Equals, ErrIDNotProperlySet)
 
 	bs, err = NewBetaseriesClient(key, "Dev050", "developer")
-	show, err = bs.ShowAdd(1234567890, 0)
+	show, err = bs.ShowAdd(ctx, 1234567890, 0, "", 0)
 	c.Assert(err, NotNil)
 	c.Assert(err, DeepEquals, &errAPI{
-		[]errorsAPI{err4001},
+		[]APIError{err4001},
 	})
 
 	bs, _, id := makeClientAndAddShow(c)
 
-	show, err = bs.ShowArchive(id, 0)
+	show, err = bs.ShowArchive(ctx, id, 0)
 	c.Assert(err, IsNil)
 	c.Assert(show.InAccount, Equals, true)
 	c.Assert(show.User.Archived, Equals, true)
 
-	show, err = bs.ShowNotArchive(id, 0)
+	show, err = bs.ShowNotArchive(ctx, id, 0)
 	c.Assert(err, IsNil)
 	c.Assert(show.InAccount, Equals, true)
 	c.Assert(show.User.Archived, Equals, false)
 
-	show, err = bs.ShowDisplay(id, 0)
+	show, err = bs.ShowDisplay(ctx, id, 0, "")
 	c.Assert(err, IsNil)
 	c.Assert(show.InAccount, Equals, true)
 	c.Assert(show.Status, Equals, "Ended")
 
-	show, err = bs.ShowRemove(id, 0)
+	show, err = bs.ShowRemove(ctx, id, 0, "")
 	c.Assert(err, IsNil)
 	c.Assert(show.InAccount, Equals, false)
 }
@@ -146,26 +151,33 @@ func (s *MySuite) TestShowsVideos(c *C) {
 	key := os.Getenv("BS_API_KEY")
 	bs, err := NewBetaseriesClient(key, "", "")
 	c.Assert(err, IsNil)
-	videos, err := bs.ShowsVideos(1, 0)
+	videos, err := bs.ShowsVideos(ctx, 1, 0)
 	c.Assert(err, IsNil)
 	c.Assert(len(videos), Equals, 6)
 	c.Assert(strings.Contains(videos[0].YoutubeURL, "http"), Equals, true)
 
-	videos, err = bs.ShowsVideos(0, 1)
+	videos, err = bs.ShowsVideos(ctx, 0, 1)
 	c.Assert(err, NotNil)
 	c.Assert(err, DeepEquals, &errAPI{
-		[]errorsAPI{err4001},
+		[]APIError{err4001},
 	})
 	c.Assert(len(videos), Equals, 0)
 
-	videos, err = bs.ShowsVideos(1, 1)
+	// the betaseries id wins over the thetvdb id
+	videos, err = bs.ShowsVideos(ctx, 1, 1)
+	c.Assert(err, IsNil)
+	c.Assert(len(videos), Equals, 6)
+
+	bs.SetStrictRefs(true)
+	videos, err = bs.ShowsVideos(ctx, 1, 1)
 	c.Assert(err, NotNil)
-	c.Assert(err, Equals, errNoSingleIDUsed)
+	c.Assert(err, Equals, ErrAmbiguousRef)
 	c.Assert(len(videos), Equals, 0)
+	bs.SetStrictRefs(false)
 
-	videos, err = bs.ShowsVideos(0, 0)
+	videos, err = bs.ShowsVideos(ctx, 0, 0)
 	c.Assert(err, NotNil)
-	c.Assert(err, Equals, errIDNotProperlySet)
+	c.Assert(err, Equals, ErrIDNotProperlySet)
 	c.Assert(len(videos), Equals, 0)
 }
 
@@ -173,23 +185,380 @@ func (s *MySuite) TestShowsEpisodes(c *C) {
 	key := os.Getenv("BS_API_KEY")
 	bs, err := NewBetaseriesClient(key, "", "")
 	c.Assert(err, IsNil)
-	shows, err := bs.ShowsSearch(tvShowTest)
+	shows, err := bs.ShowsSearch(ctx, tvShowTest, "", false)
 	c.Assert(err, IsNil)
 	c.Assert(shows, HasLen, 1)
 
-	episodes, err := bs.ShowsEpisodes(shows[0].ID, 0, 0, 0, false)
+	episodes, err := bs.ShowsEpisodes(ctx, shows[0].ID, 0, 0, 0, false)
 	c.Assert(err, IsNil)
 	c.Assert(episodes, HasLen, 68)
 
-	episodes, err = bs.ShowsEpisodes(shows[0].ID, 0, 1, 0, false)
+	episodes, err = bs.ShowsEpisodes(ctx, shows[0].ID, 0, 1, 0, false)
 	c.Assert(err, IsNil)
 	c.Assert(episodes, HasLen, 12)
 
-	episodes, err = bs.ShowsEpisodes(shows[0].ID, 0, 1, 1, false)
+	episodes, err = bs.ShowsEpisodes(ctx, shows[0].ID, 0, 1, 1, false)
 	c.Assert(err, IsNil)
 	c.Assert(episodes, HasLen, 1)
 
-	episodes, err = bs.ShowsEpisodes(shows[0].ID, 0, -1, -1, false)
+	episodes, err = bs.ShowsEpisodes(ctx, shows[0].ID, 0, -1, -1, false)
 	c.Assert(err, IsNil)
 	c.Assert(episodes, HasLen, 68)
 }
+
+func (s *MySuite) TestShowAddWriteValidation(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/episodes/display", 200,
+		`{"episode":{"id":10,"show":{"id":481,"thetvdb_id":81189}},"errors":[]}`)
+	f.handleJSON("GET", "/shows/display", 200,
+		`{"show":{"id":481,"imdb_id":"tt0903747"},"errors":[]}`)
+	f.handleJSON("POST", "/shows/show", 200,
+		`{"show":{"id":481,"in_account":true},"errors":[]}`)
+	bs := f.client(c)
+	bs.SetWriteValidation(true)
+
+	show, err := bs.ShowAdd(ctx, 481, 0, "", 10)
+	c.Assert(err, IsNil)
+	c.Assert(show.InAccount, Equals, true)
+	show, err = bs.ShowAdd(ctx, 0, 81189, "", 10)
+	c.Assert(err, IsNil)
+	show, err = bs.ShowAdd(ctx, 0, 0, "tt0903747", 10)
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/shows/show"), HasLen, 3)
+
+	_, err = bs.ShowAdd(ctx, 1, 0, "", 10)
+	c.Assert(err, Equals, ErrEpisodeShowMismatch)
+	c.Assert(f.callsTo("/shows/show"), HasLen, 3)
+
+	f.handleJSON("GET", "/episodes/display", 400,
+		`{"episode":null,"errors":[{"code":4002,"text":"Episode introuvable."}]}`)
+	_, err = bs.ShowAdd(ctx, 481, 0, "", 123456789)
+	c.Assert(err, DeepEquals, &errAPI{
+		[]APIError{{Code: 4002, Text: "Episode introuvable."}},
+	})
+	c.Assert(f.callsTo("/shows/show"), HasLen, 3)
+
+	// without validation, no extra request is made
+	bs.SetWriteValidation(false)
+	_, err = bs.ShowAdd(ctx, 1, 0, "", 10)
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/episodes/display"), HasLen, 5)
+	c.Assert(f.callsTo("/shows/show"), HasLen, 4)
+}
+
+func (s *MySuite) TestGenresDecoding(c *C) {
+	show := &Show{}
+	err := json.Unmarshal([]byte(`{"genres":["Drama","Crime"]}`), show)
+	c.Assert(err, IsNil)
+	c.Assert(show.Genres.Keys(), DeepEquals, []string{"Drama", "Crime"})
+	c.Assert(show.Genres.Labels(), DeepEquals, []string{"Drama", "Crime"})
+
+	show = &Show{}
+	err = json.Unmarshal([]byte(`{"genres":{"Action":"Action","Science-Fiction":"Science-fiction"}}`), show)
+	c.Assert(err, IsNil)
+	c.Assert(show.Genres, HasLen, 2)
+	c.Assert(show.Genres.Keys(), DeepEquals, []string{"Action", "Science-Fiction"})
+	c.Assert(show.Genres.Labels(), DeepEquals, []string{"Action", "Science-fiction"})
+	c.Assert(show.Genres[1].String(), Equals, "Science-fiction")
+
+	show = &Show{}
+	err = json.Unmarshal([]byte(`{"genres":null}`), show)
+	c.Assert(err, IsNil)
+	c.Assert(show.Genres, IsNil)
+
+	err = json.Unmarshal([]byte(`{"genres":12}`), show)
+	c.Assert(err, NotNil)
+}
+
+func (s *MySuite) TestAliasesDecoding(c *C) {
+	for _, test := range []struct {
+		input   string
+		aliases Aliases
+	}{
+		{`["GoT","Le Trône de fer"]`, Aliases{"GoT", "Le Trône de fer"}},
+		{`{"12":"GoT","3":"Le Trône de fer"}`, Aliases{"GoT", "Le Trône de fer"}},
+		{`{}`, Aliases{}},
+		{`null`, nil},
+	} {
+		show := &Show{}
+		err := json.Unmarshal([]byte(`{"aliases":`+test.input+`}`), show)
+		c.Assert(err, IsNil, Commentf(test.input))
+		c.Assert(show.Aliases, DeepEquals, test.aliases, Commentf(test.input))
+	}
+	err := json.Unmarshal([]byte(`{"aliases":"GoT"}`), &Show{})
+	c.Assert(err, NotNil)
+}
+
+func (s *MySuite) TestGenresRoundTrip(c *C) {
+	for _, input := range []string{
+		`["Drama","Crime"]`,
+		`{"Action":"Action","Science-Fiction":"Science-fiction"}`,
+		`[]`,
+		`null`,
+	} {
+		var genres Genres
+		c.Assert(json.Unmarshal([]byte(input), &genres), IsNil)
+		data, err := json.Marshal(genres)
+		c.Assert(err, IsNil)
+		c.Assert(string(data), Equals, input)
+		var decoded Genres
+		c.Assert(json.Unmarshal(data, &decoded), IsNil)
+		c.Assert(decoded, DeepEquals, genres)
+	}
+}
+
+func (s *MySuite) TestShowRefreshIfChanged(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handle("GET", "/shows/display", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("summary") == "true" {
+			writeFakeJSON(w, 200, `{"show":{"id":481,"seasons":"5","episodes":"62",`+
+				`"followers":"1000","status":"Ended"},"errors":[]}`)
+			return
+		}
+		writeFakeJSON(w, 200, `{"show":{"id":481,"seasons":"5","episodes":"62",`+
+			`"followers":"1000","status":"Ended","description":"full"},"errors":[]}`)
+	})
+	bs := f.client(c)
+	known := FingerprintOf(Show{Seasons: 5, Episodes: 62, Followers: 1000, Status: "Ended"})
+
+	show, refreshed, err := bs.ShowRefreshIfChanged(ctx, ShowRef{ID: 481}, known)
+	c.Assert(err, IsNil)
+	c.Assert(refreshed, Equals, false)
+	c.Assert(show, IsNil)
+	c.Assert(f.callsTo("/shows/display"), HasLen, 1)
+
+	known.Episodes = 60
+	show, refreshed, err = bs.ShowRefreshIfChanged(ctx, ShowRef{ID: 481}, known)
+	c.Assert(err, IsNil)
+	c.Assert(refreshed, Equals, true)
+	c.Assert(show.Description, Equals, "full")
+	c.Assert(FingerprintOf(*show), Not(Equals), known)
+	c.Assert(f.callsTo("/shows/display"), HasLen, 3)
+
+	_, _, err = bs.ShowRefreshIfChanged(ctx, ShowRef{}, known)
+	c.Assert(err, Equals, ErrIDNotProperlySet)
+}
+
+// handleCatalog serves a fake catalog of 'size' shows ordered by followers,
+// the show 'id' having 1000-3*id followers. Shows whose id is a multiple of
+// ten are in the member's account.
+func handleCatalog(f *fakeServer, size int) {
+	f.handle("GET", "/shows/list", func(w http.ResponseWriter, r *http.Request) {
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		data := &shows{Shows: []Show{}}
+		for id := start + 1; id <= start+limit && id <= size; id++ {
+			data.Shows = append(data.Shows, Show{
+				ID:        id,
+				Followers: FlexInt(1000 - 3*id),
+				InAccount: id%10 == 0,
+			})
+		}
+		json.NewEncoder(w).Encode(data)
+	})
+}
+
+func (s *MySuite) TestShowsRandomWeighted(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	handleCatalog(f, 250)
+	bs := f.client(c)
+	bs.random = rand.New(rand.NewSource(1))
+
+	shows, err := bs.ShowsRandomWeighted(ctx, 50, 400)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 50)
+	// the third page holds shows below the minimum: no fourth page
+	c.Assert(f.callsTo("/shows/list"), HasLen, 3)
+	c.Assert(f.callsTo("/shows/list")[0].Query.Get("order"), Equals, "followers")
+	seen := map[int]bool{}
+	for _, show := range shows {
+		c.Assert(seen[show.ID], Equals, false)
+		seen[show.ID] = true
+		c.Assert(show.InAccount, Equals, false)
+		c.Assert(show.Followers >= 400, Equals, true)
+	}
+
+	// the same seed gives the same shows
+	bs.random = rand.New(rand.NewSource(1))
+	again, err := bs.ShowsRandomWeighted(ctx, 50, 400)
+	c.Assert(err, IsNil)
+	c.Assert(again, DeepEquals, shows)
+
+	// asking for more shows than available returns every candidate
+	shows, err = bs.ShowsRandomWeighted(ctx, 1000, 400)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 180)
+
+	_, err = bs.ShowsRandomWeighted(ctx, 10, 2000)
+	c.Assert(err, Equals, ErrNoShowsFound)
+}
+
+func (s *MySuite) TestShowsRandomWeightedBudget(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	handleCatalog(f, 100000)
+	bs := f.client(c)
+	_, err := bs.ShowsRandomWeighted(ctx, 10, -1000000)
+	c.Assert(err, IsNil)
+	c.Assert(f.callsTo("/shows/list"), HasLen, maxWeightedPages)
+}
+
+func (s *MySuite) TestShowsSearchEncoding(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/search", 200, `{"shows":[{"id":1}],"errors":[]}`)
+	bs := f.client(c)
+	for _, test := range []struct {
+		title   string
+		encoded string
+	}{
+		{"Kaamelott & co", "kaamelott+%26+co"},
+		{"C++", "c%2B%2B"},
+		{"#Fail", "%23fail"},
+		{"Åsa = 1?", "%C3%A5sa+%3D+1%3F"},
+		{"Ça/Là", "%C3%A7a%2Fl%C3%A0"},
+	} {
+		_, err := bs.ShowsSearch(ctx, test.title, "", false)
+		c.Assert(err, IsNil)
+		calls := f.callsTo("/shows/search")
+		call := calls[len(calls)-1]
+		c.Assert(call.RawQuery, Equals, "nbpp=100&order=popularity&title="+test.encoded, Commentf(test.title))
+		c.Assert(call.Query.Get("title"), Equals, strings.ToLower(test.title))
+	}
+
+	bs.SetKeepSearchCase(true)
+	_, err := bs.ShowsSearch(ctx, "Ça/Là", "", false)
+	c.Assert(err, IsNil)
+	calls := f.callsTo("/shows/search")
+	c.Assert(calls[len(calls)-1].Query.Get("title"), Equals, "Ça/Là")
+}
+
+func (s *MySuite) TestShowsSearchWithAliases(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/search", 400, `{"errors":[{"code":4001,"text":"Aucune série trouvée."}]}`)
+	f.handleJSON("GET", "/shows/list", 200, `{"shows":[
+		{"id":1,"title":"Breaking Bad","aliases":["BB"]},
+		{"id":2,"title":"Superstore","aliases":["Gotham Store"]},
+		{"id":3,"title":"Game of Thrones","aliases":["GoT","Le Trône de fer"]},
+		{"id":4,"title":"Got Talent"},
+		{"id":5,"title":"Gotham"}
+	],"errors":[]}`)
+	bs := f.client(c)
+
+	shows, err := bs.ShowsSearchWithAliases(ctx, "got", 0)
+	c.Assert(err, IsNil)
+	ids := []int{}
+	for _, show := range shows {
+		ids = append(ids, show.ID)
+	}
+	// exact alias, then title prefixes in popularity order, then alias prefix
+	c.Assert(ids, DeepEquals, []int{3, 4, 5, 2})
+	list := f.callsTo("/shows/list")
+	c.Assert(list, HasLen, 1)
+	c.Assert(list[0].Query.Get("order"), Equals, "popularity")
+	c.Assert(list[0].Query.Get("limit"), Equals, "500")
+
+	shows, err = bs.ShowsSearchWithAliases(ctx, "trone de fer", 1)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 1)
+	c.Assert(shows[0].ID, Equals, 3)
+
+	_, err = bs.ShowsSearchWithAliases(ctx, "nothing like this", 0)
+	c.Assert(err, Equals, ErrNoShowsFound)
+}
+
+func (s *MySuite) TestShowByExactTitle(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/search", 200, `{"shows":[
+		{"id":1,"title":"Battlestar Galactica","creation":"2004"},
+		{"id":2,"title":"Battlestar Galactica","creation":"1978"},
+		{"id":3,"title":"Battlestar Galactica: Blood & Chrome","creation":"2012"},
+		{"id":4,"title":"Le Bureau des Légendes","creation":"2015","aliases":{"7":"The Bureau"}},
+		{"id":5,"title":"Game of Thrones","creation":"2011","aliases":["Le Trône de fer"]}
+	],"errors":[]}`)
+	bs := f.client(c)
+
+	// remakes
+	show, err := bs.ShowByExactTitle(ctx, "battlestar galactica", 1978)
+	c.Assert(err, IsNil)
+	c.Assert(show.ID, Equals, 2)
+	show, err = bs.ShowByExactTitle(ctx, "Battlestar Galactica", 2004)
+	c.Assert(err, IsNil)
+	c.Assert(show.ID, Equals, 1)
+	_, err = bs.ShowByExactTitle(ctx, "Battlestar Galactica", 0)
+	c.Assert(errors.Is(err, ErrAmbiguousTitle), Equals, true)
+	c.Assert(errors.Is(err, ErrInvalidInput), Equals, true)
+	c.Assert(errors.Is(err, ErrNotFound), Equals, false)
+	ambiguous, ok := err.(*AmbiguousShowError)
+	c.Assert(ok, Equals, true)
+	c.Assert(ambiguous.Candidates, HasLen, 2)
+	c.Assert(err, ErrorMatches, `several shows match: Battlestar Galactica \(1\), Battlestar Galactica \(2\)`)
+
+	// accents, case and aliases
+	show, err = bs.ShowByExactTitle(ctx, " le bureau des legendes ", 0)
+	c.Assert(err, IsNil)
+	c.Assert(show.ID, Equals, 4)
+	show, err = bs.ShowByExactTitle(ctx, "THE BUREAU", 2015)
+	c.Assert(err, IsNil)
+	c.Assert(show.ID, Equals, 4)
+	show, err = bs.ShowByExactTitle(ctx, "le trone de fer", 0)
+	c.Assert(err, IsNil)
+	c.Assert(show.ID, Equals, 5)
+
+	// no match
+	for _, test := range []struct {
+		title string
+		year  int
+	}{
+		{"Battlestar", 0},
+		{"Bureau des Légendes", 0},
+		{"Battlestar Galactica", 2003},
+		{"  ", 0},
+	} {
+		_, err = bs.ShowByExactTitle(ctx, test.title, test.year)
+		c.Assert(err, Equals, ErrNoShowsFound, Commentf(test.title))
+	}
+	c.Assert(f.callsTo("/shows/search"), HasLen, 9)
+}
+
+func (s *MySuite) TestShowsSearchWithAliasesMerge(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/search", 200, `{"shows":[
+		{"id":5,"title":"Gotham"},
+		{"id":6,"title":"Forgotten"}
+	],"errors":[]}`)
+	f.handleJSON("GET", "/shows/list", 200, `{"shows":[
+		{"id":3,"title":"Game of Thrones","aliases":["GoT"]},
+		{"id":5,"title":"Gotham"}
+	],"errors":[]}`)
+	bs := f.client(c)
+
+	shows, err := bs.ShowsSearchWithAliases(ctx, "GoT", 0)
+	c.Assert(err, IsNil)
+	ids := []int{}
+	for _, show := range shows {
+		ids = append(ids, show.ID)
+	}
+	// search results matching nothing are kept, after the matches
+	c.Assert(ids, DeepEquals, []int{3, 5, 6})
+
+	// enough results: no alias scan
+	f.handleJSON("GET", "/shows/search", 200, `{"shows":[
+		{"id":5,"title":"Gotham"},
+		{"id":6,"title":"Forgotten"},
+		{"id":7,"title":"Got Talent"}
+	],"errors":[]}`)
+	shows, err = bs.ShowsSearchWithAliases(ctx, "GoT", 2)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 2)
+	c.Assert(shows[0].ID, Equals, 5)
+	c.Assert(shows[1].ID, Equals, 7)
+	c.Assert(f.callsTo("/shows/list"), HasLen, 1)
+	c.Assert(f.callsTo("/shows/search"), HasLen, 2)
+}
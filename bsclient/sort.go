@@ -0,0 +1,167 @@
+package bsclient
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ShowSortKey selects the order used by SortShows.
+type ShowSortKey int
+
+// Orders available for SortShows
+const (
+	// ShowsByTitle sorts by title, ignoring case, accents and leading articles
+	ShowsByTitle ShowSortKey = iota
+	// ShowsByFollowers sorts by decreasing number of followers
+	ShowsByFollowers
+	// ShowsByNextAirDate sorts by air date of the member's next episode,
+	// shows without next episode coming last
+	ShowsByNextAirDate
+	// ShowsByRemaining sorts by decreasing number of episodes the member
+	// has left to watch
+	ShowsByRemaining
+)
+
+// EpisodeSortKey selects the order used by SortEpisodes.
+type EpisodeSortKey int
+
+// Orders available for SortEpisodes
+const (
+	// EpisodesByCode sorts by season then episode number
+	EpisodesByCode EpisodeSortKey = iota
+	// EpisodesByDate sorts by air date, episodes without date coming last
+	EpisodesByDate
+)
+
+// leading articles ignored when collating titles
+var titleArticles = []string{"the ", "a ", "an ", "le ", "la ", "les ", "l'", "l’"}
+
+// foldedLetters maps the lower case latin letters with diacritics to their
+// base letter.
+var foldedLetters = func() map[rune]rune {
+	folded := map[rune]rune{}
+	for base, letters := range map[rune]string{
+		'a': "àáâãäåāăą",
+		'c': "çćĉċč",
+		'd': "ď",
+		'e': "èéêëēĕėęě",
+		'g': "ĝğġģ",
+		'h': "ĥ",
+		'i': "ìíîïĩīĭį",
+		'j': "ĵ",
+		'k': "ķ",
+		'l': "ĺļľ",
+		'n': "ñńņň",
+		'o': "òóôõöōŏő",
+		'r': "ŕŗř",
+		's': "śŝşš",
+		't': "ţť",
+		'u': "ùúûüũūŭůűų",
+		'w': "ŵ",
+		'y': "ýÿŷ",
+		'z': "źżž",
+	} {
+		for _, r := range letters {
+			folded[r] = base
+		}
+	}
+	return folded
+}()
+
+// foldString lowers the case of s and removes its diacritics, so that
+// "À la Maison Blanche" and "a la maison blanche" compare equal. The latin
+// letters with diacritics are folded whether they are precomposed or
+// followed by combining marks.
+func foldString(s string) string {
+	b := strings.Builder{}
+	b.Grow(len(s))
+	for _, r := range s {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		r = unicode.ToLower(r)
+		if base, ok := foldedLetters[r]; ok {
+			r = base
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// titleKey returns the collation key of a title: trimmed, without its leading
+// article and folded. Articles are removed before folding so that "À la"
+// is not mistaken for the article "a".
+func titleKey(title string) string {
+	key := strings.ToLower(strings.TrimSpace(title))
+	for _, article := range titleArticles {
+		if strings.HasPrefix(key, article) && len(key) > len(article) {
+			key = strings.TrimSpace(key[len(article):])
+			break
+		}
+	}
+	return foldString(key)
+}
+
+// lessDate orders API dates, unset dates coming last. A date without time
+// of day comes first in its day.
+func lessDate(a, b Date) bool {
+	if a.IsZero() || b.IsZero() {
+		return !a.IsZero() && b.IsZero()
+	}
+	return a.Time().Before(b.Time())
+}
+
+// SortShows sorts the shows in place with a stable sort.
+func SortShows(shows []Show, by ShowSortKey) {
+	var less func(a, b *Show) bool
+	switch by {
+	case ShowsByFollowers:
+		less = func(a, b *Show) bool {
+			return a.Followers > b.Followers
+		}
+	case ShowsByNextAirDate:
+		less = func(a, b *Show) bool {
+			return lessDate(a.User.Next.Date, b.User.Next.Date)
+		}
+	case ShowsByRemaining:
+		less = func(a, b *Show) bool {
+			return a.User.Remaining > b.User.Remaining
+		}
+	default:
+		less = func(a, b *Show) bool {
+			return titleKey(a.Title) < titleKey(b.Title)
+		}
+	}
+	sort.SliceStable(shows, func(i, j int) bool {
+		return less(&shows[i], &shows[j])
+	})
+}
+
+// SortEpisodes sorts the episodes in place with a stable sort.
+func SortEpisodes(episodes []Episode, by EpisodeSortKey) {
+	var less func(a, b *Episode) bool
+	switch by {
+	case EpisodesByDate:
+		less = func(a, b *Episode) bool {
+			return lessDate(a.Date, b.Date)
+		}
+	default:
+		less = func(a, b *Episode) bool {
+			if a.Season != b.Season {
+				return a.Season < b.Season
+			}
+			return a.Episode < b.Episode
+		}
+	}
+	sort.SliceStable(episodes, func(i, j int) bool {
+		return less(&episodes[i], &episodes[j])
+	})
+}
+
+// atoi converts the counters the API returns as strings, 0 if unset.
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
@@ -0,0 +1,86 @@
+package bsclient
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func showTitles(shows []Show) []string {
+	out := []string{}
+	for _, show := range shows {
+		out = append(out, show.Title)
+	}
+	return out
+}
+
+func (s *MySuite) TestTitleKey(c *C) {
+	for _, test := range []struct {
+		title string
+		key   string
+	}{
+		{"Breaking Bad", "breaking bad"},
+		{"The Wire", "wire"},
+		{"À la Maison Blanche", "a la maison blanche"},
+		{"La Casa de Papel", "casa de papel"},
+		{"Le Bureau des Légendes", "bureau des legendes"},
+		{"L'Effondrement", "effondrement"},
+		{"Les Revenants", "revenants"},
+		{"The", "the"},
+		{"  Élite ", "elite"},
+	} {
+		c.Assert(titleKey(test.title), Equals, test.key, Commentf(test.title))
+	}
+}
+
+func (s *MySuite) TestSortShows(c *C) {
+	newShow := func(title string, followers FlexInt, next string) Show {
+		show := Show{Title: title, Followers: followers}
+		show.User.Next.Date = apiDate(next)
+		return show
+	}
+	input := []Show{
+		newShow("The Wire", 300, ""),
+		newShow("Élite", 100, "2016-10-05"),
+		newShow("À la Maison Blanche", 200, "2016-10-01"),
+		newShow("Breaking Bad", 300, "0000-00-00"),
+		newShow("Dark", 50, "2016-09-30"),
+	}
+	for _, test := range []struct {
+		by       ShowSortKey
+		expected []string
+	}{
+		{ShowsByTitle, []string{"À la Maison Blanche", "Breaking Bad", "Dark", "Élite", "The Wire"}},
+		{ShowsByFollowers, []string{"The Wire", "Breaking Bad", "À la Maison Blanche", "Élite", "Dark"}},
+		{ShowsByNextAirDate, []string{"Dark", "À la Maison Blanche", "Élite", "The Wire", "Breaking Bad"}},
+	} {
+		shows := append([]Show(nil), input...)
+		SortShows(shows, test.by)
+		c.Assert(showTitles(shows), DeepEquals, test.expected)
+	}
+	SortShows(nil, ShowsByTitle)
+}
+
+func (s *MySuite) TestSortEpisodes(c *C) {
+	input := []Episode{
+		{ID: 1, Season: 2, Episode: 1, Date: apiDate("2016-02-01")},
+		{ID: 2, Season: 1, Episode: 10, Date: apiDate("")},
+		{ID: 3, Season: 1, Episode: 2, Date: apiDate("2016-01-08")},
+		{ID: 4, Season: 0, Episode: 1, Date: apiDate("0000-00-00")},
+		{ID: 5, Season: 1, Episode: 1, Date: apiDate("2016-01-01")},
+	}
+	for _, test := range []struct {
+		by       EpisodeSortKey
+		expected []int
+	}{
+		{EpisodesByCode, []int{4, 5, 3, 2, 1}},
+		// episodes without date keep their relative order, last
+		{EpisodesByDate, []int{5, 3, 1, 2, 4}},
+	} {
+		episodes := append([]Episode(nil), input...)
+		SortEpisodes(episodes, test.by)
+		ids := []int{}
+		for _, episode := range episodes {
+			ids = append(ids, episode.ID)
+		}
+		c.Assert(ids, DeepEquals, test.expected)
+	}
+}
@@ -0,0 +1,63 @@
+package bsclient
+
+import (
+	"strings"
+	"time"
+)
+
+// EndpointStats are the counters of the requests sent to an endpoint of
+// the API, see Stats.
+type EndpointStats struct {
+	// Calls is the number of requests sent
+	Calls int
+	// Errors is the number of requests which failed or were answered with
+	// an error status
+	Errors int
+	// Latency is the time spent in the requests, bodies included
+	Latency time.Duration
+	// LastStatus is the status of the last response, 0 if the last request
+	// got none
+	LastStatus int
+}
+
+// Stats returns the counters of the requests sent since the client was
+// created or the counters were reset, by endpoint path (for instance
+// "/shows/display"). A retried request is counted once per attempt.
+func (bs *BetaSeries) Stats() map[string]EndpointStats {
+	bs.statsMu.Lock()
+	defer bs.statsMu.Unlock()
+	stats := make(map[string]EndpointStats, len(bs.stats))
+	for endpoint, counters := range bs.stats {
+		stats[endpoint] = counters
+	}
+	return stats
+}
+
+// ResetStats resets the counters returned by Stats, and those of
+// CacheStats.
+func (bs *BetaSeries) ResetStats() {
+	if bs.cache != nil {
+		bs.cache.resetCounters()
+	}
+	bs.statsMu.Lock()
+	defer bs.statsMu.Unlock()
+	bs.stats = nil
+}
+
+// recordStats counts a request sent to 'path', answered with 'status'.
+func (bs *BetaSeries) recordStats(path string, status int, elapsed time.Duration, err error) {
+	endpoint := strings.TrimPrefix(path, bs.baseURL.Path)
+	bs.statsMu.Lock()
+	defer bs.statsMu.Unlock()
+	if bs.stats == nil {
+		bs.stats = map[string]EndpointStats{}
+	}
+	counters := bs.stats[endpoint]
+	counters.Calls++
+	if err != nil {
+		counters.Errors++
+	}
+	counters.Latency += elapsed
+	counters.LastStatus = status
+	bs.stats[endpoint] = counters
+}
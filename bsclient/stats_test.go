@@ -0,0 +1,72 @@
+package bsclient
+
+import (
+	"sync"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestStats(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/search", 200, `{"shows":[{"id":1,"title":"Lost"}],"errors":[]}`)
+	f.handleJSON("GET", "/shows/display", 400, `{"errors":[{"code":4001,"text":"Série introuvable."}]}`)
+	bs := f.client(c)
+	c.Assert(bs.Stats(), HasLen, 0)
+
+	for i := 0; i < 2; i++ {
+		_, err := bs.ShowsSearch(ctx, "lost", "", false)
+		c.Assert(err, IsNil)
+	}
+	_, err := bs.ShowDisplay(ctx, 1, 0, "")
+	c.Assert(err, NotNil)
+	// requests rejected before being sent are not counted
+	_, err = bs.ShowDisplay(ctx, 0, 0, "")
+	c.Assert(err, NotNil)
+
+	stats := bs.Stats()
+	c.Assert(stats, HasLen, 2)
+	search := stats["/shows/search"]
+	c.Assert(search.Calls, Equals, 2)
+	c.Assert(search.Errors, Equals, 0)
+	c.Assert(search.LastStatus, Equals, 200)
+	c.Assert(search.Latency > 0, Equals, true)
+	display := stats["/shows/display"]
+	c.Assert(display.Calls, Equals, 1)
+	c.Assert(display.Errors, Equals, 1)
+	c.Assert(display.LastStatus, Equals, 400)
+
+	// the returned map is a copy
+	stats["/shows/search"] = EndpointStats{}
+	c.Assert(bs.Stats()["/shows/search"].Calls, Equals, 2)
+
+	// transport errors have no status
+	f.Close()
+	_, err = bs.ShowsSearch(ctx, "lost", "", false)
+	c.Assert(err, NotNil)
+	search = bs.Stats()["/shows/search"]
+	c.Assert(search.Calls, Equals, 3)
+	c.Assert(search.Errors, Equals, 1)
+	c.Assert(search.LastStatus, Equals, 0)
+
+	bs.ResetStats()
+	c.Assert(bs.Stats(), HasLen, 0)
+}
+
+func (s *MySuite) TestStatsConcurrent(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/search", 200, `{"shows":[{"id":1,"title":"Lost"}],"errors":[]}`)
+	bs := f.client(c)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bs.ShowsSearch(ctx, "lost", "", false)
+			bs.Stats()
+		}()
+	}
+	wg.Wait()
+	c.Assert(bs.Stats()["/shows/search"].Calls, Equals, 8)
+}
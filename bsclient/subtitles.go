@@ -1,15 +1,38 @@
 package bsclient
 
 import (
+	"context"
 	"errors"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 )
 
 var (
-	errNoSubtitlesFound = errors.New("no subtitles found")
+	// ErrNoSubtitlesFound is returned by the subtitle listings when no
+	// subtitle matches. It is an ErrNotFound.
+	ErrNoSubtitlesFound = newError(ErrNotFound, "no subtitles found")
 )
 
+// Language is a subtitle language as named by the betaseries API
+type Language string
+
+// Subtitle languages
+const (
+	// LanguageAll matches subtitles in any language
+	LanguageAll Language = "all"
+	// LanguageVO matches subtitles in the original language
+	LanguageVO Language = "vo"
+	// LanguageVF matches french subtitles
+	LanguageVF Language = "vf"
+)
+
+// matches reports whether a subtitle language is the language l.
+func (l Language) matches(language string) bool {
+	return l == "" || l == LanguageAll || strings.EqualFold(string(l), language)
+}
+
 // FileName is a string representing a file name in the betaseries API
 type FileName string
 
@@ -36,21 +59,19 @@ type subtitles struct {
 	Errors    []interface{} `json:"errors"`
 }
 
-func (bs *BetaSeries) doGetSubtitles(u *url.URL, usedAPI string) ([]Subtitle, error) {
-	resp, err := bs.do("GET", u)
+func (bs *BetaSeries) doGetSubtitles(ctx context.Context, u *url.URL, usedAPI string) ([]Subtitle, error) {
+	body, err := bs.do(ctx, "GET", u)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
 	data := &subtitles{}
-	err = bs.decode(data, resp, usedAPI, u.RawQuery)
+	err = bs.decode(data, body, usedAPI, u.RawQuery)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(data.Subtitles) < 1 {
-		return nil, errNoSubtitlesFound
+		return nil, ErrNoSubtitlesFound
 	}
 
 	return data.Subtitles, nil
@@ -58,15 +79,13 @@ func (bs *BetaSeries) doGetSubtitles(u *url.URL, usedAPI string) ([]Subtitle, er
 
 // SubtitlesEpisode returns a slice of subtitles for a given episode
 // The language can be provided to filter results (all|vovf|vo|vf).
-func (bs *BetaSeries) SubtitlesEpisode(id int, language string) ([]Subtitle, error) {
+// An empty result is reported with ErrNoSubtitlesFound.
+func (bs *BetaSeries) SubtitlesEpisode(ctx context.Context, id int, language string) ([]Subtitle, error) {
 	usedAPI := "/subtitles/episode"
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 	if id <= 0 {
-		return nil, errIDNotProperlySet
+		return nil, ErrIDNotProperlySet
 	}
 	q.Set("id", strconv.Itoa(id))
 	if language != "" {
@@ -74,20 +93,18 @@ func (bs *BetaSeries) SubtitlesEpisode(id int, language string) ([]Subtitle, err
 	}
 	u.RawQuery = q.Encode()
 
-	return bs.doGetSubtitles(u, usedAPI)
+	return bs.doGetSubtitles(ctx, u, usedAPI)
 }
 
 // SubtitlesShow returns a slice of subtitles for a given show
 // The language can be provided to filter results (all|vovf|vo|vf).
-func (bs *BetaSeries) SubtitlesShow(id int, language string) ([]Subtitle, error) {
+// An empty result is reported with ErrNoSubtitlesFound.
+func (bs *BetaSeries) SubtitlesShow(ctx context.Context, id int, language string) ([]Subtitle, error) {
 	usedAPI := "/subtitles/show"
-	u, err := url.Parse(bs.baseURL + usedAPI)
-	if err != nil {
-		return nil, errURLParsing
-	}
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 	if id <= 0 {
-		return nil, errIDNotProperlySet
+		return nil, ErrIDNotProperlySet
 	}
 	q.Set("id", strconv.Itoa(id))
 	if language != "" {
@@ -95,18 +112,22 @@ func (bs *BetaSeries) SubtitlesShow(id int, language string) ([]Subtitle, error)
 	}
 	u.RawQuery = q.Encode()
 
-	return bs.doGetSubtitles(u, usedAPI)
+	return bs.doGetSubtitles(ctx, u, usedAPI)
 }
 
 // SubtitlesLast returns a slice of the last BetaSeries subtitles
-// The number can't be higher than 100 with current API.
+// The number can't be higher than 100 with current API: higher numbers are
+// clamped to it with a warning returned by LastWarnings, negative numbers
+// are rejected with an ErrInvalidInput error.
 // The language can be provided to filter results (all|vovf|vo|vf).
-func (bs *BetaSeries) SubtitlesLast(number int, language string) ([]Subtitle, error) {
-	usedAPI := "/subtitles/last"
-	u, err := url.Parse(bs.baseURL + usedAPI)
+// An empty result is reported with ErrNoSubtitlesFound.
+func (bs *BetaSeries) SubtitlesLast(ctx context.Context, number int, language string) ([]Subtitle, error) {
+	number, warning, err := subtitlesLastLimit.validate(number)
 	if err != nil {
-		return nil, errURLParsing
+		return nil, err
 	}
+	usedAPI := "/subtitles/last"
+	u := bs.endpoint(usedAPI)
 	q := u.Query()
 	if number > 0 {
 		q.Set("number", strconv.Itoa(number))
@@ -116,5 +137,87 @@ func (bs *BetaSeries) SubtitlesLast(number int, language string) ([]Subtitle, er
 	}
 	u.RawQuery = q.Encode()
 
-	return bs.doGetSubtitles(u, usedAPI)
+	subtitles, err := bs.doGetSubtitles(ctx, u, usedAPI)
+	bs.setWarning(warning)
+	return subtitles, err
+}
+
+// SeasonCoverage is the subtitle availability of a season, see Coverage.
+type SeasonCoverage struct {
+	Season int
+	// Episodes is the number of aired episodes of the season
+	Episodes int
+	// Covered is the number of those episodes having subtitles
+	Covered int
+}
+
+// Coverage is the subtitle availability of a show in a language.
+type Coverage struct {
+	Language Language
+	// Episodes is the number of aired episodes of the show, specials left out
+	Episodes int
+	// Covered is the number of those episodes having subtitles in the language
+	Covered int
+	// Percent is the share of covered episodes, from 0 to 100
+	Percent float64
+	// Seasons holds the coverage of the seasons, in order
+	Seasons []SeasonCoverage
+	// Gaps lists the seasons having episodes without subtitles
+	Gaps []int
+}
+
+// SubtitleCoverage returns the share of the aired episodes of the show 'ref'
+// having at least one subtitle in the language 'lang', per season and
+// overall. Specials are left out.
+// The episodes are fetched with their subtitles in a single request. A show
+// without episodes or subtitles has a zero coverage rather than an error.
+func (bs *BetaSeries) SubtitleCoverage(ctx context.Context, ref ShowRef, lang Language) (*Coverage, error) {
+	episodes, err := bs.showEpisodes(ctx, ref, 0, 0, true)
+	if err != nil && !errors.Is(err, ErrNoEpisodesFound) {
+		return nil, err
+	}
+	seasons := map[int]*SeasonCoverage{}
+	coverage := &Coverage{Language: lang}
+	for i := range episodes {
+		episode := &episodes[i]
+		if !counted(episode) || !isAired(episode) {
+			continue
+		}
+		season := seasons[episode.Season]
+		if season == nil {
+			season = &SeasonCoverage{Season: episode.Season}
+			seasons[episode.Season] = season
+		}
+		season.Episodes++
+		coverage.Episodes++
+		if hasSubtitle(episode, lang) {
+			season.Covered++
+			coverage.Covered++
+		}
+	}
+	for _, season := range seasons {
+		coverage.Seasons = append(coverage.Seasons, *season)
+	}
+	sort.SliceStable(coverage.Seasons, func(i, j int) bool {
+		return coverage.Seasons[i].Season < coverage.Seasons[j].Season
+	})
+	for _, season := range coverage.Seasons {
+		if season.Covered < season.Episodes {
+			coverage.Gaps = append(coverage.Gaps, season.Season)
+		}
+	}
+	if coverage.Episodes > 0 {
+		coverage.Percent = 100 * float64(coverage.Covered) / float64(coverage.Episodes)
+	}
+	return coverage, nil
+}
+
+// hasSubtitle reports whether the episode has a subtitle in the language.
+func hasSubtitle(e *Episode, lang Language) bool {
+	for _, subtitle := range e.Subtitles {
+		if lang.matches(subtitle.Language) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,64 @@
+package bsclient
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+// coverageEpisodes spans three seasons: the first one fully subtitled in
+// french, the second one partly, the third one in english only, plus a
+// special and an unaired episode without subtitles.
+const coverageEpisodes = `{"episodes":[
+	{"id":1,"season":1,"episode":1,"date":"2016-01-01","subtitles":[{"id":11,"language":"VF"},{"id":12,"language":"VO"}]},
+	{"id":2,"season":1,"episode":2,"date":"2016-01-08","subtitles":[{"id":13,"language":"VF"}]},
+	{"id":3,"season":2,"episode":1,"date":"2016-02-01","subtitles":[{"id":14,"language":"VF"},{"id":15,"language":"VF"}]},
+	{"id":4,"season":2,"episode":2,"date":"2016-02-08","subtitles":[{"id":16,"language":"VO"}]},
+	{"id":5,"season":2,"episode":3,"date":"2016-02-15","subtitles":[]},
+	{"id":6,"season":3,"episode":1,"date":"2016-03-01","subtitles":[{"id":17,"language":"VO"}]},
+	{"id":7,"season":3,"episode":2,"date":"2017-01-01","subtitles":[]},
+	{"id":8,"season":0,"episode":1,"special":1,"date":"2016-04-01","subtitles":[]}
+],"errors":[]}`
+
+func (s *MySuite) TestSubtitleCoverage(c *C) {
+	defer fixedNow("2016-06-01")()
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/episodes", 200, coverageEpisodes)
+	bs := f.client(c)
+
+	coverage, err := bs.SubtitleCoverage(ctx, ShowRef{ID: 1}, LanguageVF)
+	c.Assert(err, IsNil)
+	c.Assert(coverage, DeepEquals, &Coverage{
+		Language: LanguageVF,
+		Episodes: 6,
+		Covered:  3,
+		Percent:  50,
+		Seasons: []SeasonCoverage{
+			{Season: 1, Episodes: 2, Covered: 2},
+			{Season: 2, Episodes: 3, Covered: 1},
+			{Season: 3, Episodes: 1, Covered: 0},
+		},
+		Gaps: []int{2, 3},
+	})
+	c.Assert(f.callsTo("/shows/episodes"), HasLen, 1)
+	c.Assert(f.callsTo("/shows/episodes")[0].RawQuery, Equals, "id=1&subtitles=true")
+
+	coverage, err = bs.SubtitleCoverage(ctx, ShowRef{ID: 1}, LanguageAll)
+	c.Assert(err, IsNil)
+	c.Assert(coverage.Covered, Equals, 5)
+	c.Assert(coverage.Gaps, DeepEquals, []int{2})
+
+	// no episodes, or no subtitles at all
+	f.handleJSON("GET", "/shows/episodes", 200, `{"episodes":[],"errors":[]}`)
+	coverage, err = bs.SubtitleCoverage(ctx, ShowRef{ID: 1}, LanguageVF)
+	c.Assert(err, IsNil)
+	c.Assert(coverage, DeepEquals, &Coverage{Language: LanguageVF})
+	f.handleJSON("GET", "/shows/episodes", 200,
+		`{"episodes":[{"id":1,"season":1,"episode":1,"date":"2016-01-01","subtitles":[]}],"errors":[]}`)
+	coverage, err = bs.SubtitleCoverage(ctx, ShowRef{ID: 1}, LanguageVF)
+	c.Assert(err, IsNil)
+	c.Assert(coverage.Percent, Equals, float64(0))
+	c.Assert(coverage.Gaps, DeepEquals, []int{1})
+
+	_, err = bs.SubtitleCoverage(ctx, ShowRef{}, LanguageVF)
+	c.Assert(err, Equals, ErrIDNotProperlySet)
+}
@@ -0,0 +1,207 @@
+package bsclient
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// warmUpConcurrency is the number of requests sent at once by WarmUp when
+// the spec does not set it
+const warmUpConcurrency = 4
+
+// WarmUpTask is a prefetch run by WarmUp. Run is given a context which
+// limits the requests it sends with the client to the concurrency of the
+// warm-up, so that applications can add their own prefetches.
+type WarmUpTask struct {
+	Name string
+	Run  func(ctx context.Context, bs *BetaSeries) error
+	// posters is set by WarmUpPosters, whose posters are reported apart
+	posters *postersTask
+}
+
+type postersTask struct {
+	count, width, height int
+}
+
+// WarmUpSpec lists the prefetches of WarmUp.
+type WarmUpSpec struct {
+	Tasks []WarmUpTask
+	// Concurrency is the number of requests sent at once, 4 if not positive
+	Concurrency int
+}
+
+// WarmUpItem is the outcome of a prefetch of WarmUp.
+type WarmUpItem struct {
+	Name    string
+	Elapsed time.Duration
+	Err     error
+}
+
+// WarmUpReport is the result of WarmUp.
+type WarmUpReport struct {
+	// Items are the outcomes of the prefetches, in the order of the spec,
+	// each poster being an item
+	Items []WarmUpItem
+	// Requests is the number of requests sent
+	Requests int
+	// Elapsed is the duration of the warm-up
+	Elapsed time.Duration
+}
+
+// Failed returns the items which failed.
+func (r *WarmUpReport) Failed() []WarmUpItem {
+	var failed []WarmUpItem
+	for _, item := range r.Items {
+		if item.Err != nil {
+			failed = append(failed, item)
+		}
+	}
+	return failed
+}
+
+// WarmUpShows prefetches the shows of the authenticated member.
+func WarmUpShows() WarmUpTask {
+	return WarmUpTask{Name: "shows", Run: func(ctx context.Context, bs *BetaSeries) error {
+		_, err := warmUpFrom(ctx).memberShows(ctx, bs)
+		return err
+	}}
+}
+
+// WarmUpEpisodes prefetches the unseen episodes of the authenticated
+// member, as listed by EpisodesList.
+func WarmUpEpisodes() WarmUpTask {
+	return WarmUpTask{Name: "episodes", Run: func(ctx context.Context, bs *BetaSeries) error {
+		_, err := bs.EpisodesList(ctx, 0, 0, "", 0, 0, -1, false, false)
+		return err
+	}}
+}
+
+// WarmUpPlanning prefetches the planning of the authenticated member for
+// the month 'month' (YYYY-MM or "now"), as listed by PlanningMember.
+func WarmUpPlanning(month string) WarmUpTask {
+	return WarmUpTask{Name: "planning", Run: func(ctx context.Context, bs *BetaSeries) error {
+		_, err := bs.PlanningMember(ctx, 0, false, month)
+		return err
+	}}
+}
+
+// WarmUpPosters prefetches the pictures of the first 'count' shows of the
+// authenticated member, see PicturesShows. The shows are listed once
+// whether the spec holds WarmUpShows or not.
+func WarmUpPosters(count, width, height int) WarmUpTask {
+	return WarmUpTask{Name: "posters", posters: &postersTask{count, width, height}}
+}
+
+// warmUpKey is the key of the state of the warm-up in its context
+type warmUpKey struct{}
+
+// warmUpRun is the state shared by the prefetches of a warm-up.
+type warmUpRun struct {
+	slots chan struct{}
+
+	mu       sync.Mutex
+	requests int
+	// shows are the shows of the member, listed once
+	showsOnce sync.Once
+	shows     []Show
+	showsErr  error
+}
+
+// warmUpFrom returns the warm-up of 'ctx', nil if there is none.
+func warmUpFrom(ctx context.Context) *warmUpRun {
+	run, _ := ctx.Value(warmUpKey{}).(*warmUpRun)
+	return run
+}
+
+// acquire waits for a request slot of the warm-up, returning the function
+// releasing it.
+func (run *warmUpRun) acquire(ctx context.Context) (func(), error) {
+	select {
+	case run.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	run.mu.Lock()
+	run.requests++
+	run.mu.Unlock()
+	return func() { <-run.slots }, nil
+}
+
+// memberShows returns the shows of the authenticated member, listed on the
+// first call.
+func (run *warmUpRun) memberShows(ctx context.Context, bs *BetaSeries) ([]Show, error) {
+	run.showsOnce.Do(func() {
+		var member *Member
+		member, run.showsErr = bs.MembersInfos(ctx, 0, false, "shows")
+		if member != nil {
+			run.shows = member.Shows
+		}
+	})
+	return run.shows, run.showsErr
+}
+
+// WarmUp runs the prefetches of 'spec' concurrently, sending at most
+// spec.Concurrency requests at once, typically to fill the cache of the
+// client (see WithCache) when an application starts. A failed prefetch does
+// not stop the others: the failures are reported in the items of the
+// report, and the error is the one of 'ctx' if it is done before the end.
+func (bs *BetaSeries) WarmUp(ctx context.Context, spec WarmUpSpec) (*WarmUpReport, error) {
+	concurrency := spec.Concurrency
+	if concurrency <= 0 {
+		concurrency = warmUpConcurrency
+	}
+	run := &warmUpRun{slots: make(chan struct{}, concurrency)}
+	ctx = context.WithValue(ctx, warmUpKey{}, run)
+	start := time.Now()
+
+	items := make([][]WarmUpItem, len(spec.Tasks))
+	var wg sync.WaitGroup
+	for i, task := range spec.Tasks {
+		wg.Add(1)
+		go func(i int, task WarmUpTask) {
+			defer wg.Done()
+			if task.posters != nil {
+				items[i] = bs.warmUpPosters(ctx, run, task.posters)
+				return
+			}
+			taskStart := time.Now()
+			err := task.Run(ctx, bs)
+			items[i] = []WarmUpItem{{task.Name, time.Since(taskStart), err}}
+		}(i, task)
+	}
+	wg.Wait()
+
+	report := &WarmUpReport{Requests: run.requests, Elapsed: time.Since(start)}
+	for _, taskItems := range items {
+		report.Items = append(report.Items, taskItems...)
+	}
+	return report, ctx.Err()
+}
+
+// warmUpPosters prefetches the posters of the member's shows, one item per
+// poster.
+func (bs *BetaSeries) warmUpPosters(ctx context.Context, run *warmUpRun, task *postersTask) []WarmUpItem {
+	start := time.Now()
+	shows, err := run.memberShows(ctx, bs)
+	if err != nil {
+		return []WarmUpItem{{"posters", time.Since(start), err}}
+	}
+	if len(shows) > task.count {
+		shows = shows[:task.count]
+	}
+	items := make([]WarmUpItem, len(shows))
+	var wg sync.WaitGroup
+	for i := range shows {
+		wg.Add(1)
+		go func(i, id int) {
+			defer wg.Done()
+			posterStart := time.Now()
+			_, err := bs.PicturesShows(ctx, id, task.width, task.height)
+			items[i] = WarmUpItem{"poster " + strconv.Itoa(id), time.Since(posterStart), err}
+		}(i, shows[i].ID)
+	}
+	wg.Wait()
+	return items
+}
@@ -0,0 +1,147 @@
+package bsclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+// etagJSON answers with 'body' and its ETag, or 304 when the request holds
+// it, counting the requests in flight.
+func etagJSON(body string, inFlight *concurrencyGauge) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		inFlight.enter()
+		defer inFlight.leave()
+		etag := fmt.Sprintf(`"%d"`, len(body))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		writeFakeJSON(w, 200, body)
+	}
+}
+
+// concurrencyGauge keeps the highest number of requests in flight.
+type concurrencyGauge struct {
+	mu      sync.Mutex
+	current int
+	max     int
+}
+
+func (g *concurrencyGauge) enter() {
+	g.mu.Lock()
+	g.current++
+	if g.current > g.max {
+		g.max = g.current
+	}
+	g.mu.Unlock()
+	// let the other requests pile up
+	time.Sleep(2 * time.Millisecond)
+}
+
+func (g *concurrencyGauge) leave() {
+	g.mu.Lock()
+	g.current--
+	g.mu.Unlock()
+}
+
+func (s *MySuite) TestWarmUp(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	gauge := &concurrencyGauge{}
+	shows := []string{}
+	for id := 1; id <= 25; id++ {
+		shows = append(shows, fmt.Sprintf(`{"id":%d}`, id))
+	}
+	f.handle("GET", "/members/infos", etagJSON(`{"member":{"id":1,"shows":[`+strings.Join(shows, ",")+`]},"errors":[]}`, gauge))
+	f.handle("GET", "/episodes/list", etagJSON(`{"shows":[{"id":1,"unseen":[{"id":10}]}],"errors":[]}`, gauge))
+	f.handle("GET", "/planning/member", etagJSON(`{"episodes":[{"id":10}],"errors":[]}`, gauge))
+	f.handle("GET", "/pictures/shows", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("id") == "7" {
+			writeFakeJSON(w, 500, `{"errors":[]}`)
+			return
+		}
+		etagJSON("poster "+r.URL.Query().Get("id"), gauge)(w, r)
+	})
+	bs, err := NewClientFromToken("key", "token", WithBaseURL(f.URL), WithCache(100))
+	c.Assert(err, IsNil)
+
+	var custom int
+	report, err := bs.WarmUp(ctx, WarmUpSpec{
+		Tasks: []WarmUpTask{WarmUpShows(), WarmUpEpisodes(), WarmUpPlanning("now"), WarmUpPosters(20, 300, 200),
+			{Name: "custom", Run: func(ctx context.Context, bs *BetaSeries) error {
+				custom++
+				_, err := bs.PicturesShows(ctx, 1, 300, 200)
+				return err
+			}}},
+		Concurrency: 3,
+	})
+	c.Assert(err, IsNil)
+	// the member's shows are listed once for the shows and the posters
+	c.Assert(report.Requests, Equals, 24)
+	c.Assert(f.calls, HasLen, 24)
+	c.Assert(f.callsTo("/members/infos"), HasLen, 1)
+	c.Assert(gauge.max <= 3, Equals, true, Commentf("%d requests at once", gauge.max))
+	c.Assert(custom, Equals, 1)
+	c.Assert(report.Items, HasLen, 24)
+	c.Assert(report.Items[0].Name, Equals, "shows")
+	c.Assert(report.Items[3].Name, Equals, "poster 1")
+	c.Assert(report.Items[23].Name, Equals, "custom")
+	failed := report.Failed()
+	c.Assert(failed, HasLen, 1)
+	c.Assert(failed[0].Name, Equals, "poster 7")
+	c.Assert(errors.Is(failed[0].Err, ErrServiceUnavailable), Equals, true)
+
+	// the calls of the application are answered from the cache
+	_, err = bs.MembersInfos(ctx, 0, false, "shows")
+	c.Assert(err, IsNil)
+	_, err = bs.PlanningMember(ctx, 0, false, "now")
+	c.Assert(err, IsNil)
+	poster, err := bs.PicturesShows(ctx, 3, 300, 200)
+	c.Assert(err, IsNil)
+	c.Assert(poster, Equals, "poster 3")
+	for _, path := range []string{"/members/infos", "/planning/member", "/pictures/shows"} {
+		calls := f.callsTo(path)
+		c.Assert(calls[len(calls)-1].Header.Get("If-None-Match"), Not(Equals), "", Commentf(path))
+	}
+	c.Assert(bs.cache.len(), Equals, 22)
+
+	// the warm-up stops with its context
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	report, err = bs.WarmUp(cancelled, WarmUpSpec{Tasks: []WarmUpTask{WarmUpShows()}})
+	c.Assert(err, Equals, context.Canceled)
+	c.Assert(report.Items[0].Err, Equals, context.Canceled)
+}
+
+func (s *MySuite) TestWarmUpCanceled(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/display", 200, `{"show":{"id":1},"errors":[]}`)
+	f.setLatency(time.Minute)
+	bs := f.client(c)
+	display := WarmUpTask{Name: "display", Run: func(ctx context.Context, bs *BetaSeries) error {
+		_, err := bs.ShowDisplay(ctx, 1, 0, "")
+		return err
+	}}
+
+	// the second task waits for the request slot held by the first one
+	var report *WarmUpReport
+	cancelDuring(c, 50*time.Millisecond, func(ctx context.Context) error {
+		var err error
+		report, err = bs.WarmUp(ctx, WarmUpSpec{Tasks: []WarmUpTask{display, display}, Concurrency: 1})
+		return err
+	})
+	c.Assert(report.Items, HasLen, 2)
+	for _, item := range report.Items {
+		c.Assert(errors.Is(item.Err, context.Canceled), Equals, true)
+	}
+	c.Assert(f.callsTo("/shows/display"), HasLen, 1)
+}
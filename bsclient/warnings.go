@@ -0,0 +1,73 @@
+package bsclient
+
+// listEnvelope is implemented by the responses of the listings which may
+// hold API errors along with their results.
+type listEnvelope interface {
+	// len returns the number of results
+	len() int
+	// apiErrors returns the API errors of the response
+	apiErrors() []APIError
+}
+
+func (data *shows) len() int                 { return len(data.Shows) }
+func (data *shows) apiErrors() []APIError    { return data.Errors }
+func (data *similars) len() int              { return len(data.Similars) }
+func (data *similars) apiErrors() []APIError { return data.Errors }
+func (data *videos) len() int                { return len(data.Videos) }
+func (data *videos) apiErrors() []APIError   { return data.Errors }
+
+// LastWarnings returns the API errors sent along with the results of the
+// last show, similar show, video, member search or subtitle listing, such
+// as the errors of the shows the API could not list, or nil if there were
+// none. A listing whose response holds errors but no result fails with
+// those errors instead. The warnings of the client itself have the code 0,
+// such as a limit above the maximum of the endpoint, which is clamped to it
+// rather than sent.
+// When listings are requested concurrently, the warnings are those of the
+// last one answered.
+func (bs *BetaSeries) LastWarnings() []APIError {
+	bs.warningsMu.Lock()
+	defer bs.warningsMu.Unlock()
+	return append([]APIError(nil), bs.warnings...)
+}
+
+// decodeList decodes the response of a listing into 'data', keeping its API
+// errors as the warnings returned by LastWarnings if it holds results, and
+// returning them otherwise.
+func (bs *BetaSeries) decodeList(data listEnvelope, body []byte) error {
+	err := bs.unmarshal(data, body)
+	var warnings []APIError
+	if err == nil && len(data.apiErrors()) > 0 {
+		if data.len() == 0 {
+			err = &errAPI{data.apiErrors()}
+		} else {
+			warnings = data.apiErrors()
+		}
+	}
+	bs.warningsMu.Lock()
+	defer bs.warningsMu.Unlock()
+	bs.warnings = warnings
+	return err
+}
+
+// addWarning adds 'warning', if any, to the warnings returned by
+// LastWarnings, once the listing it is about has been decoded.
+func (bs *BetaSeries) addWarning(warning *APIError) {
+	if warning == nil {
+		return
+	}
+	bs.warningsMu.Lock()
+	defer bs.warningsMu.Unlock()
+	bs.warnings = append(bs.warnings, *warning)
+}
+
+// setWarning sets the warnings returned by LastWarnings to 'warning' alone,
+// nil if there is none, for the listings which hold no API errors.
+func (bs *BetaSeries) setWarning(warning *APIError) {
+	bs.warningsMu.Lock()
+	defer bs.warningsMu.Unlock()
+	bs.warnings = nil
+	if warning != nil {
+		bs.warnings = []APIError{*warning}
+	}
+}
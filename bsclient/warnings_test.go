@@ -0,0 +1,38 @@
+package bsclient
+
+import (
+	"errors"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *MySuite) TestLastWarnings(c *C) {
+	f := newFakeServer()
+	defer f.Close()
+	f.handleJSON("GET", "/shows/search", 200, `{"shows":[{"id":1},{"id":2}],`+
+		`"errors":[{"code":4001,"text":"Série 1234 introuvable."}]}`)
+	f.handleJSON("GET", "/shows/similars", 200, `{"similars":[{"id":3,"show_id":3}],"errors":[]}`)
+	f.handleJSON("GET", "/shows/videos", 200, `{"videos":[],"errors":[{"code":4001,"text":"Série introuvable."}]}`)
+	bs := f.client(c)
+	c.Assert(bs.LastWarnings(), IsNil)
+
+	shows, err := bs.ShowsSearch(ctx, "show", "", false)
+	c.Assert(err, IsNil)
+	c.Assert(shows, HasLen, 2)
+	warnings := bs.LastWarnings()
+	c.Assert(warnings, DeepEquals, []APIError{{Code: CodeNotFound, Text: "Série 1234 introuvable."}})
+	c.Assert(errors.Is(&warnings[0], ErrNotFound), Equals, true)
+
+	// the warnings are those of the last listing
+	_, err = bs.ShowsSimilars(ctx, 1, 0, false)
+	c.Assert(err, IsNil)
+	c.Assert(bs.LastWarnings(), IsNil)
+
+	// errors without results fail the listing
+	_, err = bs.ShowsVideos(ctx, 1, 0)
+	c.Assert(errors.Is(err, ErrNotFound), Equals, true)
+	var apiErr *APIError
+	c.Assert(errors.As(err, &apiErr), Equals, true)
+	c.Assert(apiErr.Code, Equals, CodeNotFound)
+	c.Assert(bs.LastWarnings(), IsNil)
+}
@@ -0,0 +1,190 @@
+// Command bsctl is a small command line client for the betaseries API,
+// built on the bsclient package.
+//
+// The API key and the member credentials are read from the BS_API_KEY,
+// BS_LOGIN and BS_PASSWORD environment variables.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"github.com/dns-gh/bs-client/bsclient"
+)
+
+const usage = `usage: bsctl <command> [arguments]
+
+commands:
+  search <query>          search shows by title
+  show <show-id>          display a show
+  unseen                  list the unseen episodes of the member
+  watch <episode-id>      mark an episode as watched
+  note <show-id> <1-5>    rate a show
+  export                  export the member's shows as JSON
+`
+
+var errUsage = errors.New("invalid usage")
+
+// client lists the bsclient methods used by the commands.
+type client interface {
+	ShowsSearch(ctx context.Context, query, order string, summary bool) ([]bsclient.Show, error)
+	ShowDisplay(ctx context.Context, id, theTvdbID int, imdbID string) (*bsclient.Show, error)
+	EpisodesList(ctx context.Context, showID, theTvdbID int, imdbID string,
+		userID, limit, released int, subtitles, specials bool) ([]bsclient.Show, error)
+	EpisodeWatched(ctx context.Context, bsID, theTvdbID, note int, bulk, delete bool) (*bsclient.Episode, error)
+	ShowNote(ctx context.Context, bsID, theTvdbID, note int) (*bsclient.Show, error)
+	MembersInfos(ctx context.Context, id int, summary bool, only string) (*bsclient.Member, error)
+}
+
+type command struct {
+	args int
+	run  func(ctx context.Context, bs client, args []string, w io.Writer) error
+}
+
+var commands = map[string]command{
+	"search": {-1, search},
+	"show":   {1, show},
+	"unseen": {0, unseen},
+	"watch":  {1, watch},
+	"note":   {2, note},
+	"export": {0, export},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	bs, err := bsclient.NewBetaseriesClient(os.Getenv("BS_API_KEY"),
+		os.Getenv("BS_LOGIN"), os.Getenv("BS_PASSWORD"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bsctl:", strings.TrimSpace(err.Error()))
+		os.Exit(1)
+	}
+	// interrupting the command cancels its request
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err = run(ctx, bs, os.Args[1:], os.Stdout)
+	if err == errUsage {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bsctl:", strings.TrimSpace(err.Error()))
+		os.Exit(1)
+	}
+}
+
+// run dispatches the command line arguments to the matching command.
+func run(ctx context.Context, bs client, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return errUsage
+	}
+	args = args[1:]
+	if (cmd.args >= 0 && len(args) != cmd.args) || (cmd.args < 0 && len(args) == 0) {
+		return errUsage
+	}
+	return cmd.run(ctx, bs, args, w)
+}
+
+func parseID(arg string) (int, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid id %q", arg)
+	}
+	return id, nil
+}
+
+func search(ctx context.Context, bs client, args []string, w io.Writer) error {
+	shows, err := bs.ShowsSearch(ctx, strings.Join(args, " "), "", true)
+	if err != nil {
+		return err
+	}
+	for _, show := range shows {
+		fmt.Fprintf(w, "%d\t%s\n", show.ID, show.Title)
+	}
+	return nil
+}
+
+func show(ctx context.Context, bs client, args []string, w io.Writer) error {
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	show, err := bs.ShowDisplay(ctx, id, 0, "")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s (%d)\n", show.Title, show.ID)
+	fmt.Fprintf(w, "status:   %s\n", show.Status)
+	fmt.Fprintf(w, "seasons:  %s\n", show.Seasons)
+	fmt.Fprintf(w, "episodes: %s\n", show.Episodes)
+	fmt.Fprintf(w, "network:  %s\n", show.Network)
+	fmt.Fprintf(w, "genres:   %s\n", strings.Join(show.Genres.Labels(), ", "))
+	return nil
+}
+
+func unseen(ctx context.Context, bs client, args []string, w io.Writer) error {
+	shows, err := bs.EpisodesList(ctx, 0, 0, "", 0, 0, -1, false, false)
+	if err != nil {
+		return err
+	}
+	for _, show := range shows {
+		for _, episode := range show.Unseen {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", episode.ID, show.Title, episode.Code, episode.Title)
+		}
+	}
+	return nil
+}
+
+func watch(ctx context.Context, bs client, args []string, w io.Writer) error {
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	// only mark the given episode, not the previous ones
+	episode, err := bs.EpisodeWatched(ctx, id, 0, 0, false, false)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s %s marked as watched\n", episode.Show.Title, episode.Code)
+	return nil
+}
+
+func note(ctx context.Context, bs client, args []string, w io.Writer) error {
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	value, err := strconv.Atoi(args[1])
+	if err != nil || value < 1 || value > 5 {
+		return fmt.Errorf("invalid note %q", args[1])
+	}
+	show, err := bs.ShowNote(ctx, id, 0, value)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s rated %d\n", show.Title, value)
+	return nil
+}
+
+func export(ctx context.Context, bs client, args []string, w io.Writer) error {
+	member, err := bs.MembersInfos(ctx, 0, false, "shows")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(member.Shows)
+}
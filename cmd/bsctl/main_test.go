@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/dns-gh/bs-client/bsclient"
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type MySuite struct{}
+
+var _ = Suite(&MySuite{})
+
+// stubClient records the calls made by the commands.
+type stubClient struct {
+	calls []string
+	err   error
+}
+
+func (s *stubClient) record(call string) error {
+	s.calls = append(s.calls, call)
+	return s.err
+}
+
+func (s *stubClient) ShowsSearch(ctx context.Context, query, order string, summary bool) ([]bsclient.Show, error) {
+	return []bsclient.Show{{ID: 481, Title: "Breaking Bad"}}, s.record("search " + query)
+}
+
+func (s *stubClient) ShowDisplay(ctx context.Context, id, theTvdbID int, imdbID string) (*bsclient.Show, error) {
+	return &bsclient.Show{ID: id, Title: "Breaking Bad", Status: "Ended"}, s.record("display")
+}
+
+func (s *stubClient) EpisodesList(ctx context.Context, showID, theTvdbID int, imdbID string,
+	userID, limit, released int, subtitles, specials bool) ([]bsclient.Show, error) {
+	show := bsclient.Show{Title: "Breaking Bad"}
+	show.Unseen = []bsclient.Episode{{ID: 10, Code: "S01E01", Title: "Pilot"}}
+	return []bsclient.Show{show}, s.record("list")
+}
+
+func (s *stubClient) EpisodeWatched(ctx context.Context, bsID, theTvdbID, note int, bulk, delete bool) (*bsclient.Episode, error) {
+	if bulk {
+		return nil, errors.New("unexpected bulk")
+	}
+	return &bsclient.Episode{ID: bsID, Code: "S01E01"}, s.record("watched")
+}
+
+func (s *stubClient) ShowNote(ctx context.Context, bsID, theTvdbID, note int) (*bsclient.Show, error) {
+	return &bsclient.Show{ID: bsID, Title: "Breaking Bad"}, s.record("note")
+}
+
+func (s *stubClient) MembersInfos(ctx context.Context, id int, summary bool, only string) (*bsclient.Member, error) {
+	return &bsclient.Member{Shows: []bsclient.Show{{ID: 481}}}, s.record("infos " + only)
+}
+
+func (s *MySuite) TestRunUsage(c *C) {
+	bs := &stubClient{}
+	for _, args := range [][]string{
+		nil,
+		{"unknown"},
+		{"search"},
+		{"show"},
+		{"show", "1", "2"},
+		{"unseen", "1"},
+		{"note", "1"},
+	} {
+		err := run(context.Background(), bs, args, &bytes.Buffer{})
+		c.Assert(err, Equals, errUsage, Commentf("%v", args))
+	}
+	c.Assert(bs.calls, HasLen, 0)
+}
+
+func (s *MySuite) TestRunCommands(c *C) {
+	bs := &stubClient{}
+	out := &bytes.Buffer{}
+	c.Assert(run(context.Background(), bs, []string{"search", "breaking", "bad"}, out), IsNil)
+	c.Assert(out.String(), Equals, "481\tBreaking Bad\n")
+
+	out.Reset()
+	c.Assert(run(context.Background(), bs, []string{"unseen"}, out), IsNil)
+	c.Assert(out.String(), Equals, "10\tBreaking Bad\tS01E01\tPilot\n")
+
+	c.Assert(run(context.Background(), bs, []string{"show", "481"}, &bytes.Buffer{}), IsNil)
+	c.Assert(run(context.Background(), bs, []string{"watch", "10"}, &bytes.Buffer{}), IsNil)
+	c.Assert(run(context.Background(), bs, []string{"note", "481", "4"}, &bytes.Buffer{}), IsNil)
+
+	out.Reset()
+	c.Assert(run(context.Background(), bs, []string{"export"}, out), IsNil)
+	var shows []bsclient.Show
+	c.Assert(json.Unmarshal(out.Bytes(), &shows), IsNil)
+	c.Assert(shows, HasLen, 1)
+
+	c.Assert(bs.calls, DeepEquals, []string{
+		"search breaking bad", "list", "display", "watched", "note", "infos shows",
+	})
+}
+
+func (s *MySuite) TestRunInvalidArguments(c *C) {
+	bs := &stubClient{}
+	c.Assert(run(context.Background(), bs, []string{"show", "abc"}, &bytes.Buffer{}), ErrorMatches, `invalid id "abc"`)
+	c.Assert(run(context.Background(), bs, []string{"watch", "-1"}, &bytes.Buffer{}), ErrorMatches, `invalid id "-1"`)
+	c.Assert(run(context.Background(), bs, []string{"note", "481", "6"}, &bytes.Buffer{}), ErrorMatches, `invalid note "6"`)
+	c.Assert(bs.calls, HasLen, 0)
+
+	bs.err = errors.New("api failure")
+	c.Assert(run(context.Background(), bs, []string{"unseen"}, &bytes.Buffer{}), ErrorMatches, "api failure")
+}